@@ -0,0 +1,43 @@
+package litemigrate
+
+import (
+	"os"
+	"os/user"
+)
+
+// AppliedBy identifies who or what applied a migration, so audits can answer who ran it. It is
+// recorded in the migration table together with the time the migration was applied.
+type AppliedBy struct {
+	Host       string `json:"host,omitempty"`
+	User       string `json:"user,omitempty"`
+	AppVersion string `json:"app_version,omitempty"`
+	// ApprovedBy is who approved the run after reviewing its plan, if it required an approval.
+	ApprovedBy string `json:"approved_by,omitempty"`
+}
+
+// LocalAppliedBy returns the hostname and OS user of the process with the given application
+// version.
+func LocalAppliedBy(appVersion string) AppliedBy {
+	appliedBy := AppliedBy{AppVersion: appVersion}
+	appliedBy.Host, _ = os.Hostname()
+	if u, err := user.Current(); err == nil {
+		appliedBy.User = u.Username
+	} else {
+		appliedBy.User = os.Getenv("USER")
+	}
+	return appliedBy
+}
+
+// SetAppliedBy sets the metadata recorded with every applied migration. By default it is
+// LocalAppliedBy without an application version. An empty AppliedBy records nothing.
+func (db *Database) SetAppliedBy(appliedBy AppliedBy) *Database {
+	db.appliedBy = &appliedBy
+	return db
+}
+
+func (db *Database) currentAppliedBy() AppliedBy {
+	if db.appliedBy != nil {
+		return *db.appliedBy
+	}
+	return LocalAppliedBy("")
+}
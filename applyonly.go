@@ -0,0 +1,31 @@
+package litemigrate
+
+import "context"
+
+// WithVersions makes MigrateUp and Plan ignore every migration except the ones with the given
+// versions, including post-deploy ones. Versions that aren't defined make the run fail.
+func WithVersions(versions ...int64) RunOption {
+	return func(c *runConfig) {
+		c.versions = versions
+	}
+}
+
+// ApplyOnly applies the pending migrations with the given versions and nothing else, e.g. to
+// cherry-pick a hotfix migration into an environment without the other pending ones. It fails
+// before making changes if a version isn't defined or a selected migration depends on one that is
+// neither applied nor selected. Applying a version below the current one warns that it is out of
+// order, see WarningOutOfOrder.
+func (db *Database) ApplyOnly(ctx context.Context, versions ...int64) (*Result, error) {
+	return db.MigrateUp(ctx, WithVersions(versions...))
+}
+
+// checkVersions returns an error if the run selects versions that aren't defined.
+func (c *runConfig) checkVersions(migrations *Migrations) error {
+	defined := migrations.byVersion()
+	for _, version := range c.versions {
+		if _, ok := defined[version]; !ok {
+			return errorf(CodeUnknownMigration, "migration (version=%v) isn't defined", version)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestApplyOnly(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	dependent := tableMigration(4, "four")
+	dependent.DependsOn = []int64{2}
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		tableMigration(3, "three"),
+		dependent,
+	})
+
+	result, err := db.ApplyOnly(ctx, 3)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 1 || result.Applied[0] != 3 {
+		t.Fatalf("expected only version 3 to be applied, got %v", result.Applied)
+	}
+
+	if _, err := db.ApplyOnly(ctx, 4); litemigrate.Code(err) != litemigrate.CodeInvalidMigration {
+		t.Errorf("expected error code %s for a missing dependency, got %v", litemigrate.CodeInvalidMigration, err)
+	}
+
+	if _, err := db.ApplyOnly(ctx, 5); litemigrate.Code(err) != litemigrate.CodeUnknownMigration {
+		t.Errorf("expected error code %s for an undefined version, got %v", litemigrate.CodeUnknownMigration, err)
+	}
+
+	result, err = db.ApplyOnly(ctx, 2, 4)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 2 || result.Applied[0] != 2 || result.Applied[1] != 4 {
+		t.Errorf("expected versions 2 and 4 to be applied, got %v", result.Applied)
+	}
+}
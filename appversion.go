@@ -0,0 +1,92 @@
+package litemigrate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WithAppVersion sets the version of the running application for a run. Pending migrations whose
+// MinAppVersion is newer are deferred instead of applied, so an older binary can't apply
+// migrations meant for a future release. It defaults to the AppVersion of Database.SetAppliedBy.
+// Without an application version, MinAppVersion is ignored.
+func WithAppVersion(version string) RunOption {
+	return func(c *runConfig) {
+		c.appVersion = version
+	}
+}
+
+// deferred reports whether the migration requires a newer application version than the run's.
+func (c *runConfig) deferred(migration Migration) (bool, error) {
+	if c.appVersion == "" || migration.MinAppVersion == "" {
+		return false, nil
+	}
+	cmp, err := compareVersions(c.appVersion, migration.MinAppVersion)
+	if err != nil {
+		return false, errorf(CodeInvalidMigration, "invalid application version %q: %w", c.appVersion, err)
+	}
+	return cmp < 0, nil
+}
+
+// compareVersions compares two semantic versions such as "1.4.0" or "v1.4.0-rc.1", returning -1,
+// 0 or 1. Missing components count as zero and build metadata is ignored. Pre-releases sort before
+// their release and are compared as strings among themselves.
+func compareVersions(a, b string) (int, error) {
+	aCore, aPre, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bCore, bPre, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(aCore) || i < len(bCore); i++ {
+		var x, y int
+		if i < len(aCore) {
+			x = aCore[i]
+		}
+		if i < len(bCore) {
+			y = bCore[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0, nil
+	case aPre == "":
+		return 1, nil
+	case bPre == "":
+		return -1, nil
+	}
+	return strings.Compare(aPre, bPre), nil
+}
+
+// parseVersion splits a version into its numeric components and its pre-release.
+func parseVersion(version string) ([]int, string, error) {
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexByte(core, '+'); i >= 0 {
+		core = core[:i]
+	}
+	pre := ""
+	if i := strings.IndexByte(core, '-'); i >= 0 {
+		core, pre = core[:i], core[i+1:]
+	}
+
+	parts := strings.Split(core, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, "", fmt.Errorf("version %q isn't a semantic version", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, pre, nil
+}
@@ -0,0 +1,101 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestAppVersion(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	future := tableMigration(2, "two")
+	future.MinAppVersion = "v1.10.0"
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		future,
+	}).SetAppliedBy(litemigrate.AppliedBy{AppVersion: "1.9.3"})
+
+	result, err := db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 1 || result.Applied[0] != 1 {
+		t.Errorf("expected version 1 to be applied, got %v", result.Applied)
+	}
+	if len(result.Deferred) != 1 || result.Deferred[0] != 2 {
+		t.Errorf("expected version 2 to be deferred, got %v", result.Deferred)
+	}
+
+	result, err = db.MigrateUp(ctx, litemigrate.WithAppVersion("1.10.0-rc.1"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Deferred) != 1 {
+		t.Errorf("expected a pre-release to defer version 2, got %v", result.Deferred)
+	}
+
+	result, err = db.MigrateUp(ctx, litemigrate.WithAppVersion("1.10"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Applied) != 1 || result.Applied[0] != 2 {
+		t.Errorf("expected version 2 to be applied, got %v", result.Applied)
+	}
+}
+
+func TestAppVersionInvalid(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	migration := tableMigration(1, "one")
+	migration.MinAppVersion = "next"
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{migration})
+
+	_, err = db.MigrateUp(context.Background())
+	var lmErr *litemigrate.Error
+	if !errors.As(err, &lmErr) || lmErr.Code != litemigrate.CodeInvalidMigration {
+		t.Fatalf("expected an invalid migration error, got %v", err)
+	}
+}
+
+func TestAppVersionPlan(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	future := tableMigration(2, "two")
+	future.MinAppVersion = "2.0.0"
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		future,
+	})
+
+	plan, err := db.Plan(context.Background(), litemigrate.WithAppVersion("1.0.0"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(plan.Migrations) != 1 || plan.Migrations[0].Version != 1 {
+		t.Errorf("expected only version 1 to be planned, got %+v", plan.Migrations)
+	}
+}
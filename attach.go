@@ -0,0 +1,128 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+// attachment is a SQLite file attached to a connection under a schema name.
+type attachment struct {
+	name string
+	path string
+}
+
+// Attach attaches the SQLite file at path as the schema name to the connections running
+// migrations, so migrations can span several databases, e.g. with CREATE TABLE analytics.events.
+// The file is created if needed. Databases are attached before every run and detached after it,
+// and changes to all of them are committed in the same transaction. A migration's Database field
+// names the database it targets, which is recorded in the migration table.
+func (db *Database) Attach(name, path string) *Database {
+	db.attached = append(db.attached, attachment{name: name, path: path})
+	return db
+}
+
+// target returns the name of the database the migration targets.
+func (m Migration) target() string {
+	if m.Database == "" {
+		return "main"
+	}
+	return m.Database
+}
+
+// attachments returns the history database and the attached databases.
+func (db *Database) attachments() []attachment {
+	attachments := make([]attachment, 0, len(db.attached)+1)
+	if db.historyDatabase != "" {
+		attachments = append(attachments, attachment{name: historySchema, path: db.historyDatabase})
+	}
+	return append(attachments, db.attached...)
+}
+
+// checkAttached returns an error if an attached database uses a reserved or duplicate name, or a
+// migration targets a database that isn't attached.
+func (db *Database) checkAttached() error {
+	names := map[string]bool{}
+	for _, attachment := range db.attached {
+		if attachment.name == "" || attachment.name == "main" || attachment.name == "temp" || attachment.name == historySchema || names[attachment.name] {
+			return errorf(CodeInvalidOption, "can't attach %s as %q: the name is reserved or already used", attachment.path, attachment.name)
+		}
+		names[attachment.name] = true
+	}
+
+	for _, migration := range *db.migrations {
+		if migration.target() != "main" && !names[migration.Database] {
+			return errorf(CodeInvalidMigration, "invalid migration: (version=%v, description=%s) targets database %s, which isn't attached", migration.Version, migration.Description, migration.Database)
+		}
+	}
+	return nil
+}
+
+// attach attaches the databases to the connection unless they already are, and returns a function
+// detaching the ones it attached. It must be called outside of a transaction.
+func attach(ctx context.Context, conn *sql.Conn, attachments []attachment) (func(), error) {
+	attached := make([]string, 0, len(attachments))
+	detach := func() {
+		for _, name := range attached {
+			if _, err := conn.ExecContext(context.Background(), fmt.Sprintf("DETACH DATABASE %s;", quoteIdent(name))); err != nil {
+				log.Printf("failed to detach database (name=%s): %v", name, err)
+			}
+		}
+	}
+
+	for _, attachment := range attachments {
+		exists := false
+		err := conn.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM pragma_database_list WHERE name = ?;", attachment.name).Scan(&exists)
+		if err != nil {
+			detach()
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("ATTACH DATABASE ? AS %s;", quoteIdent(attachment.name)), attachment.path); err != nil {
+			detach()
+			return nil, errorf(CodeMigrationTable, "failed to attach %s as %s: %w", attachment.path, attachment.name, err)
+		}
+		attached = append(attached, attachment.name)
+	}
+	return detach, nil
+}
+
+// snapshotAttachments copies the history database and the attached databases into dir with VACUUM
+// INTO, and attaches the copies to the snapshot.
+func (db *Database) snapshotAttachments(ctx context.Context, dir string, snapshot *Database) error {
+	attachments := db.attachments()
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	detach, err := attach(ctx, conn, attachments)
+	if err != nil {
+		return err
+	}
+	defer detach()
+
+	for _, attachment := range attachments {
+		path := filepath.Join(dir, attachment.name+".db")
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("VACUUM %s INTO ?;", quoteIdent(attachment.name)), path); err != nil {
+			return errorf(CodeSnapshotFailed, "failed to take snapshot of %s: %w", attachment.name, err)
+		}
+
+		if attachment.name == historySchema {
+			snapshot.historyDatabase = path
+		} else {
+			snapshot.Attach(attachment.name, path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestAttach(t *testing.T) {
+	dir := t.TempDir()
+	conn, err := sql.Open("sqlite3", filepath.Join(dir, "data.db"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	events := tableMigration(2, "analytics.events")
+	events.Database = "analytics"
+
+	ctx := context.Background()
+	analyticsPath := filepath.Join(dir, "analytics.db")
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "users"),
+		events,
+	}).Attach("analytics", analyticsPath)
+
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var attached int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM pragma_database_list WHERE name = 'analytics';").Scan(&attached); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if attached != 0 {
+		t.Errorf("expected the database to be detached after the run")
+	}
+
+	analytics, err := sql.Open("sqlite3", analyticsPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer analytics.Close()
+
+	var tables int
+	if err := analytics.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'events';").Scan(&tables); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tables != 1 {
+		t.Errorf("expected the events table in the attached database")
+	}
+
+	history, err := db.History(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(history) != 2 || history[0].Database != "main" || history[1].Database != "analytics" {
+		t.Errorf("expected the target databases to be recorded, got %+v", history)
+	}
+
+	if _, err := db.MigrateDown(ctx, 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := analytics.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'events';").Scan(&tables); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("expected the events table to be dropped")
+	}
+}
+
+func TestAttachUnknownDatabase(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	events := tableMigration(1, "analytics.events")
+	events.Database = "analytics"
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{events})
+
+	_, err = db.MigrateUp(context.Background())
+	var lmErr *litemigrate.Error
+	if !errors.As(err, &lmErr) || lmErr.Code != litemigrate.CodeInvalidMigration {
+		t.Fatalf("expected an invalid migration error, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package litemigrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// awaitInterval is how often a waiting run checks whether the lock is released.
+const awaitInterval = 100 * time.Millisecond
+
+// SetAwait makes MigrateUp wait up to the timeout when another process holds the database's write
+// lock, typically another replica of the service migrating the same database at startup. Once the
+// lock is released, the run doesn't apply anything itself but verifies that no migration is pending,
+// and fails with CodeAwaitFailed if the timeout expires first or the other process left migrations
+// pending. By default, such a run fails with CodeLocked.
+func (db *Database) SetAwait(timeout time.Duration) *Database {
+	db.awaitTimeout = timeout
+	return db
+}
+
+// WithAwait overrides SetAwait for a run.
+func WithAwait(timeout time.Duration) RunOption {
+	return func(c *runConfig) {
+		c.awaitTimeout = timeout
+	}
+}
+
+// isBusy reports whether err was caused by another connection holding a lock on the database. The
+// package doesn't depend on a driver, so it matches the message SQLite uses for SQLITE_BUSY.
+func isBusy(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "database is locked")
+}
+
+// lockedError marks an error caused by another connection holding the write lock with CodeLocked.
+func lockedError(err error) error {
+	if isBusy(err) {
+		return errorf(CodeLocked, "database is locked by another connection: %w", err)
+	}
+	return err
+}
+
+// await waits until the write lock is released and returns an empty result if no migration of the
+// run is pending. Like MigrateUp, it doesn't count deferred, unmet and excluded migrations.
+func (db *Database) await(ctx context.Context, cfg *runConfig) (*Result, error) {
+	log.Printf("waiting for another migrator to release the lock (timeout=%s)", cfg.awaitTimeout)
+
+	waitCtx, cancel := context.WithTimeout(ctx, cfg.awaitTimeout)
+	defer cancel()
+
+	for {
+		err := db.probeLock(waitCtx)
+		if err == nil {
+			break
+		}
+		if !isBusy(err) && waitCtx.Err() == nil {
+			return nil, err
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, errorf(CodeAwaitFailed, "lock wasn't released within %s", cfg.awaitTimeout)
+		case <-time.After(awaitInterval):
+		}
+	}
+
+	// Conditions and adoption are checked inside a transaction that is rolled back, so the pending
+	// migrations are the ones MigrateUp would apply.
+	tx, release, err := db.beginState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := db.createMigrationTable(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	index, err := db.getMigrationIndex(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]string, 0)
+	migrations := cfg.limit(db.migrations.sorted())
+	selection := db.newSelection(cfg, index, migrations)
+	for _, migration := range migrations {
+		outcome, err := selection.decide(ctx, tx, migration)
+		if err != nil {
+			return nil, err
+		}
+		if outcome == outcomeApply || outcome == outcomeAdopted {
+			pending = append(pending, fmt.Sprint(migration.Version))
+		}
+	}
+
+	if len(pending) > 0 {
+		return nil, errorf(CodeAwaitFailed, "lock was released but migrations are still pending: %s", strings.Join(pending, ", "))
+	}
+
+	log.Printf("another migrator brought the database up to date")
+	return newResult(Up), nil
+}
+
+// probeLock takes the write lock and releases it right away.
+func (db *Database) probeLock(ctx context.Context) error {
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE;"); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, "ROLLBACK;")
+	return err
+}
@@ -0,0 +1,129 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestAwait(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "app.db") + "?_busy_timeout=50"
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := tableMigration(1, "users")
+	up := blocking.Up
+	blocking.Up = func(tx *sql.Tx) error {
+		if err := up(tx); err != nil {
+			return err
+		}
+		close(started)
+		<-release
+		return nil
+	}
+
+	first, err := litemigrate.New(dsn, &litemigrate.Migrations{blocking})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer first.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := first.MigrateUp(ctx)
+		done <- err
+	}()
+	<-started
+
+	second, err := litemigrate.New(dsn, &litemigrate.Migrations{tableMigration(1, "users")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer second.Close()
+
+	if _, err := second.MigrateUp(ctx); litemigrate.Code(err) != litemigrate.CodeLocked {
+		t.Errorf("expected a locked error without waiting, got %v", err)
+	}
+
+	_, err = second.MigrateUp(ctx, litemigrate.WithAwait(100*time.Millisecond))
+	if litemigrate.Code(err) != litemigrate.CodeAwaitFailed {
+		t.Errorf("expected the wait to time out, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		close(release)
+	}()
+
+	result, err := second.SetAwait(5 * time.Second).MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Applied) != 0 {
+		t.Errorf("expected the waiting run to apply nothing, got %v", result.Applied)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestAwaitConditional(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "app.db") + "?_busy_timeout=50"
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := tableMigration(1, "users")
+	up := blocking.Up
+	blocking.Up = func(tx *sql.Tx) error {
+		if err := up(tx); err != nil {
+			return err
+		}
+		close(started)
+		<-release
+		return nil
+	}
+
+	legacy := tableMigration(2, "legacy_copy")
+	legacy.Condition = func(ctx context.Context, tx *sql.Tx) (bool, error) {
+		return false, nil
+	}
+
+	first, err := litemigrate.New(dsn, &litemigrate.Migrations{blocking, legacy})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer first.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := first.MigrateUp(ctx)
+		done <- err
+	}()
+	<-started
+
+	second, err := litemigrate.New(dsn, &litemigrate.Migrations{tableMigration(1, "users"), legacy})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer second.Close()
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		close(release)
+	}()
+
+	if _, err := second.MigrateUp(ctx, litemigrate.WithAwait(5*time.Second)); err != nil {
+		t.Fatalf("expected the unmet migration not to count as pending, got %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
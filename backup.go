@@ -0,0 +1,155 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SetBackup makes MigrateUp copy the database into a file in dir with VACUUM INTO before
+// migrating, and restore the copy if the run fails. This is a safety net for changes the
+// transaction can't roll back. The copy is removed after the run, unless restoring it fails.
+func (db *Database) SetBackup(dir string) *Database {
+	db.backupDir = dir
+	return db
+}
+
+// withBackup backs up the database, runs f and restores the backup if f fails.
+func (db *Database) withBackup(ctx context.Context, f func() error) error {
+	path := filepath.Join(db.backupDir, fmt.Sprintf("litemigrate-backup-%s.db", time.Now().UTC().Format("20060102150405.000000000")))
+	if _, err := db.conn.ExecContext(ctx, "VACUUM INTO ?;", path); err != nil {
+		return errorf(CodeBackupFailed, "failed to back up database: %w", err)
+	}
+	log.Printf("backed up database (path=%s)", path)
+
+	runErr := f()
+	if runErr == nil {
+		os.Remove(path)
+		return nil
+	}
+
+	// The run's context may be the reason it failed, so the restore must not depend on it.
+	if err := db.restore(context.Background(), path); err != nil {
+		return errorf(CodeRestoreFailed, "%w; restoring the backup %s failed: %v", runErr, path, err)
+	}
+	os.Remove(path)
+
+	log.Printf("restored database from backup after failed run")
+	return runErr
+}
+
+// restore replaces the contents of the database with those of the backup, by recreating its
+// schema and copying its rows inside a transaction.
+func (db *Database) restore(ctx context.Context, path string) error {
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS litemigrate_backup;", path); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "DETACH DATABASE litemigrate_backup;")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON;"); err != nil {
+		return err
+	}
+
+	current, err := schemaStatements(ctx, tx, "main", "table")
+	if err != nil {
+		return err
+	}
+	for _, object := range current {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE main.%s;", quoteIdent(object.Name))); err != nil {
+			return err
+		}
+	}
+
+	// Views and triggers may outlive the tables they refer to.
+	for _, kind := range []string{"view", "trigger"} {
+		remaining, err := schemaStatements(ctx, tx, "main", kind)
+		if err != nil {
+			return err
+		}
+		for _, object := range remaining {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP %s main.%s;", strings.ToUpper(kind), quoteIdent(object.Name))); err != nil {
+				return err
+			}
+		}
+	}
+
+	tables, err := schemaStatements(ctx, tx, "litemigrate_backup", "table")
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if _, err := tx.ExecContext(ctx, table.SQL); err != nil {
+			return err
+		}
+		query := fmt.Sprintf("INSERT INTO main.%s SELECT * FROM litemigrate_backup.%s;", quoteIdent(table.Name), quoteIdent(table.Name))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	// AUTOINCREMENT counters live in sqlite_sequence, which exists in both databases or in neither.
+	sequences := false
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM litemigrate_backup.sqlite_master WHERE name = 'sqlite_sequence';").Scan(&sequences)
+	if err != nil {
+		return err
+	}
+	if sequences {
+		_, err := tx.ExecContext(ctx, "DELETE FROM main.sqlite_sequence; INSERT INTO main.sqlite_sequence SELECT * FROM litemigrate_backup.sqlite_sequence;")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Triggers are created last, so they don't fire while the rows are copied.
+	for _, kind := range []string{"index", "view", "trigger"} {
+		objects, err := schemaStatements(ctx, tx, "litemigrate_backup", kind)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			if _, err := tx.ExecContext(ctx, object.SQL); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// schemaStatements returns the user-defined objects of a kind in a schema, in creation order.
+func schemaStatements(ctx context.Context, tx *sql.Tx, schema, kind string) ([]schemaObject, error) {
+	query := fmt.Sprintf("SELECT name, COALESCE(sql, '') FROM %s.sqlite_master WHERE type = ? AND name NOT LIKE 'sqlite_%%' ORDER BY rowid;", schema)
+	rows, err := tx.QueryContext(ctx, query, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := make([]schemaObject, 0)
+	for rows.Next() {
+		object := schemaObject{Type: kind}
+		if err := rows.Scan(&object.Name, &object.SQL); err != nil {
+			return nil, err
+		}
+		if object.SQL != "" {
+			objects = append(objects, object)
+		}
+	}
+	return objects, rows.Err()
+}
@@ -0,0 +1,109 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestBackup(t *testing.T) {
+	dir := t.TempDir()
+	migrations := &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Create users table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT);
+					CREATE INDEX users_name ON users (name);
+					CREATE TRIGGER users_default AFTER INSERT ON users WHEN NEW.name IS NULL
+					BEGIN
+						UPDATE users SET name = 'unknown' WHERE id = NEW.id;
+					END;
+					INSERT INTO users (name) VALUES ('alice'), (NULL);
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE users;`)
+				return err
+			},
+		},
+	}
+
+	db, err := litemigrate.New(filepath.Join(dir, "test.db"), migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	backups := filepath.Join(dir, "backups")
+	if err := os.Mkdir(backups, 0o755); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	db.SetBackup(backups)
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	failed := errors.New("failed")
+	*migrations = append(*migrations, litemigrate.Migration{
+		Version:     2,
+		Description: "Fail",
+		Up: func(tx *sql.Tx) error {
+			return failed
+		},
+		Down: func(tx *sql.Tx) error {
+			return nil
+		},
+	})
+
+	if _, err := db.MigrateUp(context.Background()); !errors.Is(err, failed) {
+		t.Fatalf("expected error %v, got %v", failed, err)
+	}
+
+	*migrations = (*migrations)[:1]
+	report, err := db.Validate(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !report.Valid() {
+		t.Errorf("expected the restored history to match, got %v", report)
+	}
+
+	conn, err := sql.Open("sqlite3", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Exec(`INSERT INTO users (name) VALUES (NULL);`); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var id int64
+	var name string
+	if err := conn.QueryRow(`SELECT id, name FROM users ORDER BY id DESC LIMIT 1;`).Scan(&id, &name); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if id != 3 || name != "unknown" {
+		t.Errorf("expected restored sequence and trigger (id=3, name=unknown), got (id=%d, name=%s)", id, name)
+	}
+
+	entries, err := os.ReadDir(backups)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("expected backups to be removed, got %d files", len(entries))
+	}
+}
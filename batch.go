@@ -0,0 +1,105 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DefaultBatchSize is the number of rows a batch processes when BatchOptions.Size isn't set.
+const DefaultBatchSize = 1000
+
+// BatchFunc processes up to limit rows in a transaction and returns the number of rows it
+// processed. A batch that processes fewer rows than the limit ends the run, so it must skip rows
+// processed by earlier batches, e.g. with a WHERE clause on the new column.
+type BatchFunc func(ctx context.Context, tx *sql.Tx, limit int) (int64, error)
+
+// BatchOptions configures RunBatches.
+type BatchOptions struct {
+	// Size is the maximum number of rows processed by a batch. It defaults to DefaultBatchSize.
+	Size int
+	// Pause is the time waited between batches, so other writers get the database in between.
+	Pause time.Duration
+	// Progress is called after every committed batch.
+	Progress func(BatchProgress)
+}
+
+// BatchProgress reports the progress of RunBatches.
+type BatchProgress struct {
+	// Batch is the number of batches committed so far.
+	Batch int
+	// Rows is the number of rows the committed batches processed.
+	Rows     int64
+	Duration time.Duration
+}
+
+// RunBatches runs a data migration, such as backfilling a new column, in batches that each commit
+// their own transaction, so a migration over millions of rows neither holds one huge transaction
+// nor blocks other writers for its whole duration. It runs until a batch processes fewer rows than
+// the batch size and returns the number of rows processed. When the context is canceled, the
+// batches committed so far are kept.
+//
+// The batches run outside of the migration run, typically after MigrateUp has added the schema
+// they need:
+//
+//	_, err := db.RunBatches(ctx, litemigrate.BatchOptions{Size: 500}, func(ctx context.Context, tx *sql.Tx, limit int) (int64, error) {
+//		res, err := tx.ExecContext(ctx, "UPDATE users SET email_lower = lower(email) WHERE rowid IN (SELECT rowid FROM users WHERE email_lower IS NULL LIMIT ?);", limit)
+//		if err != nil {
+//			return 0, err
+//		}
+//		return res.RowsAffected()
+//	})
+func (db *Database) RunBatches(ctx context.Context, opts BatchOptions, batch BatchFunc) (int64, error) {
+	size := opts.Size
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	start := time.Now()
+	progress := BatchProgress{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress.Rows, err
+		}
+
+		rows, err := db.runBatch(ctx, size, batch)
+		if err != nil {
+			return progress.Rows, fmt.Errorf("batch %d failed: %w", progress.Batch+1, err)
+		}
+
+		progress.Batch++
+		progress.Rows += rows
+		progress.Duration = time.Since(start)
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+
+		if rows < int64(size) {
+			return progress.Rows, nil
+		}
+
+		if opts.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return progress.Rows, ctx.Err()
+			case <-time.After(opts.Pause):
+			}
+		}
+	}
+}
+
+// runBatch runs a single batch in its own transaction.
+func (db *Database) runBatch(ctx context.Context, size int, batch BatchFunc) (int64, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := batch(ctx, tx, size)
+	if err != nil {
+		return 0, err
+	}
+	return rows, tx.Commit()
+}
@@ -0,0 +1,112 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func backfill(ctx context.Context, tx *sql.Tx, limit int) (int64, error) {
+	res, err := tx.ExecContext(ctx, "UPDATE users SET name_lower = lower(name) WHERE rowid IN (SELECT rowid FROM users WHERE name_lower IS NULL LIMIT ?);", limit)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func TestRunBatches(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, name_lower TEXT);
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 25)
+		INSERT INTO users (id, name) SELECT i, 'User' || i FROM n;
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{})
+
+	reports := make([]litemigrate.BatchProgress, 0)
+	rows, err := db.RunBatches(ctx, litemigrate.BatchOptions{
+		Size:     10,
+		Progress: func(p litemigrate.BatchProgress) { reports = append(reports, p) },
+	}, backfill)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if rows != 25 {
+		t.Errorf("expected 25 rows, got %d", rows)
+	}
+	if len(reports) != 3 || reports[2].Batch != 3 || reports[2].Rows != 25 {
+		t.Errorf("expected 3 progress reports ending at 25 rows, got %+v", reports)
+	}
+
+	missing := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM users WHERE name_lower IS NULL;").Scan(&missing); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if missing != 0 {
+		t.Errorf("expected every row to be backfilled, got %d missing", missing)
+	}
+}
+
+func TestRunBatchesKeepsCommittedBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, name_lower TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'A'), (2, 'B'), (3, 'C');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{})
+
+	rows, err := db.RunBatches(ctx, litemigrate.BatchOptions{
+		Size:     1,
+		Progress: func(p litemigrate.BatchProgress) { cancel() },
+	}, backfill)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+	if rows != 1 {
+		t.Errorf("expected 1 row, got %d", rows)
+	}
+
+	done := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM users WHERE name_lower IS NOT NULL;").Scan(&done); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if done != 1 {
+		t.Errorf("expected the first batch to be committed, got %d rows", done)
+	}
+
+	failing := func(ctx context.Context, tx *sql.Tx, limit int) (int64, error) {
+		return 0, errors.New("boom")
+	}
+	if _, err := db.RunBatches(context.Background(), litemigrate.BatchOptions{}, failing); err == nil {
+		t.Error("expected the batch error")
+	}
+}
@@ -0,0 +1,119 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SetCommitEach makes MigrateUp commit every migration in its own transaction instead of running
+// the whole run in one. When a migration fails, the ones before it stay applied, and the failed
+// migration is recorded in a marker table, so the next MigrateUp resumes from it instead of
+// starting over. Integrity and foreign key checks run once after the last migration. Dry runs
+// still run in a single transaction.
+func (db *Database) SetCommitEach(commitEach bool) *Database {
+	db.commitEach = commitEach
+	return db
+}
+
+// WithCommitEach overrides SetCommitEach for a run.
+func WithCommitEach(commitEach bool) RunOption {
+	return func(c *runConfig) {
+		c.commitEach = commitEach
+	}
+}
+
+// FailedMigration is the marker of a migration that failed in a run committing every migration.
+type FailedMigration struct {
+	Version  int64     `json:"version"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// failedTable returns the name of the table holding the failed migration marker.
+func (db *Database) failedTable() string {
+	return db.migrationTable + "_failed"
+}
+
+// FailedMigration returns the marker of the migration that failed in the last run committing every
+// migration, or nil if there is none. The marker is removed once the migration is applied.
+func (db *Database) FailedMigration(ctx context.Context) (*FailedMigration, error) {
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return db.readFailed(ctx, q)
+}
+
+func (db *Database) readFailed(ctx context.Context, q queryer) (*FailedMigration, error) {
+	exists, err := db.stateTableExists(ctx, q, db.failedTable())
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT version, error, failed_at FROM %s LIMIT 1;", db.qualify(db.failedTable())))
+	if err != nil {
+		return nil, errorf(CodeMigrationTable, "failed to read failed migration: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		failed   FailedMigration
+		failedAt string
+	)
+	if err := rows.Scan(&failed.Version, &failed.Error, &failedAt); err != nil {
+		return nil, err
+	}
+	failed.FailedAt, _ = time.Parse(time.RFC3339Nano, failedAt)
+	return &failed, rows.Err()
+}
+
+// markFailed records the migration as failed in a transaction of its own, replacing any earlier
+// marker.
+func (db *Database) markFailed(ctx context.Context, migration Migration, cause error) error {
+	tx, release, err := db.beginState(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			version INTEGER NOT NULL,
+			error TEXT NOT NULL,
+			failed_at TEXT NOT NULL
+		);
+	`, db.qualify(db.failedTable())))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s;", db.qualify(db.failedTable()))); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (version, error, failed_at) VALUES (?, ?, ?);", db.qualify(db.failedTable()))
+	if _, err := tx.ExecContext(ctx, query, migration.Version, cause.Error(), time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("recorded failed migration (version=%v, description=%s)", migration.Version, migration.Description)
+	return nil
+}
+
+// clearFailed removes the failed migration marker.
+func (db *Database) clearFailed(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s;", db.qualify(db.failedTable())))
+	return err
+}
@@ -0,0 +1,74 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestCommitEach(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	broken := true
+	flaky := tableMigration(2, "two")
+	up := flaky.Up
+	flaky.Up = func(tx *sql.Tx) error {
+		if broken {
+			return errors.New("backfill failed")
+		}
+		return up(tx)
+	}
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one"), flaky, tableMigration(3, "three")}).SetCommitEach(true)
+	result, err := db.MigrateUp(ctx)
+	if litemigrate.Code(err) != litemigrate.CodeMigrationFailed {
+		t.Fatalf("expected error code %s, got %v", litemigrate.CodeMigrationFailed, err)
+	}
+
+	if result == nil || len(result.Applied) != 1 || result.Failed != 2 {
+		t.Fatalf("expected version 1 to be committed and version 2 to fail, got %+v", result)
+	}
+
+	failed, err := db.FailedMigration(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if failed == nil || failed.Version != 2 || failed.Error == "" || failed.FailedAt.IsZero() {
+		t.Fatalf("expected a marker for version 2, got %+v", failed)
+	}
+
+	status, err := db.Status(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Version != 1 || status.Failed == nil || status.Failed.Version != 2 {
+		t.Errorf("expected version 1 with failed version 2, got %+v", status)
+	}
+
+	broken = false
+	result, err = db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 2 || result.Applied[0] != 2 || result.Applied[1] != 3 {
+		t.Errorf("expected to resume from version 2, got %v", result.Applied)
+	}
+
+	failed, err = db.FailedMigration(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if failed != nil {
+		t.Errorf("expected the marker to be cleared, got %+v", failed)
+	}
+}
@@ -0,0 +1,621 @@
+// Package cli implements a command line interface for running migrations, so applications can
+// ship a migration binary with a few lines of code:
+//
+//	func main() {
+//		cli.Main(&migrations)
+//	}
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const usage = `usage: litemigrate [-config file] [-profile name] [-db dsn] [-dir dir] [-table name] [-history-db path] [-lock-file path] [-roles roles] [-env name] [-json] [-plain] [-detailed-exit-codes] <command> [arguments]
+
+commands:
+  create [-sql] <name>       create a new migration with the next version
+  up [-estimate] [-yes] [-allow-destructive] [-phase phase] [-post-deploy] [-commit-each] [-only versions] [-exclude versions] [-app-version version]
+                             migrate the database up to the latest version
+  down [-n amount] [-dry-run] [-override-protection]
+                             migrate the database down by the given amount
+  reset [-override-protection]
+                             roll back every migration and apply them all again
+  status                     print the current version and the pending migrations
+  version                    print the current and latest version, failing when migrations are pending
+  force <version>            record the version as current after manual recovery, without running migrations
+  validate                   check the migration table against the migrations, failing on any problem
+  schema                     print the schema of the database
+`
+
+// App is a command line application that runs a set of migrations.
+type App struct {
+	migrations *litemigrate.Migrations
+	in         *bufio.Reader
+	out        io.Writer
+	translate  Translator
+	json       bool
+
+	// detailedExitCodes is set by -detailed-exit-codes, and changed by up and down when they
+	// applied or rolled back migrations.
+	detailedExitCodes bool
+	changed           bool
+
+	// rich overrides whether rich output is used, and plain is set by -plain.
+	rich  *bool
+	plain bool
+}
+
+// New creates a new command line application for the migrations.
+func New(migrations *litemigrate.Migrations) *App {
+	return &App{
+		migrations: migrations,
+		in:         bufio.NewReader(os.Stdin),
+		out:        os.Stdout,
+	}
+}
+
+// Main runs the command line application with the process arguments and exits with the exit code
+// of the outcome, see ExitCode.
+func Main(migrations *litemigrate.Migrations) {
+	app := New(migrations)
+	err := app.Run(context.Background(), os.Args[1:])
+	if err != nil {
+		if code := litemigrate.Code(err); code != "" {
+			fmt.Fprintf(os.Stderr, "litemigrate: %v (code=%s)\n", err, code)
+		} else {
+			fmt.Fprintf(os.Stderr, "litemigrate: %v\n", err)
+		}
+	}
+	os.Exit(app.ExitCode(err))
+}
+
+// SetInput sets the reader used to answer prompts.
+func (a *App) SetInput(r io.Reader) *App {
+	a.in = bufio.NewReader(r)
+	return a
+}
+
+// SetOutput sets the writer used for command output.
+func (a *App) SetOutput(w io.Writer) *App {
+	a.out = w
+	return a
+}
+
+// globals are the flags shared by all commands.
+type globals struct {
+	dsn       string
+	dir       string
+	table     string
+	historyDB string
+	lockFile  string
+	roles     string
+	env       string
+}
+
+// Run parses the arguments and runs the requested command.
+func (a *App) Run(ctx context.Context, args []string) error {
+	a.changed = false
+
+	var (
+		g                   globals
+		configPath, profile string
+	)
+	fs := flag.NewFlagSet("litemigrate", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	fs.Usage = func() { fmt.Fprint(a.out, a.tr(usage)) }
+	fs.StringVar(&configPath, "config", os.Getenv("LITEMIGRATE_CONFIG"), "configuration file, litemigrate.yaml, litemigrate.yml or litemigrate.toml when present (defaults to $LITEMIGRATE_CONFIG)")
+	fs.StringVar(&profile, "profile", os.Getenv("LITEMIGRATE_PROFILE"), "profile of the configuration file whose settings are used (defaults to $LITEMIGRATE_PROFILE)")
+	fs.StringVar(&g.dsn, "db", os.Getenv("LITEMIGRATE_DB"), "database DSN, ${NAME} is replaced by environment variables (defaults to $LITEMIGRATE_DB)")
+	fs.StringVar(&g.dir, "dir", "", "directory or zip/tar.gz archive of SQL migrations to run along with the application's migrations")
+	fs.StringVar(&g.env, "env", os.Getenv("LITEMIGRATE_ENV"), "environment the SQL migrations are rendered for, see sqlfile.WithEnvironment (defaults to $LITEMIGRATE_ENV)")
+	fs.StringVar(&g.table, "table", "_migrations", "name of the migration table")
+	fs.StringVar(&g.historyDB, "history-db", "", "SQLite file storing the migration table instead of the database")
+	fs.StringVar(&g.lockFile, "lock-file", "", "file locked for the whole run to serialize migrators, see Database.SetLockFile")
+	fs.StringVar(&g.roles, "roles", os.Getenv("LITEMIGRATE_ROLES"), "comma separated roles allowed to run, all when empty (defaults to $LITEMIGRATE_ROLES)")
+	fs.BoolVar(&a.json, "json", false, "print machine-readable JSON instead of text, including errors")
+	fs.BoolVar(&a.plain, "plain", false, "print plain text without progress, colors or tables, even to a terminal")
+	fs.BoolVar(&a.detailedExitCodes, "detailed-exit-codes", false, "exit with 2 instead of 0 when up or down applied or rolled back migrations")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if err := g.configure(fs, configPath, profile); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no command given")
+	}
+
+	err := a.run(ctx, fs, g, fs.Arg(0), fs.Args()[1:])
+	// version and validate already printed their output when they fail a check.
+	if err != nil && a.json && !errors.Is(err, errBehind) && !errors.Is(err, errInvalid) {
+		a.printJSON(errorOutput{Error: err.Error(), Code: litemigrate.Code(err)})
+	}
+	return err
+}
+
+// run runs a command with the parsed global flags.
+func (a *App) run(ctx context.Context, fs *flag.FlagSet, g globals, command string, args []string) error {
+	switch command {
+	case "create":
+		if g.dir == "" {
+			g.dir = "."
+		}
+		return a.create(args, g.dir)
+	case "up", "down", "reset", "status", "version", "validate", "force", "schema":
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command: %s", command)
+	}
+
+	if g.dsn == "" {
+		return fmt.Errorf("no database given: set -db or LITEMIGRATE_DB")
+	}
+
+	migrations, repeatables, err := a.load(g.dir, g.env)
+	if err != nil {
+		return err
+	}
+
+	db, err := litemigrate.NewWithSecrets(ctx, g.dsn, litemigrate.EnvSecrets{}, migrations)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	db.SetMigrationTable(g.table).SetRepeatables(repeatables...)
+
+	if g.historyDB != "" {
+		db.SetHistoryDatabase(g.historyDB)
+	}
+
+	if g.lockFile != "" {
+		db.SetLockFile(g.lockFile)
+	}
+
+	if g.roles != "" {
+		db.SetAllowedRoles(splitList(g.roles)...)
+	}
+
+	switch command {
+	case "up":
+		return a.up(ctx, db, args)
+	case "status":
+		return a.status(ctx, db)
+	case "version":
+		return a.version(ctx, db, migrations)
+	case "validate":
+		return a.validate(ctx, db)
+	case "force":
+		return a.force(ctx, db, args)
+	case "reset":
+		return a.reset(ctx, db, args)
+	case "schema":
+		return a.schema(ctx, db)
+	default:
+		return a.down(ctx, db, args)
+	}
+}
+
+// load returns the application's migrations together with the SQL migrations in dir, and the
+// repeatable SQL migrations in dir. dir may also be a zip or tar.gz archive. When env is set, the
+// SQL files are rendered for the environment.
+func (a *App) load(dir, env string) (*litemigrate.Migrations, []litemigrate.Repeatable, error) {
+	if dir == "" {
+		return a.migrations, nil, nil
+	}
+
+	fsys := os.DirFS(dir)
+	if isArchive(dir) {
+		var err error
+		if fsys, err = sqlfile.OpenArchive(dir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	opts := make([]sqlfile.Option, 0)
+	if env != "" {
+		opts = append(opts, sqlfile.WithEnvironment(env, nil))
+	}
+
+	files, err := sqlfile.Load(fsys, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repeatables, err := sqlfile.LoadRepeatable(fsys, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	migrations, err := litemigrate.Merge(*a.migrations, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &migrations, repeatables, nil
+}
+
+// isArchive reports whether the path names a zip or tar.gz archive.
+func isArchive(path string) bool {
+	for _, ext := range []string{".zip", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(strings.ToLower(path), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) up(ctx context.Context, db *litemigrate.Database, args []string) error {
+	fs := flag.NewFlagSet("up", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	estimate := fs.Bool("estimate", false, "print the estimated impact and ask before migrating (with -json, only print it unless -yes is set)")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	allowDestructive := fs.Bool("allow-destructive", false, "apply migrations with destructive statements such as DROP TABLE")
+	phase := fs.String("phase", "", "only apply migrations of the phase, expand or contract")
+	postDeploy := fs.Bool("post-deploy", false, "only apply the post-deploy migrations, which are skipped otherwise")
+	commitEach := fs.Bool("commit-each", false, "commit every migration on its own, so a failed run resumes from the failed migration")
+	only := fs.String("only", "", "comma separated versions to apply, ignoring the other pending migrations")
+	exclude := fs.String("exclude", "", "comma separated versions to bypass, recording them as intentionally skipped")
+	appVersion := fs.String("app-version", "", "the application version, deferring migrations that require a newer one")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := []litemigrate.RunOption{litemigrate.WithAllowDestructive(*allowDestructive)}
+	switch litemigrate.Phase(*phase) {
+	case "":
+	case litemigrate.Expand, litemigrate.Contract:
+		opts = append(opts, litemigrate.WithPhase(litemigrate.Phase(*phase)))
+	default:
+		return fmt.Errorf("invalid phase %q, must be expand or contract", *phase)
+	}
+	if *postDeploy {
+		opts = append(opts, litemigrate.WithPostDeploy())
+	}
+	if *commitEach {
+		opts = append(opts, litemigrate.WithCommitEach(true))
+	}
+	if *only != "" {
+		versions, err := parseVersions(*only)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, litemigrate.WithVersions(versions...))
+	}
+	if *exclude != "" {
+		versions, err := parseVersions(*exclude)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, litemigrate.WithoutVersions(versions...))
+	}
+	if *appVersion != "" {
+		opts = append(opts, litemigrate.WithAppVersion(*appVersion))
+	}
+
+	var plan *litemigrate.Plan
+	if *estimate {
+		var err error
+		plan, err = db.Plan(ctx, opts...)
+		if err != nil {
+			return err
+		}
+
+		// JSON output can't be combined with a prompt, so the plan is only printed unless the run
+		// is confirmed up front.
+		if a.json && (len(plan.Migrations) == 0 || !*yes) {
+			return a.printJSON(runOutput{Plan: plan})
+		}
+
+		if len(plan.Migrations) == 0 {
+			fmt.Fprintln(a.out, a.tr("no pending migrations"))
+			return nil
+		}
+
+		if !a.json {
+			a.printPlan(plan)
+		}
+
+		if !*yes {
+			ok, err := a.confirm(a.tr("apply these migrations?"))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Fprintln(a.out, a.tr("aborted"))
+				return nil
+			}
+		}
+
+		// The approval is recorded with every migration of the run.
+		appliedBy := litemigrate.LocalAppliedBy(*appVersion)
+		appliedBy.ApprovedBy = appliedBy.User
+		db.SetAppliedBy(appliedBy)
+	}
+
+	var t *terminal
+	if a.richOutput() {
+		status, err := db.Status(ctx)
+		if err != nil {
+			return err
+		}
+		t = newTerminal(a, len(status.Pending))
+		db.SetProgressHandler(t.handle)
+	}
+
+	start := time.Now()
+	result, err := db.MigrateUp(ctx, opts...)
+	if t != nil {
+		t.summary(litemigrate.Up, time.Since(start), err)
+	}
+	if err != nil {
+		return err
+	}
+
+	a.changed = len(result.Applied) > 0
+	if !a.json {
+		return nil
+	}
+	return a.printJSON(runOutput{Plan: plan, Result: result})
+}
+
+func (a *App) down(ctx context.Context, db *litemigrate.Database, args []string) error {
+	fs := flag.NewFlagSet("down", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	amount := fs.Int("n", 1, "number of migrations to roll back")
+	dryRun := fs.Bool("dry-run", false, "list the migrations that would be rolled back, flagging irreversible ones, without rolling back")
+	override := fs.Bool("override-protection", false, "roll back even though $LITEMIGRATE_PROTECTED is set")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		return a.planDown(ctx, db, *amount)
+	}
+
+	opts, err := protect(db, *override)
+	if err != nil {
+		return err
+	}
+
+	var t *terminal
+	if a.richOutput() {
+		history, err := db.History(ctx)
+		if err != nil {
+			return err
+		}
+
+		total := 0
+		for _, record := range history {
+			if record.Status != litemigrate.StatusExcluded {
+				total++
+			}
+		}
+		if *amount < total {
+			total = *amount
+		}
+		t = newTerminal(a, total)
+		db.SetProgressHandler(t.handle)
+	}
+
+	start := time.Now()
+	result, err := db.MigrateDown(ctx, *amount, opts...)
+	if t != nil {
+		t.summary(litemigrate.Down, time.Since(start), err)
+	}
+	if err != nil {
+		return err
+	}
+
+	a.changed = len(result.RolledBack) > 0
+	if !a.json {
+		return nil
+	}
+	return a.printJSON(runOutput{Result: result})
+}
+
+func (a *App) force(ctx context.Context, db *litemigrate.Database, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("force takes exactly one version")
+	}
+
+	version, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid version %s: %w", args[0], err)
+	}
+
+	if err := db.Force(ctx, version); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(forceOutput{Version: version})
+	}
+	a.printf("forced version %d", version)
+	fmt.Fprintln(a.out)
+	return nil
+}
+
+func (a *App) schema(ctx context.Context, db *litemigrate.Database) error {
+	if !a.json {
+		return db.DumpSchema(ctx, a.out)
+	}
+
+	var schema strings.Builder
+	if err := db.DumpSchema(ctx, &schema); err != nil {
+		return err
+	}
+	hash, err := db.SchemaHash(ctx)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(schemaOutput{Schema: schema.String(), Hash: hash})
+}
+
+func (a *App) reset(ctx context.Context, db *litemigrate.Database, args []string) error {
+	fs := flag.NewFlagSet("reset", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	override := fs.Bool("override-protection", false, "reset even though $LITEMIGRATE_PROTECTED is set")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := protect(db, *override)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Reset(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	a.changed = len(result.RolledBack) > 0 || len(result.Applied) > 0
+	if !a.json {
+		return nil
+	}
+	return a.printJSON(runOutput{Result: result})
+}
+
+// protect protects the database against rollbacks when $LITEMIGRATE_PROTECTED is true, and returns
+// the run options overriding the protection if override is set.
+func protect(db *litemigrate.Database, override bool) ([]litemigrate.RunOption, error) {
+	if value := os.Getenv("LITEMIGRATE_PROTECTED"); value != "" {
+		protected, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LITEMIGRATE_PROTECTED: %w", err)
+		}
+		db.SetProtected(protected)
+	}
+
+	if override {
+		return []litemigrate.RunOption{litemigrate.WithProtected(false)}, nil
+	}
+	return nil, nil
+}
+
+// planDown prints the migrations down would roll back.
+func (a *App) planDown(ctx context.Context, db *litemigrate.Database, amount int) error {
+	plan, err := db.PlanDown(ctx, amount)
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(runOutput{Plan: plan})
+	}
+
+	if len(plan.Migrations) == 0 {
+		fmt.Fprintln(a.out, a.tr("no migrations to roll back"))
+		return nil
+	}
+
+	for _, migration := range plan.Migrations {
+		a.printf("version %v: %s", migration.Version, migration.Description)
+		if migration.Irreversible {
+			fmt.Fprintf(a.out, " (%s)", a.tr("irreversible"))
+		}
+		fmt.Fprintln(a.out)
+	}
+	return nil
+}
+
+func (a *App) printPlan(plan *litemigrate.Plan) {
+	for _, migration := range plan.Migrations {
+		a.printf("version %v: %s", migration.Version, migration.Description)
+		if migration.Destructive() {
+			fmt.Fprintf(a.out, " (%s)", a.tr("destructive"))
+		}
+		fmt.Fprintln(a.out)
+
+		a.printObjects(a.tr("created"), migration.Created, nil)
+		a.printObjects(a.tr("altered"), migration.Altered, migration.Rows)
+		a.printObjects(a.tr("dropped"), migration.Dropped, migration.Rows)
+		if len(migration.DroppedColumns) > 0 {
+			fmt.Fprintf(a.out, "  %s: %s\n", a.tr("dropped columns"), strings.Join(migration.DroppedColumns, ", "))
+		}
+		for _, statement := range migration.DestructiveStatements {
+			fmt.Fprintf(a.out, "  %s: %s\n", a.tr("destructive statement"), statement)
+		}
+		fmt.Fprintf(a.out, "  %s: %d\n", a.tr("rows changed"), migration.Changes)
+		fmt.Fprintf(a.out, "  %s: %s\n", a.tr("availability"), a.tr(string(migration.Availability)))
+		for _, reason := range migration.BlockingReasons {
+			fmt.Fprintf(a.out, "    %s\n", a.tr(reason))
+		}
+	}
+}
+
+func (a *App) printObjects(label string, objects []string, rows map[string]int64) {
+	for _, object := range objects {
+		fmt.Fprintf(a.out, "  %s: %s", label, object)
+		if count, ok := rows[strings.TrimPrefix(object, "table ")]; ok && strings.HasPrefix(object, "table ") {
+			fmt.Fprint(a.out, " (")
+			a.printf("%d rows", count)
+			fmt.Fprint(a.out, ")")
+		}
+		fmt.Fprintln(a.out)
+	}
+}
+
+func (a *App) confirm(prompt string) (bool, error) {
+	fmt.Fprintf(a.out, "%s %s ", prompt, a.tr("[y/N]"))
+
+	answer, err := a.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for _, yes := range []string{"y", "yes", a.tr("y"), a.tr("yes")} {
+		if answer == strings.ToLower(yes) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// splitList splits a comma separated list and trims the spaces around its items.
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseVersions parses a comma separated list of versions.
+func parseVersions(list string) ([]int64, error) {
+	versions := make([]int64, 0)
+	for _, item := range splitList(list) {
+		version, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %w", item, err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
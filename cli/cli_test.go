@@ -0,0 +1,245 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+var migrations = litemigrate.Migrations{
+	{
+		Version:     1,
+		Description: "Create test table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`CREATE TABLE test (id INTEGER PRIMARY KEY);`)
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`DROP TABLE test;`)
+			return err
+		},
+	},
+}
+
+func currentVersion(t *testing.T, dsn string) int64 {
+	t.Helper()
+
+	db, err := litemigrate.New(dsn, &migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	version, err := db.CurrentVersion(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return version
+}
+
+func TestUpEstimate(t *testing.T) {
+	tests := []struct {
+		answer  string
+		version int64
+	}{
+		{answer: "n\n", version: 0},
+		{answer: "y\n", version: 1},
+	}
+
+	for _, test := range tests {
+		dsn := filepath.Join(t.TempDir(), "test.db")
+
+		var out bytes.Buffer
+		app := cli.New(&migrations).SetInput(strings.NewReader(test.answer)).SetOutput(&out)
+
+		err := app.Run(context.Background(), []string{"-db", dsn, "up", "-estimate"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if !strings.Contains(out.String(), "created: table test") {
+			t.Errorf("expected estimate in output, got %q", out.String())
+		}
+
+		if version := currentVersion(t, dsn); version != test.version {
+			t.Errorf("expected version %d, got %d", test.version, version)
+		}
+	}
+}
+
+func TestUpEstimateApproval(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	app := cli.New(&migrations).SetInput(strings.NewReader("y\n")).SetOutput(&bytes.Buffer{})
+	if err := app.Run(context.Background(), []string{"-db", dsn, "up", "-estimate"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db, err := litemigrate.New(dsn, &migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	history, err := db.History(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(history))
+	}
+	if approvedBy := history[0].AppliedBy.ApprovedBy; approvedBy != litemigrate.LocalAppliedBy("").User {
+		t.Errorf("expected the run to be approved by the local user, got %q", approvedBy)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	err := cli.New(&migrations).SetOutput(&out).Run(context.Background(), []string{"sideways"})
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestRoles(t *testing.T) {
+	destructive := litemigrate.Migrations{migrations[0]}
+	destructive[0].Role = "destructive"
+
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	app := cli.New(&destructive).SetOutput(&bytes.Buffer{})
+
+	err := app.Run(context.Background(), []string{"-db", dsn, "-roles", "ddl, data", "up"})
+	if err == nil {
+		t.Error("expected error for disallowed role, got nil")
+	}
+
+	err = app.Run(context.Background(), []string{"-db", dsn, "-roles", "ddl,destructive", "up"})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestForce(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	var out bytes.Buffer
+	app := cli.New(&migrations).SetOutput(&out)
+	if err := app.Run(context.Background(), []string{"-db", dsn, "force", "1"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version := currentVersion(t, dsn); version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+
+	if err := app.Run(context.Background(), []string{"-db", dsn, "force", "one"}); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	seed := "-- +goose Up\nCREATE TABLE users (name TEXT);\n{{ if envIs \"dev\" }}INSERT INTO users VALUES ('test');{{ end }}\n-- +goose Down\nDROP TABLE users;\n"
+	if err := os.WriteFile(filepath.Join(dir, "0002_create_users.sql"), []byte(seed), 0o644); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for env, expected := range map[string]int{"dev": 1, "prod": 0} {
+		dsn := filepath.Join(t.TempDir(), "test.db")
+
+		var out bytes.Buffer
+		if err := cli.New(&migrations).SetOutput(&out).Run(context.Background(), []string{"-db", dsn, "-dir", dir, "-env", env, "up"}); err != nil {
+			t.Fatalf("%s: expected no error, got %v", env, err)
+		}
+
+		conn, err := sql.Open("sqlite3", dsn)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer conn.Close()
+
+		rows := 0
+		if err := conn.QueryRow("SELECT COUNT(*) FROM users;").Scan(&rows); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rows != expected {
+			t.Errorf("%s: expected %d seed rows, got %d", env, expected, rows)
+		}
+	}
+}
+
+func TestProtected(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	t.Setenv("LITEMIGRATE_PROTECTED", "true")
+
+	var out bytes.Buffer
+	app := cli.New(&migrations).SetOutput(&out)
+	if err := app.Run(context.Background(), []string{"-db", dsn, "up"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err := app.Run(context.Background(), []string{"-db", dsn, "down"})
+	if code := litemigrate.Code(err); code != litemigrate.CodeProtected {
+		t.Fatalf("expected error code %s, got %v", litemigrate.CodeProtected, err)
+	}
+	if version := currentVersion(t, dsn); version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+
+	if err := app.Run(context.Background(), []string{"-db", dsn, "down", "-override-protection"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version := currentVersion(t, dsn); version != 0 {
+		t.Errorf("expected version 0, got %d", version)
+	}
+}
+
+func TestDownDryRun(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	var out bytes.Buffer
+	app := cli.New(&migrations).SetOutput(&out)
+	if err := app.Run(context.Background(), []string{"-db", dsn, "up"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	out.Reset()
+	if err := app.Run(context.Background(), []string{"-db", dsn, "-plain", "down", "-dry-run"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if expected := "version 1: Create test table\n"; out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+	if version := currentVersion(t, dsn); version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+}
+
+func TestReset(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	var out bytes.Buffer
+	app := cli.New(&migrations).SetOutput(&out)
+	if err := app.Run(context.Background(), []string{"-db", dsn, "up"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := app.Run(context.Background(), []string{"-db", dsn, "reset"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version := currentVersion(t, dsn); version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+
+	t.Setenv("LITEMIGRATE_PROTECTED", "1")
+	if err := app.Run(context.Background(), []string{"-db", dsn, "reset"}); litemigrate.Code(err) != litemigrate.CodeProtected {
+		t.Errorf("expected error code %s, got %v", litemigrate.CodeProtected, err)
+	}
+}
@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFiles are the configuration files looked up in the working directory when -config isn't
+// set, in order.
+var configFiles = []string{"litemigrate.yaml", "litemigrate.yml", "litemigrate.toml"}
+
+// settings are the global flags that can be set in a configuration file.
+type settings struct {
+	DB        string `yaml:"db"`
+	Dir       string `yaml:"dir"`
+	Table     string `yaml:"table"`
+	HistoryDB string `yaml:"history_db"`
+	LockFile  string `yaml:"lock_file"`
+	Roles     string `yaml:"roles"`
+	Env       string `yaml:"env"`
+}
+
+// config is a configuration file. The settings of a profile override the top-level ones.
+//
+//	db: app.db
+//	dir: migrations
+//	profiles:
+//	  production:
+//	    db: /var/lib/app/app.db
+//	    roles: schema
+//	    env: prod
+type config struct {
+	settings `yaml:",inline"`
+	Profiles map[string]settings `yaml:"profiles"`
+}
+
+// findConfig returns the configuration file to read: path when set, otherwise the first of
+// configFiles that exists, or an empty path if there is none.
+func findConfig(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+
+	for _, name := range configFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// readConfig reads a YAML or TOML configuration file, depending on its extension, and returns
+// the settings of the profile, or the top-level settings when profile is empty. A relative
+// migrations directory is resolved against the directory of the file.
+func readConfig(path, profile string) (settings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return settings{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var c *config
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		c, err = parseTOML(data)
+	} else {
+		c, err = parseYAML(data)
+	}
+	if err != nil {
+		return settings{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	s := c.settings
+	if profile != "" {
+		p, ok := c.Profiles[profile]
+		if !ok {
+			return settings{}, fmt.Errorf("profile %s isn't defined in %s", profile, path)
+		}
+		s = s.merge(p)
+	}
+
+	if s.Dir != "" && !filepath.IsAbs(s.Dir) {
+		s.Dir = filepath.Join(filepath.Dir(path), s.Dir)
+	}
+	return s, nil
+}
+
+// configure fills in the global flags that weren't set on the command line or through environment
+// variables from the configuration file. Configured roles are a restriction: flags and environment
+// variables can narrow them, but not allow roles the configuration doesn't, and an empty -roles
+// keeps them.
+func (g *globals) configure(fs *flag.FlagSet, path, profile string) error {
+	path, err := findConfig(path)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		if profile != "" {
+			return fmt.Errorf("profile %s given without a configuration file", profile)
+		}
+		return nil
+	}
+
+	s, err := readConfig(path, profile)
+	if err != nil {
+		return err
+	}
+
+	if s.Roles != "" && g.roles != "" {
+		allowed := map[string]bool{}
+		for _, role := range splitList(s.Roles) {
+			allowed[role] = true
+		}
+		for _, role := range splitList(g.roles) {
+			if !allowed[role] {
+				return fmt.Errorf("role %s isn't allowed by %s", role, path)
+			}
+		}
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	// An empty -roles allows every role, so it can't override the configured roles.
+	if g.roles == "" {
+		delete(set, "roles")
+	}
+
+	for _, field := range []struct {
+		name       string
+		flag       *string
+		configured string
+	}{
+		{"db", &g.dsn, s.DB}, {"dir", &g.dir, s.Dir}, {"table", &g.table, s.Table}, {"history-db", &g.historyDB, s.HistoryDB}, {"lock-file", &g.lockFile, s.LockFile}, {"roles", &g.roles, s.Roles}, {"env", &g.env, s.Env},
+	} {
+		// Flags defaulting to environment variables keep those values; table defaults to a constant.
+		if set[field.name] || field.configured == "" || field.name != "table" && *field.flag != "" {
+			continue
+		}
+		*field.flag = field.configured
+	}
+	return nil
+}
+
+// merge returns the settings overridden by the non-empty settings of o.
+func (s settings) merge(o settings) settings {
+	for _, field := range []struct{ dst, src *string }{
+		{&s.DB, &o.DB}, {&s.Dir, &o.Dir}, {&s.Table, &o.Table}, {&s.HistoryDB, &o.HistoryDB}, {&s.LockFile, &o.LockFile}, {&s.Roles, &o.Roles}, {&s.Env, &o.Env},
+	} {
+		if *field.src != "" {
+			*field.dst = *field.src
+		}
+	}
+	return s
+}
+
+func parseYAML(data []byte) (*config, error) {
+	c := &config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseTOML parses the subset of TOML the configuration needs: string keys at the top level and
+// in [profiles.<name>] tables, and comments.
+//
+//	db = "app.db"
+//
+//	[profiles.production]
+//	db = "/var/lib/app/app.db"
+func parseTOML(data []byte) (*config, error) {
+	c := &config{Profiles: map[string]settings{}}
+	current := &c.settings
+	profile := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			name, ok := strings.CutPrefix(strings.TrimSuffix(text, "]"), "[profiles.")
+			if !ok || !strings.HasSuffix(text, "]") || name == "" {
+				return nil, fmt.Errorf("line %d: unsupported table %s", line, text)
+			}
+			if profile != "" {
+				c.Profiles[profile] = *current
+			}
+			profile = strings.Trim(name, `"`)
+			current = &settings{}
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+
+		value, err := tomlString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "db":
+			current.DB = value
+		case "dir":
+			current.Dir = value
+		case "table":
+			current.Table = value
+		case "history_db":
+			current.HistoryDB = value
+		case "lock_file":
+			current.LockFile = value
+		case "roles":
+			current.Roles = value
+		case "env":
+			current.Env = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %s", line, strings.TrimSpace(key))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		c.Profiles[profile] = *current
+	}
+	return c, nil
+}
+
+// tomlString parses a basic or literal TOML string, followed by an optional comment.
+func tomlString(value string) (string, error) {
+	if strings.HasPrefix(value, "'") {
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		return value[1 : end+1], checkComment(value[end+2:])
+	}
+
+	if !strings.HasPrefix(value, `"`) {
+		return "", fmt.Errorf("expected a string, got %s", value)
+	}
+
+	prefix, err := strconv.QuotedPrefix(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", value)
+	}
+
+	unquoted, err := strconv.Unquote(prefix)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", value)
+	}
+	return unquoted, checkComment(value[len(prefix):])
+}
+
+func checkComment(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected %s after string", rest)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func TestConfig(t *testing.T) {
+	files := map[string]string{
+		"litemigrate.yaml": "db: default.db\ntable: _schema\nprofiles:\n  production:\n    db: %s\n",
+		"litemigrate.toml": "db = \"default.db\"\ntable = '_schema' # shared\n\n[profiles.production]\ndb = \"%s\"\n",
+	}
+
+	for name, contents := range files {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			dsn := filepath.Join(dir, "production.db")
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(fmt.Sprintf(contents, dsn)), 0o644); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			var out bytes.Buffer
+			if err := cli.New(&migrations).SetOutput(&out).Run(context.Background(), []string{"-config", path, "-profile", "production", "up"}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if version := currentVersionIn(t, dsn, "_schema"); version != 1 {
+				t.Errorf("expected version 1, got %d", version)
+			}
+
+			other := filepath.Join(dir, "other.db")
+			if err := cli.New(&migrations).SetOutput(&out).Run(context.Background(), []string{"-config", path, "-db", other, "up"}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if version := currentVersionIn(t, other, "_schema"); version != 1 {
+				t.Errorf("expected -db to override the configuration, got version %d", version)
+			}
+
+			err := cli.New(&migrations).SetOutput(&out).Run(context.Background(), []string{"-config", path, "-profile", "staging", "up"})
+			if err == nil {
+				t.Error("expected an error for an undefined profile")
+			}
+		})
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	files := map[string]string{
+		"unknown key": "database: app.db\n",
+		"bad toml":    "db = app.db\n",
+		"bad table":   "[servers]\n",
+	}
+
+	for name, contents := range files {
+		ext := ".yaml"
+		if name != "unknown key" {
+			ext = ".toml"
+		}
+
+		path := filepath.Join(t.TempDir(), "litemigrate"+ext)
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var out bytes.Buffer
+		if err := cli.New(&migrations).SetOutput(&out).Run(context.Background(), []string{"-config", path, "status"}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func currentVersionIn(t *testing.T, dsn, table string) int64 {
+	t.Helper()
+
+	db, err := litemigrate.New(dsn, &migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	version, err := db.SetMigrationTable(table).CurrentVersion(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return version
+}
+
+func TestConfigRoles(t *testing.T) {
+	destructive := litemigrate.Migrations{migrations[0]}
+	destructive[0].Role = "destructive"
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "litemigrate.yaml")
+	dsn := filepath.Join(dir, "test.db")
+	contents := fmt.Sprintf("db: %s\nprofiles:\n  production:\n    roles: schema\n", dsn)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	app := cli.New(&destructive).SetOutput(&bytes.Buffer{})
+
+	err := app.Run(context.Background(), []string{"-config", path, "-profile", "production", "-roles", "schema,destructive", "up"})
+	if err == nil {
+		t.Error("expected an error for a role the profile doesn't allow, got nil")
+	}
+
+	t.Setenv("LITEMIGRATE_ROLES", "destructive")
+	err = app.Run(context.Background(), []string{"-config", path, "-profile", "production", "up"})
+	if err == nil {
+		t.Error("expected an error for a role the profile doesn't allow, got nil")
+	}
+
+	err = app.Run(context.Background(), []string{"-config", path, "-profile", "production", "-roles", "schema", "up"})
+	if err == nil {
+		t.Error("expected an error for a migration with a role that isn't allowed, got nil")
+	}
+
+	err = app.Run(context.Background(), []string{"-config", path, "-profile", "production", "-roles", "", "up"})
+	if err == nil {
+		t.Error("expected an empty -roles to keep the profile's roles, got nil")
+	}
+	if version := currentVersion(t, dsn); version != 0 {
+		t.Errorf("expected version 0, got %d", version)
+	}
+
+	if err := app.Run(context.Background(), []string{"-config", path, "up"}); err != nil {
+		t.Errorf("expected no error without the profile, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var goTemplate = template.Must(template.New("migration").Parse(`package {{ .Package }}
+
+import (
+	"database/sql"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func init() {
+	{{ .Var }} = append({{ .Var }}, litemigrate.Migration{
+		Version:     {{ .Version }},
+		Description: {{ printf "%q" .Description }},
+		Up: func(tx *sql.Tx) error {
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			return nil
+		},
+	})
+}
+`))
+
+var (
+	versionPrefix = regexp.MustCompile(`^(\d+)_`)
+	nonWord       = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+func (a *App) create(args []string, defaultDir string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	dir := fs.String("dir", defaultDir, "directory of the migrations")
+	sqlFiles := fs.Bool("sql", false, "create a pair of up and down SQL files instead of a Go file")
+	pkg := fs.String("package", "", "package name (defaults to the directory name)")
+	variable := fs.String("var", "Migrations", "package variable the migration is appended to")
+	timestamp := fs.Bool("timestamp", false, "use a timestamp version instead of the next number")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: create [-dir dir] [-sql] [-package name] [-var name] [-timestamp] <name>")
+	}
+
+	name := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(fs.Arg(0)), "_"), "_")
+	if name == "" {
+		return fmt.Errorf("invalid migration name: %q", fs.Arg(0))
+	}
+
+	version, err := a.nextVersion(*dir, *timestamp)
+	if err != nil {
+		return err
+	}
+
+	if *sqlFiles {
+		paths, err := a.createSQL(*dir, version, name)
+		if err != nil {
+			return err
+		}
+		return a.printCreated(paths)
+	}
+
+	if *pkg == "" {
+		abs, err := filepath.Abs(*dir)
+		if err != nil {
+			return err
+		}
+		*pkg = nonWord.ReplaceAllString(strings.ToLower(filepath.Base(abs)), "")
+	}
+
+	var buf bytes.Buffer
+	err = goTemplate.Execute(&buf, map[string]any{
+		"Package":     *pkg,
+		"Var":         *variable,
+		"Version":     version,
+		"Description": strings.ReplaceAll(name, "_", " "),
+	})
+	if err != nil {
+		return err
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(*dir, fmt.Sprintf("%04d_%s.go", version, name))
+	if err := writeNew(path, source); err != nil {
+		return err
+	}
+	return a.printCreated([]string{path})
+}
+
+// createSQL writes an empty pair of up and down SQL files and returns their paths.
+func (a *App) createSQL(dir string, version int64, name string) ([]string, error) {
+	header := fmt.Sprintf("-- %s\n", strings.ReplaceAll(name, "_", " "))
+	paths := make([]string, 0, 2)
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+		if err := writeNew(path, []byte(header)); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// printCreated prints the paths of the created files.
+func (a *App) printCreated(paths []string) error {
+	if a.json {
+		return a.printJSON(createOutput{Created: paths})
+	}
+
+	for _, path := range paths {
+		a.printf("created %s", path)
+		fmt.Fprintln(a.out)
+	}
+	return nil
+}
+
+// nextVersion returns the version following the highest version found in the file names of the
+// directory and in the application's migrations.
+func (a *App) nextVersion(dir string, timestamp bool) (int64, error) {
+	if timestamp {
+		return strconv.ParseInt(time.Now().UTC().Format("20060102150405"), 10, 64)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	latest := int64(0)
+	for _, entry := range entries {
+		match := versionPrefix.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %s: %w", entry.Name(), err)
+		}
+
+		if version > latest {
+			latest = version
+		}
+	}
+
+	if a.migrations != nil {
+		for _, migration := range *a.migrations {
+			if migration.Version > latest {
+				latest = migration.Version
+			}
+		}
+	}
+	return latest + 1, nil
+}
+
+// writeNew writes a file, failing if it already exists.
+func writeNew(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,73 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func TestCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err := os.WriteFile(filepath.Join(dir, "0007_create_users.go"), []byte("package migrations\n"), 0o644)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var out bytes.Buffer
+	app := cli.New(&litemigrate.Migrations{}).SetOutput(&out)
+
+	err = app.Run(context.Background(), []string{"create", "-dir", dir, "Add email to users"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "0008_add_email_to_users.go"))
+	if err != nil {
+		t.Fatalf("expected migration file, got %v", err)
+	}
+
+	for _, expected := range []string{
+		"package migrations",
+		"Migrations = append(Migrations, litemigrate.Migration{",
+		"Version:     8,",
+		`Description: "add email to users",`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected file to contain %q, got:\n%s", expected, data)
+		}
+	}
+}
+
+func TestCreateSQL(t *testing.T) {
+	dir := t.TempDir()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	var out bytes.Buffer
+	app := cli.New(&litemigrate.Migrations{}).SetOutput(&out)
+
+	err := app.Run(context.Background(), []string{"-dir", dir, "create", "-sql", "create_users"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, name := range []string{"0001_create_users.up.sql", "0001_create_users.down.sql"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to exist, got %v", name, err)
+		}
+	}
+
+	err = app.Run(context.Background(), []string{"-db", dsn, "-dir", dir, "up"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// Exit codes returned by Main, so scripts and CI can branch on the outcome of a command.
+const (
+	// ExitOK means the command succeeded. With -detailed-exit-codes, up and down only return it
+	// when there was nothing to do.
+	ExitOK = 0
+	// ExitError means the command failed for any reason without a more specific code.
+	ExitError = 1
+	// ExitApplied means up or down applied or rolled back migrations. It is only returned with
+	// -detailed-exit-codes, so scripts that treat any non-zero status as a failure keep working.
+	ExitApplied = 2
+	// ExitValidationFailed means the migration table is inconsistent with the migrations, or
+	// version found pending migrations.
+	ExitValidationFailed = 3
+	// ExitLocked means another migrator held the database's write lock or the lock file.
+	ExitLocked = 4
+)
+
+// validationCodes are the error codes that mean the migrations or the migration table failed
+// validation.
+var validationCodes = []litemigrate.ErrorCode{
+	litemigrate.CodeDuplicateVersion,
+	litemigrate.CodeInvalidMigration,
+	litemigrate.CodeUnknownMigration,
+	litemigrate.CodeBrokenHistory,
+	litemigrate.CodeChecksumMismatch,
+}
+
+// ExitCode returns the exit code for the outcome of the last Run, which returned err.
+func (a *App) ExitCode(err error) int {
+	if err == nil {
+		if a.detailedExitCodes && a.changed {
+			return ExitApplied
+		}
+		return ExitOK
+	}
+
+	if errors.Is(err, errInvalid) || errors.Is(err, errBehind) {
+		return ExitValidationFailed
+	}
+
+	code := litemigrate.Code(err)
+	if code == litemigrate.CodeLocked {
+		return ExitLocked
+	}
+	for _, c := range validationCodes {
+		if code == c {
+			return ExitValidationFailed
+		}
+	}
+	return ExitError
+}
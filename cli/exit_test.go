@@ -0,0 +1,95 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func TestExitCode(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	var out bytes.Buffer
+	app := cli.New(&migrations).SetOutput(&out)
+
+	run := func(args ...string) int {
+		return app.ExitCode(app.Run(ctx, args))
+	}
+
+	if code := run("-db", dsn, "version"); code != cli.ExitValidationFailed {
+		t.Errorf("expected %d while migrations are pending, got %d", cli.ExitValidationFailed, code)
+	}
+	if code := run("-db", dsn, "-detailed-exit-codes", "up"); code != cli.ExitApplied {
+		t.Errorf("expected %d after applying migrations, got %d", cli.ExitApplied, code)
+	}
+	if code := run("-db", dsn, "-detailed-exit-codes", "up"); code != cli.ExitOK {
+		t.Errorf("expected %d with nothing to do, got %d", cli.ExitOK, code)
+	}
+	if code := run("-db", dsn, "down"); code != cli.ExitOK {
+		t.Errorf("expected %d without -detailed-exit-codes, got %d", cli.ExitOK, code)
+	}
+	if code := run("-db", dsn, "unknown"); code != cli.ExitError {
+		t.Errorf("expected %d for an unknown command, got %d", cli.ExitError, code)
+	}
+
+	conn, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	lock, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer lock.Close()
+	if _, err := lock.ExecContext(ctx, "BEGIN IMMEDIATE;"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer lock.ExecContext(ctx, "ROLLBACK;")
+
+	if code := run("-db", dsn+"?_busy_timeout=50", "up"); code != cli.ExitLocked {
+		t.Errorf("expected %d while the database is locked, got %d", cli.ExitLocked, code)
+	}
+}
+
+func TestExitCodeLockFile(t *testing.T) {
+	dir := t.TempDir()
+	dsn := filepath.Join(dir, "test.db")
+	lockFile := filepath.Join(dir, "test.db.lock")
+
+	started, release := make(chan struct{}), make(chan struct{})
+	blocking := litemigrate.Migrations{migrations[0]}
+	blocking[0].Up = func(tx *sql.Tx) error {
+		close(started)
+		<-release
+		return migrations[0].Up(tx)
+	}
+
+	holder := cli.New(&blocking).SetOutput(&bytes.Buffer{})
+	done := make(chan error, 1)
+	go func() {
+		done <- holder.Run(context.Background(), []string{"-db", dsn, "-lock-file", lockFile, "up"})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	app := cli.New(&migrations).SetOutput(&bytes.Buffer{})
+	if code := app.ExitCode(app.Run(ctx, []string{"-db", dsn, "-lock-file", lockFile, "up"})); code != cli.ExitLocked {
+		t.Errorf("expected %d while the lock file is held, got %d", cli.ExitLocked, code)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
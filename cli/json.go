@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"encoding/json"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// errorOutput is printed with -json when a command fails.
+type errorOutput struct {
+	Error string                `json:"error"`
+	Code  litemigrate.ErrorCode `json:"code,omitempty"`
+}
+
+// runOutput is printed with -json by up and down.
+type runOutput struct {
+	Plan   *litemigrate.Plan   `json:"plan,omitempty"`
+	Result *litemigrate.Result `json:"result,omitempty"`
+}
+
+// schemaOutput is printed with -json by schema.
+type schemaOutput struct {
+	Schema string `json:"schema"`
+	Hash   string `json:"hash"`
+}
+
+// createOutput is printed with -json by create.
+type createOutput struct {
+	Created []string `json:"created"`
+}
+
+// forceOutput is printed with -json by force.
+type forceOutput struct {
+	Version int64 `json:"version"`
+}
+
+// printJSON prints a value as indented JSON.
+func (a *App) printJSON(v any) error {
+	encoder := json.NewEncoder(a.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,74 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func TestJSONOutput(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	run := func(args ...string) (map[string]any, error) {
+		var out bytes.Buffer
+		err := cli.New(&migrations).SetOutput(&out).Run(context.Background(), append([]string{"-db", dsn, "-json"}, args...))
+
+		output := map[string]any{}
+		if decodeErr := json.Unmarshal(out.Bytes(), &output); decodeErr != nil {
+			t.Fatalf("expected JSON output, got %q", out.String())
+		}
+		return output, err
+	}
+
+	output, err := run("status")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pending := output["pending"].([]any); len(pending) != 1 || output["version"].(float64) != 0 {
+		t.Errorf("expected 1 pending migration at version 0, got %v", output)
+	}
+
+	output, err = run("up", "-estimate")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := output["result"]; ok || output["plan"] == nil {
+		t.Errorf("expected only the plan without -yes, got %v", output)
+	}
+	if version := currentVersion(t, dsn); version != 0 {
+		t.Errorf("expected the estimate not to migrate, got version %d", version)
+	}
+
+	output, err = run("up")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	result := output["result"].(map[string]any)
+	if applied := result["applied"].([]any); len(applied) != 1 || result["version"].(float64) != 1 {
+		t.Errorf("expected version 1 to be applied, got %v", result)
+	}
+
+	output, err = run("status")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if applied := output["applied"].([]any); len(applied) != 1 || len(output["pending"].([]any)) != 0 {
+		t.Errorf("expected 1 applied migration, got %v", output)
+	}
+
+	if _, err := run("down", "-n", "1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output, err = run("down")
+	if err == nil {
+		t.Fatal("expected an error with nothing to roll back")
+	}
+	if output["code"] != string(litemigrate.CodeNothingToRollback) || output["error"] == "" {
+		t.Errorf("expected the error as JSON, got %v", output)
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import "fmt"
+
+// Translator returns the translation of an operator-facing message, such as a prompt or a status
+// label. Messages are identified by their English text, which may contain fmt verbs that the
+// translation has to keep in the same order. A translator returns the message unchanged when it
+// has no translation. Errors and flag descriptions are not translated.
+type Translator func(message string) string
+
+// Catalog is a Translator backed by a map from English messages to their translations.
+type Catalog map[string]string
+
+// Translate returns the translation of the message, or the message itself.
+func (c Catalog) Translate(message string) string {
+	if translation, ok := c[message]; ok {
+		return translation
+	}
+	return message
+}
+
+// SetTranslator sets the translator of the messages printed to the output.
+func (a *App) SetTranslator(translator Translator) *App {
+	a.translate = translator
+	return a
+}
+
+// tr returns the translation of a message.
+func (a *App) tr(message string) string {
+	if a.translate == nil {
+		return message
+	}
+	return a.translate(message)
+}
+
+// printf prints a translated message.
+func (a *App) printf(format string, args ...any) {
+	fmt.Fprintf(a.out, a.tr(format), args...)
+}
@@ -0,0 +1,41 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func TestTranslator(t *testing.T) {
+	catalog := cli.Catalog{
+		"created":                 "erstellt",
+		"apply these migrations?": "Diese Migrationen anwenden?",
+		"[y/N]":                   "[j/N]",
+		"y":                       "j",
+		"yes":                     "ja",
+	}
+
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	var out bytes.Buffer
+	app := cli.New(&migrations).SetInput(strings.NewReader("j\n")).SetOutput(&out).SetTranslator(catalog.Translate)
+
+	err := app.Run(context.Background(), []string{"-db", dsn, "up", "-estimate"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, expected := range []string{"erstellt: table test", "Diese Migrationen anwenden? [j/N]", "version 1: Create test table"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output, got %q", expected, out.String())
+		}
+	}
+
+	if version := currentVersion(t, dsn); version != 1 {
+		t.Errorf("expected version 1 after a translated yes, got %d", version)
+	}
+}
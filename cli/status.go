@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// statusOutput is printed with -json by status.
+type statusOutput struct {
+	*litemigrate.Status
+	Applied  []litemigrate.HistoryRecord `json:"applied"`
+	Excluded []litemigrate.HistoryRecord `json:"excluded"`
+}
+
+// status prints the current version, the applied migrations, the excluded ones and the migrations
+// that aren't applied yet, along with any inconsistency between the migration table and the
+// migrations.
+func (a *App) status(ctx context.Context, db *litemigrate.Database) error {
+	status, err := db.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	history, err := db.History(ctx)
+	if err != nil {
+		return err
+	}
+
+	output := statusOutput{Status: status, Applied: make([]litemigrate.HistoryRecord, 0), Excluded: make([]litemigrate.HistoryRecord, 0)}
+	for _, record := range history {
+		if record.Status == litemigrate.StatusExcluded {
+			output.Excluded = append(output.Excluded, record)
+		} else {
+			output.Applied = append(output.Applied, record)
+		}
+	}
+
+	if a.json {
+		return a.printJSON(output)
+	}
+
+	a.printf("current version: %d", status.Version)
+	fmt.Fprintln(a.out)
+	for _, record := range output.Applied {
+		a.printf("applied %d: %s", record.Version, record.Description)
+		fmt.Fprintln(a.out)
+	}
+	for _, record := range output.Excluded {
+		a.printf("excluded %d: %s", record.Version, record.Description)
+		fmt.Fprintln(a.out)
+	}
+	for _, migration := range status.Pending {
+		a.printf("pending %d: %s", migration.Version, migration.Description)
+		fmt.Fprintln(a.out)
+	}
+	for _, problem := range status.Problems {
+		fmt.Fprintln(a.out, problem)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+const (
+	green = "\033[32m"
+	red   = "\033[31m"
+	bold  = "\033[1m"
+	reset = "\033[0m"
+	// clearLine moves the cursor to the start of the line and clears it.
+	clearLine = "\r\033[K"
+)
+
+// spinnerFrames are drawn in turn while a migration runs.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// SetRichOutput overrides whether up and down print live progress with a spinner and a summary
+// table. By default, rich output is used when the output is a terminal and neither -json nor
+// -plain is set. Colors are disabled when $NO_COLOR is set.
+func (a *App) SetRichOutput(rich bool) *App {
+	a.rich = &rich
+	return a
+}
+
+// richOutput reports whether rich output is used for the command.
+func (a *App) richOutput() bool {
+	if a.json || a.plain {
+		return false
+	}
+	if a.rich != nil {
+		return *a.rich
+	}
+
+	f, ok := a.out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// summaryRow is a migration in the summary table printed after a run.
+type summaryRow struct {
+	version     int64
+	description string
+	duration    time.Duration
+	err         error
+}
+
+// terminal prints the progress of a run to a terminal, with a spinner and a counter for the
+// running migration and a summary table at the end.
+type terminal struct {
+	app   *App
+	color bool
+	total int
+
+	mu      sync.Mutex
+	line    string
+	frame   int
+	stop    chan struct{}
+	stopped chan struct{}
+	rows    []summaryRow
+}
+
+func newTerminal(app *App, total int) *terminal {
+	return &terminal{app: app, color: os.Getenv("NO_COLOR") == "", total: total}
+}
+
+// paint wraps s in the color code, unless colors are disabled.
+func (t *terminal) paint(code, s string) string {
+	if !t.color {
+		return s
+	}
+	return code + s + reset
+}
+
+// handle is the progress handler of the run.
+func (t *terminal) handle(event litemigrate.Event) {
+	switch event.Type {
+	case litemigrate.MigrationStarted:
+		verb := t.app.tr("applying")
+		if event.Direction == litemigrate.Down {
+			verb = t.app.tr("rolling back")
+		}
+		t.start(fmt.Sprintf("[%d/%d] %s %d: %s", len(t.rows)+1, t.total, verb, event.Version, event.Description))
+	case litemigrate.MigrationFinished:
+		t.finish(event)
+	}
+}
+
+// start draws the line of a running migration and animates its spinner until finish.
+func (t *terminal) start(line string) {
+	t.mu.Lock()
+	t.line = line
+	t.stop = make(chan struct{})
+	t.stopped = make(chan struct{})
+	t.draw()
+	t.mu.Unlock()
+
+	go func(stop, stopped chan struct{}) {
+		defer close(stopped)
+
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				t.mu.Lock()
+				t.frame++
+				t.draw()
+				t.mu.Unlock()
+			}
+		}
+	}(t.stop, t.stopped)
+}
+
+func (t *terminal) draw() {
+	fmt.Fprintf(t.app.out, "%s%s %s", clearLine, t.paint(bold, spinnerFrames[t.frame%len(spinnerFrames)]), t.line)
+}
+
+// finish stops the spinner and replaces the line of the migration with its outcome.
+func (t *terminal) finish(event litemigrate.Event) {
+	if t.stop != nil {
+		close(t.stop)
+		<-t.stopped
+		t.stop = nil
+	}
+
+	t.rows = append(t.rows, summaryRow{version: event.Version, description: event.Description, duration: event.Duration, err: event.Err})
+
+	mark := t.paint(green, "✓")
+	if event.Err != nil {
+		mark = t.paint(red, "✗")
+	}
+	fmt.Fprintf(t.app.out, "%s%s [%d/%d] %d: %s (%s)\n", clearLine, mark, len(t.rows), t.total, event.Version, event.Description, event.Duration.Round(time.Millisecond))
+}
+
+// summary prints the table of the migrations run and the outcome of the run.
+func (t *terminal) summary(direction litemigrate.Direction, duration time.Duration, err error) {
+	done := t.app.tr("applied")
+	if direction == litemigrate.Down {
+		done = t.app.tr("rolled back")
+	}
+
+	if len(t.rows) > 0 {
+		fmt.Fprintln(t.app.out)
+		w := tabwriter.NewWriter(t.app.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.app.tr("VERSION"), t.app.tr("DESCRIPTION"), t.app.tr("DURATION"), t.app.tr("STATUS"))
+		for _, row := range t.rows {
+			// The changes of every migration are undone when the run fails.
+			status := t.paint(green, done)
+			switch {
+			case row.err != nil:
+				status = t.paint(red, t.app.tr("failed"))
+			case err != nil:
+				status = t.paint(red, t.app.tr("undone"))
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", row.version, row.description, row.duration.Round(time.Millisecond), status)
+		}
+		w.Flush()
+		fmt.Fprintln(t.app.out)
+	}
+
+	switch {
+	case err != nil:
+		fmt.Fprintln(t.app.out, t.paint(red, t.app.tr("run failed, no changes were kept")))
+	case len(t.rows) == 0:
+		fmt.Fprintln(t.app.out, t.paint(green, "✓ ")+t.app.tr("nothing to do"))
+	default:
+		fmt.Fprint(t.app.out, t.paint(green, "✓ "))
+		t.app.printf("%s %d migrations in %s", done, len(t.rows), duration.Round(time.Millisecond))
+		fmt.Fprintln(t.app.out)
+	}
+}
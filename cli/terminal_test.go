@@ -0,0 +1,48 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func TestRichOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	var out bytes.Buffer
+	app := cli.New(&migrations).SetOutput(&out).SetRichOutput(true)
+	if err := app.Run(ctx, []string{"-db", dsn, "up"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, expected := range []string{"✓ [1/1] 1: Create test table", "VERSION  DESCRIPTION", "applied 1 migrations in"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output, got %q", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "\033[3") {
+		t.Errorf("expected no colors with NO_COLOR, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run(ctx, []string{"-db", dsn, "up"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "nothing to do") {
+		t.Errorf("expected nothing to do, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run(ctx, []string{"-db", dsn, "-plain", "down"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output with -plain, got %q", out.String())
+	}
+}
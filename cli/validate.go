@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// errInvalid is returned by validate when the database is inconsistent with the migrations.
+var errInvalid = errors.New("database is inconsistent with migrations")
+
+// validateOutput is printed with -json by validate.
+type validateOutput struct {
+	Valid    bool     `json:"valid"`
+	Problems []string `json:"problems"`
+	Pending  []int64  `json:"pending"`
+}
+
+// validate checks the migration table against the migrations: applied migrations that aren't
+// defined, gaps of migrations that were never applied, mismatched descriptions, migrations edited
+// since they were applied and, when the migration table records a hash chain, the hashes of the
+// records. The chain is verified with the key in $LITEMIGRATE_HASH_KEY, if set. It fails with
+// errInvalid on any problem.
+func (a *App) validate(ctx context.Context, db *litemigrate.Database) error {
+	status, err := db.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	output := validateOutput{Problems: make([]string, 0), Pending: make([]int64, 0)}
+	output.Problems = append(output.Problems, status.Problems...)
+	for _, migration := range status.Pending {
+		output.Pending = append(output.Pending, migration.Version)
+	}
+
+	history, err := db.History(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(history) > 0 && history[0].Hash != "" {
+		if key := os.Getenv("LITEMIGRATE_HASH_KEY"); key != "" {
+			db.SetHashChain([]byte(key))
+		}
+		if err := db.VerifyHistory(ctx); err != nil {
+			output.Problems = append(output.Problems, err.Error())
+		}
+	}
+	output.Valid = len(output.Problems) == 0
+
+	if a.json {
+		if err := a.printJSON(output); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range output.Problems {
+			fmt.Fprintln(a.out, problem)
+		}
+		if output.Valid {
+			fmt.Fprintln(a.out, a.tr("database is consistent with migrations"))
+		}
+	}
+
+	if !output.Valid {
+		return fmt.Errorf("%w: %d problems", errInvalid, len(output.Problems))
+	}
+	return nil
+}
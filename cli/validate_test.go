@@ -0,0 +1,88 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := litemigrate.New(dsn, &migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+	if _, err := db.SetHashChain(nil).MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := cli.New(&migrations).SetOutput(&out).Run(ctx, []string{"-db", dsn, "validate"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "database is consistent with migrations") {
+		t.Errorf("expected a consistent database, got %q", out.String())
+	}
+
+	conn, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec("UPDATE _migrations SET hash = 'tampered';"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	renumbered := litemigrate.Migrations{migrations[0]}
+	renumbered[0].Version = 2
+
+	out.Reset()
+	err = cli.New(&renumbered).SetOutput(&out).Run(ctx, []string{"-db", dsn, "-json", "validate"})
+	if err == nil {
+		t.Fatal("expected an error for an inconsistent database")
+	}
+
+	var report struct {
+		Valid    bool     `json:"valid"`
+		Problems []string `json:"problems"`
+		Pending  []int64  `json:"pending"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Valid || len(report.Problems) != 2 {
+		t.Errorf("expected an extra migration and a broken hash chain, got %+v", report)
+	}
+}
+
+func TestValidateChecksum(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	applied := litemigrate.Migrations{migrations[0]}
+	applied[0].Checksum = "a"
+	if err := cli.New(&applied).SetOutput(&bytes.Buffer{}).Run(ctx, []string{"-db", dsn, "up"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	edited := litemigrate.Migrations{migrations[0]}
+	edited[0].Checksum = "b"
+
+	var out bytes.Buffer
+	if err := cli.New(&edited).SetOutput(&out).Run(ctx, []string{"-db", dsn, "validate"}); err == nil {
+		t.Fatal("expected an error for an edited migration")
+	}
+	if !strings.Contains(out.String(), "modified migration: (version=1)") {
+		t.Errorf("expected the edited migration to be reported, got %q", out.String())
+	}
+}
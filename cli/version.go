@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// errBehind is returned by version when migrations are pending, so scripts can check whether the
+// database is up to date from the exit status.
+var errBehind = errors.New("database is behind")
+
+// versionOutput is printed with -json by version.
+type versionOutput struct {
+	Current int64 `json:"current"`
+	Latest  int64 `json:"latest"`
+	Dirty   bool  `json:"dirty"`
+	Behind  bool  `json:"behind"`
+}
+
+// version prints the current version, the latest version of the migrations and whether the
+// migration table is dirty or a migration failed, and fails with errBehind when migrations are pending.
+func (a *App) version(ctx context.Context, db *litemigrate.Database, migrations *litemigrate.Migrations) error {
+	status, err := db.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	output := versionOutput{Current: status.Version, Dirty: status.Dirty || status.Failed != nil, Behind: len(status.Pending) > 0}
+	for _, migration := range *migrations {
+		if migration.Version > output.Latest {
+			output.Latest = migration.Version
+		}
+	}
+
+	if a.json {
+		if err := a.printJSON(output); err != nil {
+			return err
+		}
+	} else {
+		a.printf("current version: %d", output.Current)
+		fmt.Fprintln(a.out)
+		a.printf("latest version: %d", output.Latest)
+		fmt.Fprintln(a.out)
+		if output.Dirty {
+			fmt.Fprintln(a.out, a.tr("dirty"))
+		}
+	}
+
+	if output.Behind {
+		return fmt.Errorf("%w: %d pending migrations", errBehind, len(status.Pending))
+	}
+	return nil
+}
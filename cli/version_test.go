@@ -0,0 +1,85 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func TestVersion(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	var out bytes.Buffer
+	err := cli.New(&migrations).SetOutput(&out).Run(ctx, []string{"-db", dsn, "version"})
+	if err == nil {
+		t.Error("expected an error while migrations are pending")
+	}
+	for _, expected := range []string{"current version: 0", "latest version: 1"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output, got %q", expected, out.String())
+		}
+	}
+
+	if err := cli.New(&migrations).SetOutput(&out).Run(ctx, []string{"-db", dsn, "up"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	out.Reset()
+	if err := cli.New(&migrations).SetOutput(&out).Run(ctx, []string{"-db", dsn, "-json", "version"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var version struct {
+		Current int64 `json:"current"`
+		Latest  int64 `json:"latest"`
+		Dirty   bool  `json:"dirty"`
+		Behind  bool  `json:"behind"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &version); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version.Current != 1 || version.Latest != 1 || version.Dirty || version.Behind {
+		t.Errorf("expected an up-to-date database, got %+v", version)
+	}
+}
+
+func TestVersionFailed(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	broken := litemigrate.Migrations{migrations[0], {
+		Version:     2,
+		Description: "Backfill test table",
+		Up: func(tx *sql.Tx) error {
+			return errors.New("backfill failed")
+		},
+	}}
+
+	if err := cli.New(&broken).SetOutput(&bytes.Buffer{}).Run(ctx, []string{"-db", dsn, "up", "-commit-each"}); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	var out bytes.Buffer
+	if err := cli.New(&migrations).SetOutput(&out).Run(ctx, []string{"-db", dsn, "-json", "version"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var version struct {
+		Dirty bool `json:"dirty"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &version); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !version.Dirty {
+		t.Error("expected a failed migration to make the database dirty")
+	}
+}
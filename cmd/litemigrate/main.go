@@ -0,0 +1,11 @@
+// Command litemigrate creates migration files and runs migrations from the command line.
+package main
+
+import (
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/cli"
+)
+
+func main() {
+	cli.Main(&litemigrate.Migrations{})
+}
@@ -0,0 +1,62 @@
+package litemigrate
+
+import "context"
+
+// SetConfirm sets a function consulted before MigrateUp applies destructive migrations and before
+// every MigrateDown, e.g. to prompt in a CLI or enforce a policy in a service. It receives the plan
+// of the run, and the run fails with CodeNotConfirmed unless it returns true. Dry runs are never
+// confirmed.
+//
+// The plan of MigrateUp is made with Plan, so every pending Up function runs twice: once in the
+// rolled back estimate and once for real. Up functions with side effects outside the database
+// should be idempotent.
+func (db *Database) SetConfirm(confirm func(ctx context.Context, plan *Plan) (bool, error)) *Database {
+	db.confirm = confirm
+	return db
+}
+
+// confirmUp consults the confirmation hook if the pending migrations are destructive.
+func (db *Database) confirmUp(ctx context.Context, opts []RunOption) error {
+	if db.confirm == nil {
+		return nil
+	}
+
+	plan, err := db.Plan(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	if !plan.Destructive() {
+		return nil
+	}
+	return db.confirmPlan(ctx, plan)
+}
+
+// confirmDown consults the confirmation hook with the migrations MigrateDown would roll back.
+func (db *Database) confirmDown(ctx context.Context, amount int) error {
+	if db.confirm == nil {
+		return nil
+	}
+
+	plan, err := db.PlanDown(ctx, amount)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Migrations) == 0 {
+		return nil
+	}
+	return db.confirmPlan(ctx, plan)
+}
+
+func (db *Database) confirmPlan(ctx context.Context, plan *Plan) error {
+	ok, err := db.confirm(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errorf(CodeNotConfirmed, "%s migration run was not confirmed", plan.Direction)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestConfirm(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	drop := litemigrate.Migration{
+		Version:     3,
+		Description: "Drop posts table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("DROP TABLE posts;")
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec("CREATE TABLE posts (id INTEGER PRIMARY KEY);")
+			return err
+		},
+	}
+
+	plans := make([]*litemigrate.Plan, 0)
+	confirmed := false
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users"), tableMigration(2, "posts"), drop}).
+		SetConfirm(func(ctx context.Context, plan *litemigrate.Plan) (bool, error) {
+			plans = append(plans, plan)
+			return confirmed, nil
+		})
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(2)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(plans) != 0 {
+		t.Fatalf("expected no confirmation for additive migrations, got %d", len(plans))
+	}
+
+	_, err = db.MigrateUp(ctx)
+	if litemigrate.Code(err) != litemigrate.CodeNotConfirmed {
+		t.Fatalf("expected the run to be declined, got %v", err)
+	}
+	if len(plans) != 1 || plans[0].Direction != litemigrate.Up || !plans[0].Destructive() {
+		t.Fatalf("expected a destructive up plan, got %+v", plans)
+	}
+
+	confirmed = true
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	confirmed = false
+	_, err = db.MigrateDown(ctx, 2)
+	if litemigrate.Code(err) != litemigrate.CodeNotConfirmed {
+		t.Fatalf("expected the rollback to be declined, got %v", err)
+	}
+
+	down := plans[len(plans)-1]
+	if down.Direction != litemigrate.Down || len(down.Migrations) != 2 || down.Migrations[0].Version != 3 || down.Migrations[1].Description != "Create posts table" {
+		t.Errorf("expected a down plan for versions 3 and 2, got %+v", down)
+	}
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithDryRun()); err != nil {
+		t.Fatalf("expected dry runs to skip confirmation, got %v", err)
+	}
+}
+
+func TestConfirmPanic(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	migration := litemigrate.Migration{
+		Version:     1,
+		Description: "Panic",
+		Up: func(tx *sql.Tx) error {
+			panic("boom")
+		},
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{migration}).
+		SetConfirm(func(ctx context.Context, plan *litemigrate.Plan) (bool, error) {
+			return true, nil
+		})
+
+	_, err = db.MigrateUp(ctx)
+	var panicErr *litemigrate.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("expected a panic error from the plan, got %v", err)
+	}
+	if litemigrate.Code(err) != litemigrate.CodeMigrationFailed {
+		t.Errorf("expected code %s, got %v", litemigrate.CodeMigrationFailed, err)
+	}
+}
@@ -0,0 +1,75 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+func (db *Database) copyCursorTable() string {
+	return db.migrationTable + "_copy"
+}
+
+// CopyTable copies the rows of a table into another table in batches, such as when a large table
+// is rebuilt with a new schema, and returns the number of rows copied. The columns both tables
+// have in common are copied by name, in the order of the source table's rowid, so the source must
+// be a rowid table.
+//
+// After every batch, the rowid of the last copied row is recorded as a cursor in a table named
+// after the migration table with a "_copy" suffix. A copy that was canceled or failed resumes from
+// its cursor, and running a finished copy again copies the rows inserted into the source since.
+func (db *Database) CopyTable(ctx context.Context, from, to string, opts BatchOptions) (int64, error) {
+	_, err := db.conn.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			source TEXT NOT NULL,
+			target TEXT NOT NULL,
+			cursor INTEGER NOT NULL,
+			PRIMARY KEY (source, target)
+		);
+	`, quoteIdent(db.copyCursorTable())))
+	if err != nil {
+		return 0, errorf(CodeMigrationTable, "failed to create copy cursor table: %w", err)
+	}
+
+	columns, err := commonColumns(ctx, db.conn, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return db.RunBatches(ctx, opts, func(ctx context.Context, tx *sql.Tx, limit int) (int64, error) {
+		return db.copyBatch(ctx, tx, from, to, columns, limit)
+	})
+}
+
+// copyBatch copies the next rows after the cursor and moves the cursor past them.
+func (db *Database) copyBatch(ctx context.Context, tx *sql.Tx, from, to, columns string, limit int) (int64, error) {
+	var cursor int64
+	err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT cursor FROM %s WHERE source = ? AND target = ?;", quoteIdent(db.copyCursorTable())), from, to).Scan(&cursor)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to read copy cursor: %w", err)
+	}
+
+	var (
+		count int64
+		last  sql.NullInt64
+	)
+	query := fmt.Sprintf("SELECT COUNT(*), MAX(rowid) FROM (SELECT rowid FROM %s WHERE rowid > ? ORDER BY rowid LIMIT ?);", quoteIdent(from))
+	if err := tx.QueryRowContext(ctx, query, cursor, limit).Scan(&count, &last); err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", from, err)
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	query = fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s WHERE rowid > ? AND rowid <= ? ORDER BY rowid;", quoteIdent(to), columns, columns, quoteIdent(from))
+	if _, err := tx.ExecContext(ctx, query, cursor, last.Int64); err != nil {
+		return 0, fmt.Errorf("failed to copy %s to %s: %w", from, to, err)
+	}
+
+	query = fmt.Sprintf("INSERT OR REPLACE INTO %s (source, target, cursor) VALUES (?, ?, ?);", quoteIdent(db.copyCursorTable()))
+	if _, err := tx.ExecContext(ctx, query, from, to, last.Int64); err != nil {
+		return 0, fmt.Errorf("failed to record copy cursor: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,78 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestCopyTable(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE events (id INTEGER PRIMARY KEY, payload TEXT, legacy TEXT);
+		CREATE TABLE events_new (id INTEGER PRIMARY KEY, payload TEXT NOT NULL, created_at TEXT);
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 10)
+		INSERT INTO events (id, payload) SELECT i, 'event ' || i FROM n;
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batches := 0
+	rows, err := db.CopyTable(ctx, "events", "events_new", litemigrate.BatchOptions{
+		Size: 3,
+		Progress: func(p litemigrate.BatchProgress) {
+			if batches++; batches == 2 {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+	if rows != 6 {
+		t.Errorf("expected 6 rows before the cancellation, got %d", rows)
+	}
+
+	rows, err = db.CopyTable(context.Background(), "events", "events_new", litemigrate.BatchOptions{Size: 3})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rows != 4 {
+		t.Errorf("expected the copy to resume with 4 rows, got %d", rows)
+	}
+
+	if _, err := conn.Exec("INSERT INTO events (id, payload) VALUES (11, 'late');"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rows, err = db.CopyTable(context.Background(), "events", "events_new", litemigrate.BatchOptions{Size: 3})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rows != 1 {
+		t.Errorf("expected only the new row to be copied, got %d", rows)
+	}
+
+	copied := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM events_new JOIN events USING (id) WHERE events_new.payload = events.payload;").Scan(&copied); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if copied != 11 {
+		t.Errorf("expected 11 copied rows, got %d", copied)
+	}
+}
@@ -0,0 +1,165 @@
+package litemigrate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// topological orders migrations sorted by version so every migration follows the migrations it
+// depends on, keeping version order wherever the dependencies allow it. Dependencies on versions
+// that aren't defined are ignored and migrations in a cycle keep their version order; both fail
+// validation.
+func topological(migrations []Migration) []Migration {
+	defined := map[int64]bool{}
+	hasDependencies := false
+	for _, migration := range migrations {
+		defined[migration.Version] = true
+		hasDependencies = hasDependencies || len(migration.DependsOn) > 0
+	}
+	if !hasDependencies {
+		return migrations
+	}
+
+	ordered := make([]Migration, 0, len(migrations))
+	done := map[int64]bool{}
+	for len(ordered) < len(migrations) {
+		next := -1
+		for i, migration := range migrations {
+			if !done[migration.Version] && dependenciesDone(migration, defined, done) {
+				next = i
+				break
+			}
+		}
+
+		// The remaining migrations are in a cycle.
+		if next < 0 {
+			for _, migration := range migrations {
+				if !done[migration.Version] {
+					done[migration.Version] = true
+					ordered = append(ordered, migration)
+				}
+			}
+			break
+		}
+
+		done[migrations[next].Version] = true
+		ordered = append(ordered, migrations[next])
+	}
+	return ordered
+}
+
+func dependenciesDone(migration Migration, defined, done map[int64]bool) bool {
+	for _, dependency := range migration.DependsOn {
+		if defined[dependency] && !done[dependency] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateDependencies checks that every dependency is defined and that the dependencies don't
+// form a cycle.
+func (ms *Migrations) validateDependencies() error {
+	migrations := ms.byVersion()
+	for _, migration := range *ms {
+		for _, dependency := range migration.DependsOn {
+			if _, ok := migrations[dependency]; !ok {
+				return errorf(CodeInvalidMigration, "invalid migration: (version=%v, description=%s) depends on version %v, which isn't defined", migration.Version, migration.Description, dependency)
+			}
+		}
+	}
+
+	// A migration is in a cycle if it can reach itself through its dependencies.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[int64]int{}
+	var path []int64
+	var visit func(version int64) error
+	visit = func(version int64) error {
+		switch state[version] {
+		case visiting:
+			cycle := make([]string, 0)
+			for i := len(path) - 1; i >= 0; i-- {
+				cycle = append(cycle, fmt.Sprint(path[i]))
+				if path[i] == version {
+					break
+				}
+			}
+			return errorf(CodeInvalidMigration, "invalid migration: dependency cycle between versions %s", strings.Join(cycle, ", "))
+		case visited:
+			return nil
+		}
+
+		state[version] = visiting
+		path = append(path, version)
+		for _, dependency := range migrations[version].DependsOn {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[version] = visited
+		return nil
+	}
+
+	versions := make([]int64, 0, len(migrations))
+	for version := range migrations {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	for _, version := range versions {
+		if err := visit(version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDependencies returns an error if a dependency of the migration is neither applied nor
+// applied earlier in the run, e.g. because the run's options exclude it.
+func checkDependencies(migration Migration, applied map[int64]bool) error {
+	for _, dependency := range migration.DependsOn {
+		if !applied[dependency] {
+			return errorf(CodeInvalidMigration, "migration (version=%v, description=%s) depends on version %v, which isn't applied", migration.Version, migration.Description, dependency)
+		}
+	}
+	return nil
+}
+
+// rollbackOrder returns the applied versions in the order they are rolled back: the reverse of
+// the order they are applied in. With dependencies, versions that aren't defined come first.
+func (ms *Migrations) rollbackOrder(index []int64) []int64 {
+	order := make([]int64, len(index))
+	copy(order, index)
+
+	position := map[int64]int{}
+	hasDependencies := false
+	for i, migration := range ms.sorted() {
+		position[migration.Version] = i
+		hasDependencies = hasDependencies || len(migration.DependsOn) > 0
+	}
+
+	// Without dependencies, migrations are rolled back newest version first.
+	if !hasDependencies {
+		sort.Slice(order, func(i, j int) bool { return order[i] > order[j] })
+		return order
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		pi, iDefined := position[order[i]]
+		pj, jDefined := position[order[j]]
+		switch {
+		case iDefined && jDefined:
+			return pi > pj
+		case iDefined != jDefined:
+			return !iDefined
+		default:
+			return order[i] > order[j]
+		}
+	})
+	return order
+}
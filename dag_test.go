@@ -0,0 +1,80 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestDependsOn(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	// Version 2 was written in a branch that also needs version 3 from another branch.
+	comments := litemigrate.Migration{
+		Version:     2,
+		Description: "Create comments table",
+		DependsOn:   []int64{3},
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("CREATE TABLE comments (id INTEGER PRIMARY KEY, post_id INTEGER REFERENCES posts (id));")
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec("DROP TABLE comments;")
+			return err
+		},
+	}
+	migrations := litemigrate.Migrations{tableMigration(1, "users"), comments, tableMigration(3, "posts"), tableMigration(4, "tags")}
+	db := litemigrate.NewWithConn(conn, &migrations)
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(2)); litemigrate.Code(err) != litemigrate.CodeInvalidMigration {
+		t.Errorf("expected an error for an excluded dependency, got %v", err)
+	}
+
+	result, err := db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(result.Applied, []int64{1, 3, 2, 4}) {
+		t.Errorf("expected versions 1, 3, 2, 4 to be applied in order, got %v", result.Applied)
+	}
+
+	result, err = db.MigrateDown(ctx, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(result.RolledBack, []int64{4, 2}) || result.Version != 3 {
+		t.Errorf("expected versions 4 and 2 to be rolled back to version 3, got %v and %d", result.RolledBack, result.Version)
+	}
+}
+
+func TestDependsOnInvalid(t *testing.T) {
+	tests := map[string]litemigrate.Migrations{
+		"undefined dependency": {tableMigration(1, "users")},
+		"cycle":                {tableMigration(1, "users"), tableMigration(2, "posts")},
+	}
+	tests["undefined dependency"][0].DependsOn = []int64{5}
+	tests["cycle"][0].DependsOn = []int64{2}
+	tests["cycle"][1].DependsOn = []int64{1}
+
+	for name, migrations := range tests {
+		db, err := litemigrate.New(testDBPath, &migrations)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer db.Close()
+
+		if _, err := db.MigrateUp(context.Background()); litemigrate.Code(err) != litemigrate.CodeInvalidMigration {
+			t.Errorf("%s: expected an invalid migration error, got %v", name, err)
+		}
+	}
+}
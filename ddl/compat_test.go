@@ -0,0 +1,92 @@
+package ddl
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestRebuild covers the emulation used on SQLite versions without DROP COLUMN and RENAME COLUMN.
+func TestRebuild(t *testing.T) {
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE memberships (user_id INTEGER NOT NULL, team_id INTEGER NOT NULL, role TEXT DEFAULT 'member', legacy TEXT, PRIMARY KEY (user_id, team_id));
+		CREATE INDEX memberships_role ON memberships (role);
+		INSERT INTO memberships VALUES (1, 2, 'owner', 'x');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := rebuild(tx, "memberships", "legacy", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := rebuild(tx, "memberships", "team_id", "group_id"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	columns, err := readColumns(tx, "memberships")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []column{
+		{name: "user_id", typ: "INTEGER", notNull: true, primaryKey: 1},
+		{name: "group_id", typ: "INTEGER", notNull: true, primaryKey: 2},
+		{name: "role", typ: "TEXT", dflt: sql.NullString{String: "'member'", Valid: true}},
+	}
+	if len(columns) != len(want) {
+		t.Fatalf("expected %d columns, got %v", len(want), columns)
+	}
+	for i := range want {
+		if columns[i] != want[i] {
+			t.Errorf("expected column %+v, got %+v", want[i], columns[i])
+		}
+	}
+
+	role := ""
+	if err := tx.QueryRow("SELECT role FROM memberships INDEXED BY memberships_role WHERE group_id = 2;").Scan(&role); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if role != "owner" {
+		t.Errorf("expected owner, got %s", role)
+	}
+
+	if err := rebuild(tx, "memberships", "missing", ""); err == nil {
+		t.Error("expected an error for a missing column")
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer tx.Rollback()
+
+	if ok, err := atLeast(tx, 3, 0, 0); err != nil || !ok {
+		t.Errorf("expected SQLite 3.0.0 or later, got %v, %v", ok, err)
+	}
+	if ok, err := atLeast(tx, 4, 0, 0); err != nil || ok {
+		t.Errorf("expected SQLite before 4.0.0, got %v, %v", ok, err)
+	}
+}
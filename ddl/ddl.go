@@ -0,0 +1,256 @@
+// Package ddl runs common schema changes from Up and Down functions, so they don't have to be
+// written by hand. The helpers check the current schema first where SQLite has no IF NOT EXISTS
+// form, and emulate statements the linked SQLite version doesn't support by rebuilding the table
+// with litemigrate.RebuildTable.
+//
+//	Up: func(tx *sql.Tx) error {
+//		if err := ddl.AddColumn(tx, "users", "email TEXT"); err != nil {
+//			return err
+//		}
+//		return ddl.CreateIndexIfNotExists(tx, "users_email", "users", "email")
+//	},
+package ddl
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// AddColumn adds a column to a table unless it already exists. definition is the column definition
+// as used by ALTER TABLE ... ADD COLUMN, starting with the column name, e.g. "logins INTEGER NOT
+// NULL DEFAULT 0".
+func AddColumn(tx *sql.Tx, table, definition string) error {
+	fields := strings.Fields(definition)
+	if len(fields) == 0 {
+		return fmt.Errorf("failed to add column to %s: empty definition", table)
+	}
+
+	exists, err := columnExists(tx, table, unquote(fields[0]))
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", quoteIdent(table), definition))
+	if err != nil {
+		return fmt.Errorf("failed to add column to %s: %w", table, err)
+	}
+	return nil
+}
+
+// DropColumnCompat drops a column from a table unless it doesn't exist. It uses ALTER TABLE ...
+// DROP COLUMN on SQLite 3.35.0 and later, and rebuilds the table on older versions. The rebuilt
+// table is created from its column list, so table constraints other than the primary key are lost;
+// foreign keys must be disabled for the rebuild, see litemigrate.RebuildTable.
+func DropColumnCompat(tx *sql.Tx, table, column string) error {
+	exists, err := columnExists(tx, table, column)
+	if err != nil || !exists {
+		return err
+	}
+
+	supported, err := atLeast(tx, 3, 35, 0)
+	if err != nil {
+		return err
+	}
+
+	if !supported {
+		return rebuild(tx, table, column, "")
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", quoteIdent(table), quoteIdent(column)))
+	if err != nil {
+		return fmt.Errorf("failed to drop %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// RenameColumn renames a column of a table. It uses ALTER TABLE ... RENAME COLUMN on SQLite 3.25.0
+// and later, and rebuilds the table on older versions, with the same limitations as
+// DropColumnCompat.
+func RenameColumn(tx *sql.Tx, table, from, to string) error {
+	supported, err := atLeast(tx, 3, 25, 0)
+	if err != nil {
+		return err
+	}
+
+	if !supported {
+		return rebuild(tx, table, from, to)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", quoteIdent(table), quoteIdent(from), quoteIdent(to)))
+	if err != nil {
+		return fmt.Errorf("failed to rename %s.%s: %w", table, from, err)
+	}
+	return nil
+}
+
+// CreateIndexIfNotExists creates an index on the given columns of a table unless an index with
+// the name already exists.
+func CreateIndexIfNotExists(tx *sql.Tx, name, table string, columns ...string) error {
+	return createIndex(tx, "INDEX", name, table, columns)
+}
+
+// CreateUniqueIndexIfNotExists creates a unique index on the given columns of a table unless an
+// index with the name already exists.
+func CreateUniqueIndexIfNotExists(tx *sql.Tx, name, table string, columns ...string) error {
+	return createIndex(tx, "UNIQUE INDEX", name, table, columns)
+}
+
+func createIndex(tx *sql.Tx, kind, name, table string, columns []string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("failed to create index %s: no columns", name)
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdent(column)
+	}
+
+	query := fmt.Sprintf("CREATE %s IF NOT EXISTS %s ON %s (%s);", kind, quoteIdent(name), quoteIdent(table), strings.Join(quoted, ", "))
+	if _, err := tx.Exec(query); err != nil {
+		return fmt.Errorf("failed to create index %s: %w", name, err)
+	}
+	return nil
+}
+
+// column describes a table column as reported by pragma_table_info.
+type column struct {
+	name       string
+	typ        string
+	notNull    bool
+	dflt       sql.NullString
+	primaryKey int
+}
+
+func readColumns(tx *sql.Tx, table string) ([]column, error) {
+	rows, err := tx.Query("SELECT name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?);", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make([]column, 0)
+	for rows.Next() {
+		var c column
+		if err := rows.Scan(&c.name, &c.typ, &c.notNull, &c.dflt, &c.primaryKey); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s doesn't exist", table)
+	}
+	return columns, nil
+}
+
+func columnExists(tx *sql.Tx, table, name string) (bool, error) {
+	columns, err := readColumns(tx, table)
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range columns {
+		if strings.EqualFold(c.name, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rebuild recreates a table from its column list without the column from, or with it renamed to
+// to when to isn't empty.
+func rebuild(tx *sql.Tx, table, from, to string) error {
+	columns, err := readColumns(tx, table)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	definitions := make([]string, 0, len(columns))
+	selected := make([]string, 0, len(columns))
+	keys := make(map[int]string)
+	for _, c := range columns {
+		name := c.name
+		if strings.EqualFold(c.name, from) {
+			found = true
+			if to == "" {
+				continue
+			}
+			name = to
+		}
+
+		definition := quoteIdent(name)
+		if c.typ != "" {
+			definition += " " + c.typ
+		}
+		if c.notNull {
+			definition += " NOT NULL"
+		}
+		if c.dflt.Valid {
+			definition += " DEFAULT " + c.dflt.String
+		}
+
+		definitions = append(definitions, definition)
+		selected = append(selected, quoteIdent(c.name))
+		if c.primaryKey > 0 {
+			keys[c.primaryKey] = quoteIdent(name)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("column %s.%s doesn't exist", table, from)
+	}
+
+	if len(keys) > 0 {
+		key := make([]string, 0, len(keys))
+		for i := 1; i <= len(keys); i++ {
+			key = append(key, keys[i])
+		}
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(key, ", ")))
+	}
+
+	ddl := fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(table), strings.Join(definitions, ", "))
+	return litemigrate.RebuildTable(tx, table, ddl, strings.Join(selected, ", "))
+}
+
+// atLeast reports whether the linked SQLite library is at least the given version.
+func atLeast(tx *sql.Tx, major, minor, patch int) (bool, error) {
+	var version string
+	if err := tx.QueryRow("SELECT sqlite_version();").Scan(&version); err != nil {
+		return false, err
+	}
+
+	want := []int{major, minor, patch}
+	for i, part := range strings.SplitN(version, ".", 3) {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse SQLite version %s: %w", version, err)
+		}
+		if n != want[i] {
+			return n > want[i], nil
+		}
+	}
+	return true, nil
+}
+
+func unquote(name string) string {
+	if len(name) >= 2 {
+		switch first, last := name[0], name[len(name)-1]; {
+		case first == '"' && last == '"', first == '`' && last == '`', first == '[' && last == ']':
+			return name[1 : len(name)-1]
+		}
+	}
+	return name
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
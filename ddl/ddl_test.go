@@ -0,0 +1,110 @@
+package ddl_test
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/joeychilson/litemigrate/ddl"
+)
+
+func begin(t *testing.T) *sql.Tx {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetMaxOpenConns(1)
+
+	if _, err := conn.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, legacy TEXT);"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+func columns(t *testing.T, tx *sql.Tx) []string {
+	t.Helper()
+
+	rows, err := tx.Query("SELECT name FROM pragma_table_info('users');")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestAddColumn(t *testing.T) {
+	tx := begin(t)
+
+	for i := 0; i < 2; i++ {
+		if err := ddl.AddColumn(tx, "users", `"email" TEXT NOT NULL DEFAULT ''`); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if got := columns(t, tx); len(got) != 4 || got[3] != "email" {
+		t.Errorf("expected email to be added once, got %v", got)
+	}
+}
+
+func TestDropColumnCompat(t *testing.T) {
+	tx := begin(t)
+
+	for i := 0; i < 2; i++ {
+		if err := ddl.DropColumnCompat(tx, "users", "legacy"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if got := columns(t, tx); len(got) != 2 {
+		t.Errorf("expected legacy to be dropped, got %v", got)
+	}
+}
+
+func TestRenameColumn(t *testing.T) {
+	tx := begin(t)
+
+	if err := ddl.RenameColumn(tx, "users", "name", "full_name"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := columns(t, tx); got[1] != "full_name" {
+		t.Errorf("expected name to be renamed, got %v", got)
+	}
+}
+
+func TestCreateIndexIfNotExists(t *testing.T) {
+	tx := begin(t)
+
+	for i := 0; i < 2; i++ {
+		if err := ddl.CreateUniqueIndexIfNotExists(tx, "users_name", "users", "name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO users (name) VALUES ('alice'), ('alice');"); err == nil {
+		t.Error("expected the unique index to reject duplicates")
+	}
+
+	if err := ddl.CreateIndexIfNotExists(tx, "users_empty", "users"); err == nil {
+		t.Error("expected an error for an index without columns")
+	}
+}
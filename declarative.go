@@ -0,0 +1,267 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PlanSchema compares the live schema with a desired schema, given as CREATE statements, and
+// returns SQL statements that would turn the former into the latter. It is experimental: the
+// statements are meant to be reviewed and copied into a migration, not run blindly. Tables whose
+// columns change in ways ALTER TABLE doesn't support are rebuilt by copying the common columns,
+// and destructive changes are marked with a comment.
+func (db *Database) PlanSchema(ctx context.Context, desired string) (string, error) {
+	target := openWithDriver(db.conn, ":memory:")
+	defer target.Close()
+	target.SetMaxOpenConns(1)
+
+	if _, err := target.ExecContext(ctx, desired); err != nil {
+		return "", errorf(CodeInvalidSchema, "failed to load desired schema: %w", err)
+	}
+
+	want, err := db.readSchema(ctx, target)
+	if err != nil {
+		return "", err
+	}
+
+	have, err := db.readSchema(ctx, db.conn)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		drops   []string
+		changes []string
+		creates []string
+		rebuilt = map[string]bool{}
+	)
+
+	for _, key := range sortedKeys(want) {
+		object := want[key]
+		if object.Type != "table" {
+			continue
+		}
+
+		current, exists := have[key]
+		switch {
+		case !exists:
+			changes = append(changes, object.SQL+";")
+		case normalizeSQL(current.SQL) != normalizeSQL(object.SQL):
+			statements, rebuild, err := alterTable(ctx, db.conn, target, object)
+			if err != nil {
+				return "", err
+			}
+
+			if rebuild {
+				if statements, err = rebuildTable(ctx, db.conn, target, object); err != nil {
+					return "", err
+				}
+				rebuilt[object.Name] = true
+			}
+			changes = append(changes, statements...)
+		}
+	}
+
+	for _, key := range sortedKeys(have) {
+		object := have[key]
+		wanted, kept := want[key]
+		changed := kept && normalizeSQL(wanted.SQL) != normalizeSQL(object.SQL)
+
+		switch object.Type {
+		case "table":
+			if !kept {
+				changes = append(changes, "-- destructive: table "+object.Name+" is dropped", dropStatement(object))
+			}
+		case "index":
+			// Indexes of dropped and rebuilt tables are removed along with the table.
+			_, tableKept := want["table "+object.Table]
+			if object.SQL != "" && tableKept && !rebuilt[object.Table] && (!kept || changed) {
+				drops = append(drops, dropStatement(object))
+			}
+		default:
+			// Views and triggers may depend on rebuilt tables, so they are all recreated.
+			if !kept || changed || len(rebuilt) > 0 {
+				drops = append(drops, dropStatement(object))
+			}
+		}
+	}
+
+	for _, kind := range []string{"index", "view", "trigger"} {
+		for _, key := range sortedKeys(want) {
+			object := want[key]
+			if object.Type != kind || object.SQL == "" {
+				continue
+			}
+
+			current, exists := have[key]
+			unchanged := exists && normalizeSQL(current.SQL) == normalizeSQL(object.SQL)
+			if kind == "index" && unchanged && !rebuilt[object.Table] || kind != "index" && unchanged && len(rebuilt) == 0 {
+				continue
+			}
+			creates = append(creates, object.SQL+";")
+		}
+	}
+
+	statements := append(append(drops, changes...), creates...)
+	if len(statements) == 0 {
+		return "", nil
+	}
+	return "-- Generated by litemigrate from the desired schema. Review before use.\n" + strings.Join(statements, "\n") + "\n", nil
+}
+
+// alterTable returns the ALTER TABLE statements adding the new columns of a table, or reports that
+// the table has to be rebuilt because columns were removed or changed, or can't be added.
+func alterTable(ctx context.Context, have, want queryer, table schemaObject) ([]string, bool, error) {
+	current, err := readTableColumns(ctx, have, table.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	desired, err := readTableColumns(ctx, want, table.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(desired) < len(current) {
+		return nil, true, nil
+	}
+
+	statements := make([]string, 0)
+	for i, column := range desired {
+		if i < len(current) {
+			if current[i] != column {
+				return nil, true, nil
+			}
+			continue
+		}
+
+		if column.PrimaryKey || column.NotNull && !column.Default.Valid {
+			return nil, true, nil
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", quoteIdent(table.Name), column.definition()))
+	}
+
+	// The definition changed without adding columns, e.g. a constraint was added.
+	if len(statements) == 0 {
+		return nil, true, nil
+	}
+	return statements, false, nil
+}
+
+// rebuildTable returns statements recreating a table with its desired definition and copying the
+// columns it shares with the current one, following SQLite's procedure for generalized ALTER TABLE
+// operations.
+func rebuildTable(ctx context.Context, have queryer, want *sql.DB, table schemaObject) ([]string, error) {
+	current, err := readTableColumns(ctx, have, table.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	desired, err := readTableColumns(ctx, want, table.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	temporary := table.Name + "_new"
+	create, err := renamedTableSQL(ctx, want, table.Name, temporary)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []string{
+		fmt.Sprintf("-- Rebuild %s because its columns changed.", table.Name),
+		create + ";",
+	}
+
+	common := make([]string, 0)
+	for _, column := range current {
+		kept := false
+		for _, wanted := range desired {
+			kept = kept || wanted.Name == column.Name
+		}
+
+		if kept {
+			common = append(common, quoteIdent(column.Name))
+		} else {
+			statements = append(statements, fmt.Sprintf("-- destructive: column %s.%s is dropped", table.Name, column.Name))
+		}
+	}
+
+	if len(common) > 0 {
+		columns := strings.Join(common, ", ")
+		statements = append(statements, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", quoteIdent(temporary), columns, columns, quoteIdent(table.Name)))
+	}
+
+	return append(statements,
+		fmt.Sprintf("DROP TABLE %s;", quoteIdent(table.Name)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", quoteIdent(temporary), quoteIdent(table.Name)),
+	), nil
+}
+
+// renamedTableSQL returns the CREATE TABLE statement of a table under a different name, letting
+// SQLite rewrite the statement inside a transaction that is rolled back.
+func renamedTableSQL(ctx context.Context, conn *sql.DB, table, name string) (string, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", quoteIdent(table), quoteIdent(name)))
+	if err != nil {
+		return "", err
+	}
+
+	create := ""
+	err = tx.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?;", name).Scan(&create)
+	return create, err
+}
+
+// openWithDriver opens another database with the driver of conn, so the core doesn't depend on
+// the name a driver is registered under.
+func openWithDriver(conn *sql.DB, dsn string) *sql.DB {
+	return sql.OpenDB(dsnConnector{driver: conn.Driver(), dsn: dsn})
+}
+
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func dropStatement(object schemaObject) string {
+	return fmt.Sprintf("DROP %s %s;", strings.ToUpper(object.Type), quoteIdent(object.Name))
+}
+
+// normalizeSQL removes the differences SQLite introduces when it rewrites statements, such as
+// identifier quoting and whitespace, so they don't count as changes. The object name is dropped,
+// since SQLite may quote it after a rename.
+func normalizeSQL(sql string) string {
+	if i := strings.IndexAny(sql, "("); i >= 0 && strings.HasPrefix(strings.ToUpper(sql), "CREATE TABLE") {
+		sql = sql[i:]
+	}
+
+	sql = strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "").Replace(sql)
+	sql = strings.Join(strings.Fields(sql), " ")
+	return strings.NewReplacer("( ", "(", " )", ")", " ,", ",", ", ", ",").Replace(sql)
+}
+
+func sortedKeys(objects map[string]schemaObject) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
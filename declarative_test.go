@@ -0,0 +1,70 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestPlanSchema(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, legacy TEXT);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT);
+		CREATE TABLE old (id INTEGER PRIMARY KEY);
+		INSERT INTO users (name, legacy) VALUES ('a', 'x');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	desired := `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, body TEXT DEFAULT '');
+		CREATE TABLE comments (id INTEGER PRIMARY KEY, post_id INTEGER REFERENCES posts (id));
+		CREATE INDEX users_name ON users (name);
+	`
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{})
+
+	plan, err := db.PlanSchema(context.Background(), desired)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, expected := range []string{
+		`ALTER TABLE "posts" ADD COLUMN "body" TEXT DEFAULT ''`,
+		"CREATE TABLE comments",
+		"-- destructive: column users.legacy is dropped",
+		`ALTER TABLE "users_new" RENAME TO "users";`,
+		"-- destructive: table old is dropped",
+		"CREATE INDEX users_name ON users (name);",
+	} {
+		if !strings.Contains(plan, expected) {
+			t.Errorf("expected plan to contain %q, got:\n%s", expected, plan)
+		}
+	}
+
+	_, err = conn.Exec(plan)
+	if err != nil {
+		t.Fatalf("expected plan to apply, got %v", err)
+	}
+
+	plan, err = db.PlanSchema(context.Background(), desired)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if plan != "" {
+		t.Errorf("expected empty plan after applying it, got:\n%s", plan)
+	}
+}
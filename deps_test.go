@@ -0,0 +1,52 @@
+package litemigrate_test
+
+import (
+	"go/build"
+	"strings"
+	"testing"
+)
+
+const module = "github.com/joeychilson/litemigrate"
+
+// TestNoDependencies keeps the core package and the SQL file loader free of third-party imports,
+// so embedding them only pulls in the standard library and the driver chosen by the application.
+// Network packages such as net/http are kept out of their import graphs as well.
+func TestNoDependencies(t *testing.T) {
+	for _, dir := range []string{".", "sqlfile"} {
+		pkg, err := build.ImportDir(dir, 0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		checkImports(t, pkg)
+	}
+}
+
+func checkImports(t *testing.T, pkg *build.Package) {
+	seen := map[string]bool{}
+	var walk func(imports []string, from string)
+	walk = func(imports []string, from string) {
+		for _, path := range imports {
+			// The core package is checked on its own.
+			if seen[path] || path == "C" || path == "unsafe" || path == module {
+				continue
+			}
+			seen[path] = true
+
+			if strings.Contains(strings.Split(path, "/")[0], ".") {
+				t.Errorf("expected only standard library imports, got %s imported by %s", path, from)
+				continue
+			}
+			if path == "net" || strings.HasPrefix(path, "net/http") || strings.HasPrefix(path, "crypto/tls") {
+				t.Errorf("expected no network packages, got %s imported by %s", path, from)
+				continue
+			}
+
+			dep, err := build.Import(path, "", 0)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			walk(dep.Imports, path)
+		}
+	}
+	walk(pkg.Imports, pkg.ImportPath)
+}
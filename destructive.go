@@ -0,0 +1,26 @@
+package litemigrate
+
+import "strings"
+
+// SetAllowDestructive allows MigrateUp to apply migrations with DestructiveStatements. By default,
+// a run that would apply one fails before making changes.
+func (db *Database) SetAllowDestructive(allow bool) *Database {
+	db.allowDestructive = allow
+	return db
+}
+
+// WithAllowDestructive overrides SetAllowDestructive for a run.
+func WithAllowDestructive(allow bool) RunOption {
+	return func(c *runConfig) {
+		c.allowDestructive = allow
+	}
+}
+
+// checkDestructive returns an error if the migration runs destructive statements that aren't
+// allowed.
+func (c *runConfig) checkDestructive(migration Migration) error {
+	if len(migration.DestructiveStatements) == 0 || c.allowDestructive {
+		return nil
+	}
+	return errorf(CodeDestructiveNotAllowed, "migration (version=%v, description=%s) runs destructive statements: %s", migration.Version, migration.Description, strings.Join(migration.DestructiveStatements, "; "))
+}
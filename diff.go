@@ -0,0 +1,160 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ColumnChange describes a column whose definition differs between two schemas.
+type ColumnChange struct {
+	Table  string
+	Column string
+	From   string
+	To     string
+}
+
+// SchemaDiff lists the differences between two schemas. Tables and indexes are given by name and
+// columns as "table.column". Added objects only exist in the second schema, removed objects only
+// in the first.
+type SchemaDiff struct {
+	AddedTables    []string
+	RemovedTables  []string
+	AddedColumns   []string
+	RemovedColumns []string
+	ChangedColumns []ColumnChange
+	AddedIndexes   []string
+	RemovedIndexes []string
+	ChangedIndexes []string
+}
+
+// Empty reports whether the schemas have the same tables, columns and indexes.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 &&
+		len(d.AddedColumns) == 0 && len(d.RemovedColumns) == 0 && len(d.ChangedColumns) == 0 &&
+		len(d.AddedIndexes) == 0 && len(d.RemovedIndexes) == 0 && len(d.ChangedIndexes) == 0
+}
+
+// String returns the differences as a human-readable report.
+func (d *SchemaDiff) String() string {
+	var b strings.Builder
+	list := func(label string, items []string) {
+		for _, item := range items {
+			fmt.Fprintf(&b, "%s: %s\n", label, item)
+		}
+	}
+
+	list("added table", d.AddedTables)
+	list("removed table", d.RemovedTables)
+	list("added column", d.AddedColumns)
+	list("removed column", d.RemovedColumns)
+	for _, change := range d.ChangedColumns {
+		fmt.Fprintf(&b, "changed column: %s.%s (%s -> %s)\n", change.Table, change.Column, change.From, change.To)
+	}
+	list("added index", d.AddedIndexes)
+	list("removed index", d.RemovedIndexes)
+	list("changed index", d.ChangedIndexes)
+	return b.String()
+}
+
+// DiffSchema compares the tables, columns and indexes of the database with those of another
+// connection, e.g. a migrated staging copy and production. The migration tables are ignored.
+func (db *Database) DiffSchema(ctx context.Context, other *sql.DB) (*SchemaDiff, error) {
+	from, err := db.readSchema(ctx, db.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := db.readSchema(ctx, other)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SchemaDiff{}
+	for _, key := range sortedKeys(to) {
+		object := to[key]
+		previous, exists := from[key]
+
+		switch object.Type {
+		case "table":
+			if !exists {
+				diff.AddedTables = append(diff.AddedTables, object.Name)
+				continue
+			}
+			if err := diffColumns(ctx, diff, db.conn, other, object.Name); err != nil {
+				return nil, err
+			}
+		case "index":
+			if !exists {
+				diff.AddedIndexes = append(diff.AddedIndexes, object.Name)
+			} else if normalizeSQL(previous.SQL) != normalizeSQL(object.SQL) {
+				diff.ChangedIndexes = append(diff.ChangedIndexes, object.Name)
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(from) {
+		object := from[key]
+		if _, exists := to[key]; exists {
+			continue
+		}
+
+		switch object.Type {
+		case "table":
+			diff.RemovedTables = append(diff.RemovedTables, object.Name)
+		case "index":
+			diff.RemovedIndexes = append(diff.RemovedIndexes, object.Name)
+		}
+	}
+	return diff, nil
+}
+
+// diffColumns adds the column differences of a table that exists in both schemas.
+func diffColumns(ctx context.Context, diff *SchemaDiff, from, to queryer, table string) error {
+	before, err := readTableColumns(ctx, from, table)
+	if err != nil {
+		return err
+	}
+
+	after, err := readTableColumns(ctx, to, table)
+	if err != nil {
+		return err
+	}
+
+	describe := func(c Column) string {
+		if c.PrimaryKey {
+			return c.definition() + " PRIMARY KEY"
+		}
+		return c.definition()
+	}
+
+	columns := map[string]Column{}
+	for _, c := range before {
+		columns[c.Name] = c
+	}
+
+	added := make([]string, 0)
+	for _, c := range after {
+		previous, exists := columns[c.Name]
+		delete(columns, c.Name)
+
+		switch {
+		case !exists:
+			added = append(added, table+"."+c.Name)
+		case describe(previous) != describe(c):
+			diff.ChangedColumns = append(diff.ChangedColumns, ColumnChange{Table: table, Column: c.Name, From: describe(previous), To: describe(c)})
+		}
+	}
+
+	removed := make([]string, 0, len(columns))
+	for name := range columns {
+		removed = append(removed, table+"."+name)
+	}
+	sort.Strings(removed)
+
+	diff.AddedColumns = append(diff.AddedColumns, added...)
+	diff.RemovedColumns = append(diff.RemovedColumns, removed...)
+	return nil
+}
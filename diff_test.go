@@ -0,0 +1,67 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestDiffSchema(t *testing.T) {
+	open := func(schema string) *sql.DB {
+		conn, err := sql.Open("sqlite3", testDBPath)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		conn.SetMaxOpenConns(1)
+
+		if _, err := conn.Exec(schema); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return conn
+	}
+
+	production := open(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER);
+		CREATE INDEX users_name ON users (name);
+		CREATE TABLE sessions (id INTEGER PRIMARY KEY);
+	`)
+	defer production.Close()
+
+	staging := open(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, email TEXT);
+		CREATE INDEX users_name ON users (name, email);
+		CREATE INDEX users_email ON users (email);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY);
+	`)
+	defer staging.Close()
+
+	db := litemigrate.NewWithConn(production, &litemigrate.Migrations{})
+
+	diff, err := db.DiffSchema(context.Background(), staging)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `added table: posts
+removed table: sessions
+added column: users.email
+removed column: users.age
+changed column: users.name ("name" TEXT -> "name" TEXT NOT NULL)
+added index: users_email
+changed index: users_name
+`
+	if diff.String() != expected {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", expected, diff.String())
+	}
+
+	same, err := db.DiffSchema(context.Background(), production)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !same.Empty() {
+		t.Errorf("expected no differences with itself, got:\n%s", same)
+	}
+}
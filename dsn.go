@@ -0,0 +1,60 @@
+package litemigrate
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DSN builds a data source name for the sqlite3 driver (github.com/mattn/go-sqlite3) from common
+// settings, instead of hand-written query strings.
+type DSN struct {
+	Path string
+	// JournalMode is the journal mode, such as "WAL". It is left unchanged when empty.
+	JournalMode string
+	// BusyTimeout is how long a connection waits for a lock held by another connection.
+	BusyTimeout time.Duration
+	ForeignKeys bool
+	SharedCache bool
+	// Params are additional driver parameters.
+	Params url.Values
+}
+
+// MigrationDSN returns a DSN for the database file at path with defaults suited for migrations:
+// WAL mode, a five second busy timeout and foreign keys enabled.
+func MigrationDSN(path string) DSN {
+	return DSN{
+		Path:        path,
+		JournalMode: "WAL",
+		BusyTimeout: 5 * time.Second,
+		ForeignKeys: true,
+	}
+}
+
+// String returns the DSN as a file: URI, e.g.
+// "file:app.db?_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL".
+func (d DSN) String() string {
+	params := url.Values{}
+	for key, values := range d.Params {
+		params[key] = append([]string(nil), values...)
+	}
+
+	if d.JournalMode != "" {
+		params.Set("_journal_mode", d.JournalMode)
+	}
+	if d.BusyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(d.BusyTimeout.Milliseconds(), 10))
+	}
+	if d.ForeignKeys {
+		params.Set("_foreign_keys", "on")
+	}
+	if d.SharedCache {
+		params.Set("cache", "shared")
+	}
+
+	dsn := "file:" + d.Path
+	if len(params) > 0 {
+		dsn += "?" + params.Encode()
+	}
+	return dsn
+}
@@ -0,0 +1,42 @@
+package litemigrate_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestDSN(t *testing.T) {
+	dsn := litemigrate.DSN{Path: "app.db", BusyTimeout: time.Second, SharedCache: true}
+	if dsn.String() != "file:app.db?_busy_timeout=1000&cache=shared" {
+		t.Errorf("unexpected dsn %s", dsn)
+	}
+
+	conn, err := sql.Open("sqlite3", litemigrate.MigrationDSN(filepath.Join(t.TempDir(), "app.db")).String())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	var (
+		journalMode string
+		busyTimeout int
+		foreignKeys bool
+	)
+	if err := conn.QueryRow("PRAGMA journal_mode;").Scan(&journalMode); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := conn.QueryRow("PRAGMA busy_timeout;").Scan(&busyTimeout); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := conn.QueryRow("PRAGMA foreign_keys;").Scan(&foreignKeys); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if journalMode != "wal" || busyTimeout != 5000 || !foreignKeys {
+		t.Errorf("expected wal, 5000 and foreign keys, got %s, %d and %v", journalMode, busyTimeout, foreignKeys)
+	}
+}
@@ -0,0 +1,53 @@
+package litemigrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// DumpSchema writes the CREATE statements of the schema to w, excluding SQLite's internal objects
+// and the migration tables. Tables come first, followed by indexes, views and triggers, each
+// sorted by name, so databases with the same schema produce identical dumps.
+func (db *Database) DumpSchema(ctx context.Context, w io.Writer) error {
+	schema, err := db.readSchema(ctx, db.conn)
+	if err != nil {
+		return err
+	}
+
+	order := map[string]int{"table": 0, "index": 1, "view": 2, "trigger": 3}
+	objects := make([]schemaObject, 0, len(schema))
+	for _, object := range schema {
+		if object.SQL != "" {
+			objects = append(objects, object)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if order[objects[i].Type] != order[objects[j].Type] {
+			return order[objects[i].Type] < order[objects[j].Type]
+		}
+		return objects[i].Name < objects[j].Name
+	})
+
+	for _, object := range objects {
+		if _, err := fmt.Fprintf(w, "%s;\n", object.SQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SchemaHash returns the hex-encoded SHA-256 hash of the schema as written by DumpSchema, so
+// environments can be compared and out-of-band changes detected without transferring dumps. The
+// hash covers the statements as SQLite stores them, so equivalent statements that are formatted
+// differently hash differently.
+func (db *Database) SchemaHash(ctx context.Context) (string, error) {
+	h := sha256.New()
+	if err := db.DumpSchema(ctx, h); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,108 @@
+package litemigrate_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestDumpSchema(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Create users table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE VIEW user_names AS SELECT name FROM users;
+					CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+					CREATE INDEX users_name ON users (name);
+					CREATE TABLE accounts (id INTEGER PRIMARY KEY);
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP VIEW user_names; DROP TABLE users; DROP TABLE accounts;`)
+				return err
+			},
+		},
+	})
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var dump bytes.Buffer
+	if err := db.DumpSchema(context.Background(), &dump); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `CREATE TABLE accounts (id INTEGER PRIMARY KEY);
+CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+CREATE INDEX users_name ON users (name);
+CREATE VIEW user_names AS SELECT name FROM users;
+`
+	if dump.String() != expected {
+		t.Errorf("expected dump:\n%s\ngot:\n%s", expected, dump.String())
+	}
+}
+
+func TestSchemaHash(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one")})
+	empty, err := db.SchemaHash(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	migrated, err := db.SchemaHash(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(migrated) != 64 || migrated == empty {
+		t.Fatalf("expected the hash to change after migrating, got %s and %s", empty, migrated)
+	}
+
+	again, err := db.SchemaHash(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if again != migrated {
+		t.Errorf("expected the hash to be deterministic, got %s and %s", migrated, again)
+	}
+
+	if _, err := conn.Exec("CREATE INDEX one_id ON one (id);"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	drifted, err := db.SchemaHash(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if drifted == migrated {
+		t.Error("expected the hash to change after an out-of-band change")
+	}
+}
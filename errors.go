@@ -0,0 +1,81 @@
+package litemigrate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a stable identifier of a class of errors, so automation can react to specific
+// failures across versions of the library. Codes are never reused or renumbered.
+type ErrorCode string
+
+const (
+	CodeDuplicateVersion      ErrorCode = "LM001" // two migrations share a version
+	CodeInvalidMigration      ErrorCode = "LM002" // a migration misses fields or has an invalid version
+	CodeUnknownMigration      ErrorCode = "LM003" // an applied migration isn't defined in code
+	CodeRoleNotAllowed        ErrorCode = "LM004" // a migration requires a role that isn't allowed
+	CodeNothingToRollback     ErrorCode = "LM005" // no migration is applied
+	CodeMigrationFailed       ErrorCode = "LM006" // an Up, Down or Applied function failed
+	CodeMigrationTable        ErrorCode = "LM007" // the migration table can't be created or updated
+	CodeBrokenHistory         ErrorCode = "LM008" // a history record doesn't link to the previous one
+	CodeDirtyHistory          ErrorCode = "LM009" // an imported history is marked as dirty
+	CodeInvalidRepeatable     ErrorCode = "LM010" // a repeatable migration is invalid or duplicated
+	CodeShadowFailed          ErrorCode = "LM011" // the shadow database replay failed
+	CodeBackupFailed          ErrorCode = "LM012" // the database can't be backed up
+	CodeRestoreFailed         ErrorCode = "LM013" // the backup can't be restored after a failed run
+	CodeChecksumMismatch      ErrorCode = "LM014" // a history record's hash doesn't match its contents
+	CodeInvalidDSN            ErrorCode = "LM015" // a DSN or one of its secrets can't be resolved
+	CodeUnknownHistoryFormat  ErrorCode = "LM016" // ImportHistory was given an unknown format
+	CodeForeignKeyViolation   ErrorCode = "LM017" // the foreign key check found violations
+	CodeIntegrityCheckFailed  ErrorCode = "LM018" // the integrity check found problems
+	CodeNotConfirmed          ErrorCode = "LM019" // the confirmation hook declined a run
+	CodeDestructiveNotAllowed ErrorCode = "LM020" // a migration runs destructive statements that aren't allowed
+	CodeAwaitFailed           ErrorCode = "LM021" // the lock held by another migrator wasn't released in time, or migrations are still pending
+	CodeLocked                ErrorCode = "LM022" // another migrator holds the database's write lock or the lock file
+	CodePreconditionFailed    ErrorCode = "LM023" // a migration's precondition isn't met
+	CodeVerifyFailed          ErrorCode = "LM024" // a migration's Verify hook failed after it ran
+	CodeIrreversible          ErrorCode = "LM025" // a rollback would reach a migration without Down
+	CodeProtected             ErrorCode = "LM026" // a rollback was attempted on a protected database
+	CodeMaxDuration           ErrorCode = "LM027" // a run exceeded its maximum duration
+	CodeInvalidOption         ErrorCode = "LM028" // a setting or run option is invalid or not supported
+	CodeSnapshotFailed        ErrorCode = "LM029" // the snapshot of VerifyOnSnapshot can't be taken or migrated
+	CodeInvalidSchema         ErrorCode = "LM030" // the desired schema given to PlanSchema can't be loaded
+)
+
+// Error is an error with a stable code. Its message doesn't include the code.
+type Error struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the code of the outermost Error in err's chain, or an empty code if there is none.
+func Code(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// ErrIrreversible is returned by MigrateDown when a rollback would reach a migration without a
+// Down function. Nothing is rolled back in that case.
+type ErrIrreversible struct {
+	Version int64
+}
+
+func (e ErrIrreversible) Error() string {
+	return fmt.Sprintf("migration (version=%v) is irreversible", e.Version)
+}
+
+// errorf returns an Error with the code and a message formatted like fmt.Errorf.
+func errorf(code ErrorCode, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
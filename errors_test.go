@@ -0,0 +1,79 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestErrorCodes(t *testing.T) {
+	ctx := context.Background()
+
+	migrations := &litemigrate.Migrations{
+		tableMigration(1, "users"),
+		tableMigration(1, "posts"),
+	}
+
+	db, err := litemigrate.New(testDBPath, migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.MigrateUp(ctx)
+	if code := litemigrate.Code(err); code != litemigrate.CodeDuplicateVersion {
+		t.Fatalf("expected code %s, got %q (%v)", litemigrate.CodeDuplicateVersion, code, err)
+	}
+
+	failure := errors.New("boom")
+	*migrations = litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "failing",
+			Up:          func(tx *sql.Tx) error { return failure },
+			Down:        func(tx *sql.Tx) error { return nil },
+		},
+	}
+
+	_, err = db.MigrateUp(ctx)
+	if code := litemigrate.Code(fmt.Errorf("wrapped: %w", err)); code != litemigrate.CodeMigrationFailed {
+		t.Fatalf("expected code %s, got %q (%v)", litemigrate.CodeMigrationFailed, code, err)
+	}
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the error to wrap the migration error, got %v", err)
+	}
+
+	if code := litemigrate.Code(failure); code != "" {
+		t.Fatalf("expected no code, got %q", code)
+	}
+}
+
+func TestErrorCodesOptions(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := litemigrate.New(testDBPath, &litemigrate.Migrations{tableMigration(1, "users")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Reset(ctx, litemigrate.WithDryRun())
+	if code := litemigrate.Code(err); code != litemigrate.CodeInvalidOption {
+		t.Errorf("expected code %s, got %q (%v)", litemigrate.CodeInvalidOption, code, err)
+	}
+
+	_, err = db.PlanSchema(ctx, "CREATE TABLE")
+	if code := litemigrate.Code(err); code != litemigrate.CodeInvalidSchema {
+		t.Errorf("expected code %s, got %q (%v)", litemigrate.CodeInvalidSchema, code, err)
+	}
+
+	_, err = db.Attach("main", filepath.Join(t.TempDir(), "main.db")).MigrateUp(ctx)
+	if code := litemigrate.Code(err); code != litemigrate.CodeInvalidOption {
+		t.Errorf("expected code %s, got %q (%v)", litemigrate.CodeInvalidOption, code, err)
+	}
+}
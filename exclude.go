@@ -0,0 +1,132 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SetExcludedVersions makes MigrateUp bypass the migrations with the given versions, e.g. a known
+// bad migration while a fix is prepared. Runs record the excluded migrations in the migration table
+// as intentionally skipped, see ExcludedMigrations, and migrations depending on them fail to
+// apply. Once a version is no longer excluded, it is applied like any other pending migration.
+func (db *Database) SetExcludedVersions(versions ...int64) *Database {
+	db.excludedVersions = versions
+	return db
+}
+
+// WithoutVersions overrides SetExcludedVersions for a run.
+func WithoutVersions(versions ...int64) RunOption {
+	return func(c *runConfig) {
+		c.excludedVersions = versions
+	}
+}
+
+// ExcludedMigration is a pending migration that a run intentionally skipped.
+type ExcludedMigration struct {
+	Version     int64     `json:"version"`
+	Description string    `json:"description"`
+	ExcludedAt  time.Time `json:"excluded_at"`
+}
+
+// ExcludedMigrations returns the migrations that runs skipped because their versions were excluded
+// and that aren't applied yet, in version order. They are recorded in the migration table with
+// StatusExcluded. Nothing is recorded when tracking the user_version.
+func (db *Database) ExcludedMigrations(ctx context.Context) ([]ExcludedMigration, error) {
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return db.readExcluded(ctx, q)
+}
+
+// readExcluded returns the migrations recorded as excluded, in version order.
+func (db *Database) readExcluded(ctx context.Context, q queryer) ([]ExcludedMigration, error) {
+	excluded := make([]ExcludedMigration, 0)
+	if db.userVersion {
+		return excluded, nil
+	}
+
+	columns, err := db.stateColumns(ctx, q, db.migrationTable)
+	if err != nil || !contains(columns, ColumnStatus) {
+		return excluded, err
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s, COALESCE(%s, '') FROM %s WHERE %s = ? ORDER BY %s ASC;",
+		ColumnVersion, ColumnDescription, ColumnAppliedAt, db.qualify(db.migrationTable), ColumnStatus, ColumnVersion)
+	rows, err := q.QueryContext(ctx, query, StatusExcluded)
+	if err != nil {
+		return nil, errorf(CodeMigrationTable, "failed to read excluded migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			migration  ExcludedMigration
+			excludedAt string
+		)
+		if err := rows.Scan(&migration.Version, &migration.Description, &excludedAt); err != nil {
+			return nil, err
+		}
+		migration.ExcludedAt, _ = time.Parse(time.RFC3339Nano, excludedAt)
+		excluded = append(excluded, migration)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return excluded, nil
+}
+
+// recordExcluded records the pending migrations the run excluded in the migration table, unless
+// they already are.
+func (db *Database) recordExcluded(ctx context.Context, tx *sql.Tx, excluded []Migration) error {
+	if db.userVersion {
+		return nil
+	}
+
+	for _, migration := range excluded {
+		recorded, err := db.excludedRecorded(ctx, tx, migration.Version)
+		if err != nil {
+			return err
+		}
+		if recorded {
+			continue
+		}
+
+		if err := db.insertRecord(ctx, tx, migration.Version, migration.Description, StatusExcluded); err != nil {
+			return err
+		}
+		log.Printf("excluded migration: (version=%v, description=%s)", migration.Version, migration.Description)
+	}
+	return nil
+}
+
+// excludedVersionsRecorded returns the versions recorded as excluded.
+func (db *Database) excludedVersionsRecorded(ctx context.Context, q queryer) (map[int64]bool, error) {
+	excluded, err := db.readExcluded(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := map[int64]bool{}
+	for _, migration := range excluded {
+		versions[migration.Version] = true
+	}
+	return versions, nil
+}
+
+// excludedRecorded reports whether the version is recorded as excluded.
+func (db *Database) excludedRecorded(ctx context.Context, tx *sql.Tx, version int64) (bool, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) > 0 FROM %s WHERE %s = ? AND %s = ?;", db.qualify(db.migrationTable), ColumnVersion, ColumnStatus)
+
+	recorded := false
+	if err := tx.QueryRowContext(ctx, query, version, StatusExcluded).Scan(&recorded); err != nil {
+		return false, errorf(CodeMigrationTable, "failed to read excluded migrations: %w", err)
+	}
+	return recorded, nil
+}
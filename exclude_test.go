@@ -0,0 +1,131 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestExcludedVersions(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		tableMigration(3, "three"),
+	}).SetExcludedVersions(2)
+
+	result, err := db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 2 || result.Applied[0] != 1 || result.Applied[1] != 3 {
+		t.Errorf("expected versions 1 and 3 to be applied, got %v", result.Applied)
+	}
+	if len(result.Excluded) != 1 || result.Excluded[0] != 2 {
+		t.Errorf("expected version 2 to be excluded, got %v", result.Excluded)
+	}
+
+	excluded, err := db.ExcludedMigrations(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(excluded) != 1 || excluded[0].Version != 2 || excluded[0].Description != "Create two table" || excluded[0].ExcludedAt.IsZero() {
+		t.Fatalf("expected version 2 to be recorded as excluded, got %+v", excluded)
+	}
+
+	report, err := db.Validate(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !report.Valid() || len(report.Pending) != 0 || len(report.Excluded) != 1 || report.Excluded[0] != 2 {
+		t.Errorf("expected version 2 to be reported as excluded, got %+v", report)
+	}
+
+	status, err := db.Status(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Dirty || len(status.Pending) != 0 {
+		t.Errorf("expected a clean status without pending migrations, got %+v", status)
+	}
+
+	result, err = db.MigrateUp(ctx, litemigrate.WithoutVersions())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 1 || result.Applied[0] != 2 {
+		t.Errorf("expected version 2 to be applied once no longer excluded, got %v", result.Applied)
+	}
+
+	excluded, err = db.ExcludedMigrations(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(excluded) != 0 {
+		t.Errorf("expected no excluded migrations, got %+v", excluded)
+	}
+}
+
+func TestExcludedVersionsHistory(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+	}).SetHashChain(nil).SetExcludedVersions(2)
+
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	history, err := db.History(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(history) != 2 || history[0].Status != litemigrate.StatusApplied || history[1].Version != 2 || history[1].Status != litemigrate.StatusExcluded {
+		t.Fatalf("expected version 2 to be recorded as excluded in the history, got %+v", history)
+	}
+	if version, err := db.CurrentVersion(ctx); err != nil || version != 1 {
+		t.Errorf("expected version 1, got %d (%v)", version, err)
+	}
+
+	count := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = '_migrations_excluded';").Scan(&count); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if count != 0 {
+		t.Error("expected no separate table for excluded migrations")
+	}
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithoutVersions()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	history, err = db.History(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(history) != 2 || history[1].Version != 2 || history[1].Status != litemigrate.StatusApplied {
+		t.Fatalf("expected the excluded record to be replaced once applied, got %+v", history)
+	}
+	if err := db.VerifyHistory(ctx); err != nil {
+		t.Errorf("expected valid chain, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+// Package fixtures loads test data into a migrated database.
+//
+// Every YAML or JSON file in the root of the fixture directory holds the rows of the table it is
+// named after, as a list of column values:
+//
+//	# users.yml
+//	- id: 1
+//	  name: alice
+//	- id: 2
+//	  name: bob
+//
+// SQL files are executed as they are. Files are loaded in the order of their names, inside a
+// single transaction, after the tables of the YAML and JSON files have been emptied.
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type fixture struct {
+	name  string
+	table string
+	rows  []map[string]any
+	sql   string
+}
+
+// Load loads the fixture files in the root of fsys into the database. Foreign key checks are
+// deferred until the transaction commits, so the order of the files only matters for SQL files.
+func Load(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	fixtures, err := read(fsys)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON;"); err != nil {
+		return err
+	}
+
+	for i := len(fixtures) - 1; i >= 0; i-- {
+		if fixtures[i].table == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s;", quoteIdent(fixtures[i].table))); err != nil {
+			return fmt.Errorf("failed to truncate %s: %w", fixtures[i].table, err)
+		}
+	}
+
+	for _, f := range fixtures {
+		if f.table == "" {
+			if _, err := tx.ExecContext(ctx, f.sql); err != nil {
+				return fmt.Errorf("failed to load fixture %s: %w", f.name, err)
+			}
+			continue
+		}
+
+		for i, row := range f.rows {
+			if err := insert(ctx, tx, f.table, row); err != nil {
+				return fmt.Errorf("failed to load fixture %s: row %d: %w", f.name, i+1, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// read parses the fixture files in the root of fsys, sorted by name.
+func read(fsys fs.FS) ([]fixture, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	fixtures := make([]fixture, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := path.Ext(name)
+		if entry.IsDir() || ext != ".yml" && ext != ".yaml" && ext != ".json" && ext != ".sql" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		f := fixture{name: name}
+		switch ext {
+		case ".sql":
+			f.sql = string(data)
+		case ".json":
+			f.table = strings.TrimSuffix(name, ext)
+			err = json.Unmarshal(data, &f.rows)
+		default:
+			f.table = strings.TrimSuffix(name, ext)
+			err = yaml.Unmarshal(data, &f.rows)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", name, err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+func insert(ctx context.Context, tx *sql.Tx, table string, row map[string]any) error {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	if len(columns) == 0 {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s DEFAULT VALUES;", quoteIdent(table)))
+		return err
+	}
+
+	names := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		names[i] = quoteIdent(column)
+		placeholders[i] = "?"
+		values[i] = row[column]
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", quoteIdent(table), strings.Join(names, ", "), strings.Join(placeholders, ", "))
+	_, err := tx.ExecContext(ctx, query, values...)
+	return err
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,83 @@
+package fixtures_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate/fixtures"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLoad(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`
+		PRAGMA foreign_keys = ON;
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users (id), title TEXT);
+		INSERT INTO users (id, name) VALUES (9, 'stale');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	fsys := fstest.MapFS{
+		"posts.json":  {Data: []byte(`[{"id": 1, "user_id": 2, "title": "hello"}]`)},
+		"users.yml":   {Data: []byte("- id: 1\n  name: alice\n- id: 2\n  name: bob\n")},
+		"zz_more.sql": {Data: []byte("INSERT INTO posts (id, user_id, title) VALUES (2, 1, 'more');")},
+		"README.md":   {Data: []byte("not a fixture")},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := fixtures.Load(context.Background(), db, fsys); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	var users, posts int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users;").Scan(&users); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM posts;").Scan(&posts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if users != 2 || posts != 2 {
+		t.Errorf("expected 2 users and 2 posts, got %d and %d", users, posts)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);`); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := map[string]fstest.MapFS{
+		"bad yaml":       {"users.yml": {Data: []byte("- id: [")}},
+		"missing table":  {"accounts.json": {Data: []byte(`[{"id": 1}]`)}},
+		"bad constraint": {"users.json": {Data: []byte(`[{"id": 1}]`)}},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := fixtures.Load(context.Background(), db, fsys); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
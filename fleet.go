@@ -0,0 +1,138 @@
+package litemigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Fleet applies the same migrations to many SQLite databases, e.g. in architectures with one
+// database per tenant. Every database is migrated on its own, so a failure doesn't stop the
+// others.
+type Fleet struct {
+	migrations *Migrations
+	discover   func(ctx context.Context) ([]string, error)
+	configure  func(db *Database)
+	workers    int
+}
+
+// DatabaseStatus is the outcome of migrating one database of a fleet.
+type DatabaseStatus struct {
+	Path   string
+	Result *Result
+	Err    error
+}
+
+// FleetReport reports the outcome of migrating every database of a fleet, in the order they were
+// discovered.
+type FleetReport struct {
+	Databases []DatabaseStatus
+}
+
+// Failed returns the databases that failed to migrate.
+func (r *FleetReport) Failed() []DatabaseStatus {
+	failed := make([]DatabaseStatus, 0)
+	for _, status := range r.Databases {
+		if status.Err != nil {
+			failed = append(failed, status)
+		}
+	}
+	return failed
+}
+
+// NewFleet creates a fleet of the databases returned by discover. The paths are opened like the
+// DSN of New. See Glob for discovering database files.
+func NewFleet(migrations *Migrations, discover func(ctx context.Context) ([]string, error)) *Fleet {
+	return &Fleet{
+		migrations: migrations,
+		discover:   discover,
+		workers:    1,
+	}
+}
+
+// Glob returns a discover function for NewFleet that matches database files with a pattern, such
+// as "tenants/*.db".
+func Glob(pattern string) func(ctx context.Context) ([]string, error) {
+	return func(ctx context.Context) ([]string, error) {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(paths)
+		return paths, nil
+	}
+}
+
+// Configure sets a function that configures every database of the fleet before it is migrated,
+// e.g. with SetMigrationTable or SetBackup. With more than one worker it is called concurrently.
+func (f *Fleet) Configure(configure func(db *Database)) *Fleet {
+	f.configure = configure
+	return f
+}
+
+// SetConcurrency sets the number of databases migrated at the same time. Every database uses its
+// own connection, so they don't interfere with each other. It defaults to 1.
+func (f *Fleet) SetConcurrency(workers int) *Fleet {
+	if workers < 1 {
+		workers = 1
+	}
+	f.workers = workers
+	return f
+}
+
+// MigrateUp migrates every database of the fleet up. Databases that haven't started when the
+// context is canceled are reported with the context's error. It returns an error if the databases
+// can't be discovered, or an error joining the errors of the databases that failed to migrate. The
+// report lists the outcome of every database either way.
+func (f *Fleet) MigrateUp(ctx context.Context, opts ...RunOption) (*FleetReport, error) {
+	paths, err := f.discover(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover databases: %w", err)
+	}
+
+	report := &FleetReport{Databases: make([]DatabaseStatus, len(paths))}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, f.workers)
+	for i, path := range paths {
+		status := &report.Databases[i]
+		status.Path = path
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			status.Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			status.Result, status.Err = f.migrateUp(ctx, status.Path, opts)
+		}()
+	}
+	wg.Wait()
+
+	errs := make([]error, 0)
+	for _, status := range report.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", status.Path, status.Err))
+	}
+	return report, errors.Join(errs...)
+}
+
+func (f *Fleet) migrateUp(ctx context.Context, path string, opts []RunOption) (*Result, error) {
+	db, err := New(path, f.migrations)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if f.configure != nil {
+		f.configure(db)
+	}
+	return db.MigrateUp(ctx, opts...)
+}
@@ -0,0 +1,123 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestFleet(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"a.db", "b.db", "c.db"} {
+		conn, err := sql.Open("sqlite3", filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// The table of the first migration already exists in b.db, so migrating it fails.
+		query := "CREATE TABLE tenant (id INTEGER PRIMARY KEY);"
+		if name == "b.db" {
+			query += "CREATE TABLE one (id INTEGER PRIMARY KEY);"
+		}
+		if _, err := conn.Exec(query); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		conn.Close()
+	}
+
+	migrations := &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+	}
+
+	configured := 0
+	fleet := litemigrate.NewFleet(migrations, litemigrate.Glob(filepath.Join(dir, "*.db"))).
+		Configure(func(db *litemigrate.Database) { configured++ })
+
+	report, err := fleet.MigrateUp(context.Background())
+	if err == nil {
+		t.Fatal("expected error for b.db, got nil")
+	}
+
+	if len(report.Databases) != 3 || configured != 3 {
+		t.Fatalf("expected 3 configured databases, got %d and %d", len(report.Databases), configured)
+	}
+
+	failed := report.Failed()
+	if len(failed) != 1 || filepath.Base(failed[0].Path) != "b.db" {
+		t.Fatalf("expected b.db to fail, got %v", failed)
+	}
+
+	for _, status := range report.Databases {
+		if status.Err == nil && status.Result.Version != 2 {
+			t.Errorf("expected %s at version 2, got %d", status.Path, status.Result.Version)
+		}
+	}
+}
+
+func TestFleetConcurrency(t *testing.T) {
+	dir := t.TempDir()
+
+	for i := 0; i < 8; i++ {
+		conn, err := sql.Open("sqlite3", filepath.Join(dir, fmt.Sprintf("tenant%d.db", i)))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := conn.Exec("CREATE TABLE tenant (id INTEGER PRIMARY KEY);"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		conn.Close()
+	}
+
+	var running, peak int32
+	slow := func(next litemigrate.Runner) litemigrate.Runner {
+		return func(ctx context.Context, tx *sql.Tx, step litemigrate.Step) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return next(ctx, tx, step)
+		}
+	}
+
+	fleet := litemigrate.NewFleet(&litemigrate.Migrations{tableMigration(1, "one")}, litemigrate.Glob(filepath.Join(dir, "*.db"))).
+		Configure(func(db *litemigrate.Database) { db.Use(slow) }).
+		SetConcurrency(4)
+
+	report, err := fleet.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(report.Databases) != 8 || filepath.Base(report.Databases[0].Path) != "tenant0.db" {
+		t.Fatalf("expected 8 databases in discovery order, got %v", report.Databases)
+	}
+
+	if peak < 2 || peak > 4 {
+		t.Errorf("expected between 2 and 4 concurrent migrations, got %d", peak)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err = fleet.MigrateUp(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+	if len(report.Failed()) != 8 {
+		t.Errorf("expected every database to fail, got %d", len(report.Failed()))
+	}
+}
@@ -0,0 +1,103 @@
+package litemigrate
+
+import (
+	"context"
+	"log"
+	"sort"
+)
+
+// Force sets the recorded version after the database was recovered by hand, such as after a
+// failed migration was fixed manually. Afterwards, the migration table records exactly the
+// migrations in code up to the version as applied: missing records are added without running
+// their Up functions, and records of later versions, of versions that aren't defined and with
+// mismatched descriptions or checksums are removed, which clears the problems Validate reports.
+// The marker of a failed migration, see FailedMigration, is removed too. A version of 0 removes
+// all records. Nothing is run against the schema.
+func (db *Database) Force(ctx context.Context, version int64) (err error) {
+	if err := db.migrations.validate(); err != nil {
+		return err
+	}
+	defer func() { err = lockedError(err) }()
+
+	migrations := db.migrations.byVersion()
+	if _, ok := migrations[version]; !ok && version != 0 {
+		return errorf(CodeUnknownMigration, "can't force version %v: migration isn't defined", version)
+	}
+
+	unlock, err := db.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tx, release, err := db.beginState(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := db.createMigrationTable(ctx, tx); err != nil {
+		return err
+	}
+
+	records, err := db.getMigrationRecords(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	checksums, err := db.getMigrationChecksums(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	recorded := make([]int64, 0, len(records))
+	for v := range records {
+		recorded = append(recorded, v)
+	}
+	sort.Slice(recorded, func(i, j int) bool { return recorded[i] > recorded[j] })
+
+	for _, v := range recorded {
+		migration, defined := migrations[v]
+		if defined && v <= version && migration.Description == records[v] && !migration.modified(checksums[v]) {
+			continue
+		}
+
+		if err := db.deleteMigration(ctx, tx, v); err != nil {
+			return err
+		}
+		log.Printf("removed migration record (version=%v, description=%s)", v, records[v])
+		delete(records, v)
+	}
+
+	for _, migration := range db.migrations.sorted() {
+		if migration.Version > version {
+			break
+		}
+		if _, ok := records[migration.Version]; ok {
+			continue
+		}
+
+		if err := db.insertMigration(ctx, tx, migration.Version, migration.Description); err != nil {
+			return err
+		}
+		log.Printf("recorded migration without running it (version=%v, description=%s)", migration.Version, migration.Description)
+	}
+
+	marker, err := db.readFailed(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if marker != nil {
+		if err := db.clearFailed(ctx, tx); err != nil {
+			return err
+		}
+		log.Printf("removed failed migration marker (version=%v)", marker.Version)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("forced version (version=%v)", version)
+	return nil
+}
@@ -0,0 +1,88 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestForce(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	migrations := litemigrate.Migrations{tableMigration(1, "users"), tableMigration(2, "posts"), tableMigration(3, "comments")}
+	db := litemigrate.NewWithConn(conn, &migrations)
+	if _, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(2)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := db.Force(ctx, 3); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version, _ := db.CurrentVersion(ctx); version != 3 {
+		t.Errorf("expected version 3, got %d", version)
+	}
+	if _, err := conn.Exec("SELECT * FROM comments;"); err == nil {
+		t.Error("expected migration 3 to be recorded without running")
+	}
+
+	renamed := litemigrate.Migrations{tableMigration(1, "users"), tableMigration(2, "articles"), tableMigration(3, "comments")}
+	db = litemigrate.NewWithConn(conn, &renamed)
+	if err := db.Force(ctx, 2); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	report, err := db.Validate(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !report.Valid() || len(report.Pending) != 1 || report.Pending[0] != 3 {
+		t.Errorf("expected a consistent database with version 3 pending, got %s", report)
+	}
+
+	if err := db.Force(ctx, 99); litemigrate.Code(err) != litemigrate.CodeUnknownMigration {
+		t.Errorf("expected an unknown migration error, got %v", err)
+	}
+}
+
+func TestForceFailed(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	broken := tableMigration(2, "posts")
+	broken.Up = func(tx *sql.Tx) error {
+		return errors.New("backfill failed")
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users"), broken}).SetCommitEach(true)
+	if _, err := db.MigrateUp(ctx); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	if err := db.Force(ctx, 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	failed, err := db.FailedMigration(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if failed != nil {
+		t.Errorf("expected the failed migration marker to be removed, got %+v", failed)
+	}
+}
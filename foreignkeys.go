@@ -0,0 +1,124 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ForeignKeyMode controls how foreign key constraints are enforced during a migration run.
+type ForeignKeyMode int
+
+const (
+	// ForeignKeysUnchanged leaves foreign key enforcement as configured on the connection.
+	ForeignKeysUnchanged ForeignKeyMode = iota
+	// ForeignKeysDeferred defers foreign key checks until the run commits, so migrations can
+	// change rows in any order.
+	ForeignKeysDeferred
+	// ForeignKeysDisabled turns foreign keys off for the run, as SQLite requires for rebuilding
+	// tables that are referenced by other tables. They are turned back on afterwards.
+	ForeignKeysDisabled
+)
+
+// SetForeignKeyMode sets how foreign keys are enforced during migration runs. With any mode other
+// than ForeignKeysUnchanged, PRAGMA foreign_key_check runs before the run commits and the run fails
+// if it reports violations.
+func (db *Database) SetForeignKeyMode(mode ForeignKeyMode) *Database {
+	db.foreignKeyMode = mode
+	return db
+}
+
+// begin starts the transaction of a migration run according to the foreign key mode. The returned
+// function rolls the transaction back if it wasn't committed and restores the connection.
+func (db *Database) begin(ctx context.Context) (*sql.Tx, func(), error) {
+	if db.foreignKeyMode != ForeignKeysDisabled {
+		tx, release, err := db.beginState(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if db.foreignKeyMode == ForeignKeysDeferred {
+			if _, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON;"); err != nil {
+				release()
+				return nil, nil, err
+			}
+		}
+		return tx, release, nil
+	}
+
+	// PRAGMA foreign_keys is a no-op inside a transaction, so it is set on a dedicated connection
+	// before the transaction starts.
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enabled := false
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys;").Scan(&enabled); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF;"); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	detach, err := attach(ctx, conn, db.attachments())
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	release := func() {
+		if enabled {
+			conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON;")
+		}
+		detach()
+		conn.Close()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return tx, func() { tx.Rollback(); release() }, nil
+}
+
+// checkForeignKeys fails if PRAGMA foreign_key_check reports violations. It is a no-op with
+// ForeignKeysUnchanged unless the integrity check is enabled.
+func (db *Database) checkForeignKeys(ctx context.Context, tx *sql.Tx) error {
+	if db.foreignKeyMode == ForeignKeysUnchanged && !db.integrityCheck {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check;")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	violations := make([]string, 0)
+	for rows.Next() {
+		var (
+			table, parent string
+			rowid         sql.NullInt64
+			fkid          int64
+		)
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		violations = append(violations, fmt.Sprintf("%s (rowid=%v) references %s", table, rowid.Int64, parent))
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan rows: %w", err)
+	}
+
+	if len(violations) > 0 {
+		return errorf(CodeForeignKeyViolation, "foreign key check failed: %s", strings.Join(violations, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestForeignKeysDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		PRAGMA foreign_keys = ON;
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id) ON DELETE CASCADE);
+		INSERT INTO users (id, name) VALUES (1, 'alice');
+		INSERT INTO posts (id, user_id) VALUES (1, 1);
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rebuild := litemigrate.Migration{
+		Version:     1,
+		Description: "Rebuild users table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE users_new (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+				INSERT INTO users_new SELECT id, name FROM users;
+				DROP TABLE users;
+				ALTER TABLE users_new RENAME TO users;
+			`)
+			return err
+		},
+		Down: func(tx *sql.Tx) error { return nil },
+	}
+
+	orphan := litemigrate.Migration{
+		Version:     2,
+		Description: "Insert orphaned post",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO posts (id, user_id) VALUES (2, 42);")
+			return err
+		},
+		Down: func(tx *sql.Tx) error { return nil },
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{rebuild, orphan}).
+		SetForeignKeyMode(litemigrate.ForeignKeysDisabled)
+
+	_, err = db.MigrateUp(ctx)
+	if litemigrate.Code(err) != litemigrate.CodeForeignKeyViolation {
+		t.Fatalf("expected foreign key violation, got %v", err)
+	}
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	posts := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM posts;").Scan(&posts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if posts != 1 {
+		t.Errorf("expected the rebuild to keep the posts, got %d", posts)
+	}
+
+	enabled := false
+	if err := conn.QueryRow("PRAGMA foreign_keys;").Scan(&enabled); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !enabled {
+		t.Error("expected foreign keys to be enabled again after the run")
+	}
+}
@@ -0,0 +1,25 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Fresh opens an in-memory database with the migrations applied, e.g. for fast unit tests. Like
+// New, it uses the driver registered as "sqlite3". The database is limited to one connection,
+// since every connection to ":memory:" opens a separate database, and it is gone once the caller
+// closes it. Options apply as they would to MigrateUp. For tests, litemigratetest.MustMigrate
+// also closes the database and reports failures through testing.TB.
+func Fresh(ctx context.Context, migrations *Migrations, opts ...RunOption) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMaxOpenConns(1)
+
+	if _, err := NewWithConn(conn, migrations).MigrateUp(ctx, opts...); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
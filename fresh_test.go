@@ -0,0 +1,29 @@
+package litemigrate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestFresh(t *testing.T) {
+	ctx := context.Background()
+	conn, err := litemigrate.Fresh(ctx, &litemigrate.Migrations{tableMigration(1, "one"), tableMigration(2, "two")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	for _, table := range []string{"one", "two"} {
+		if _, err := conn.Exec("INSERT INTO " + table + " (id) VALUES (1);"); err != nil {
+			t.Errorf("expected table %s to exist, got %v", table, err)
+		}
+	}
+
+	invalid := tableMigration(1, "one")
+	invalid.Up = nil
+	if _, err := litemigrate.Fresh(ctx, &litemigrate.Migrations{invalid}); err == nil {
+		t.Error("expected error for an invalid migration, got nil")
+	}
+}
@@ -0,0 +1,310 @@
+package litemigrate
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"time"
+)
+
+// HistoryRecord is a row of the migration table.
+type HistoryRecord struct {
+	ID          int64  `json:"id"`
+	Version     int64  `json:"version"`
+	Description string `json:"description"`
+	// Duration is the execution time of the migration, rounded to milliseconds. It is zero for
+	// migrations that were recorded without running them.
+	Duration  time.Duration `json:"duration,omitempty"`
+	AppliedAt time.Time     `json:"applied_at"`
+	AppliedBy AppliedBy     `json:"applied_by"`
+	// Database is the name of the database the migration targets. See Migration.Database.
+	Database string `json:"database,omitempty"`
+	// Status is StatusApplied, or StatusExcluded for a migration that runs skipped because its
+	// version was excluded.
+	Status string `json:"status"`
+	// Checksum is the Checksum of the migration when it was applied. See Migration.Checksum.
+	Checksum string `json:"checksum,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// SetHashChain makes the migration table tamper-evident: every record stores the hash of the
+// previous record and a hash over its own contents, so editing, inserting or deleting records
+// outside of litemigrate breaks the chain. Records that exist when the chain is enabled are
+// hashed on the next migration run. With a nil key the hashes are plain SHA-256, which only
+// proves integrity against an exported copy; with a key they are HMAC-SHA256 and cannot be
+// recomputed without it.
+func (db *Database) SetHashChain(key []byte) *Database {
+	db.hashChain = true
+	db.hashKey = key
+	return db
+}
+
+// ExportHistory writes the migration table to w as JSON lines, in the order the migrations were
+// applied.
+func (db *Database) ExportHistory(ctx context.Context, w io.Writer) error {
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return err
+	}
+	records, err := db.readHistory(ctx, q)
+	release()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyHistory checks the hash chain of the migration table and returns an error describing the
+// first record that doesn't match.
+func (db *Database) VerifyHistory(ctx context.Context) error {
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return err
+	}
+	records, err := db.readHistory(ctx, q)
+	release()
+	if err != nil {
+		return err
+	}
+	return verifyChain(records, db.hashKey)
+}
+
+// VerifyExport checks the hash chain of a history exported with ExportHistory, using the same key
+// the chain was created with.
+func VerifyExport(r io.Reader, key []byte) error {
+	records := make([]HistoryRecord, 0)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var record HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to decode history record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return verifyChain(records, key)
+}
+
+func verifyChain(records []HistoryRecord, key []byte) error {
+	prevHash := ""
+	for _, record := range records {
+		if record.PrevHash != prevHash {
+			return errorf(CodeBrokenHistory, "broken history chain: (id=%v, version=%v) doesn't follow the previous record", record.ID, record.Version)
+		}
+
+		if record.Hash != chainHash(key, prevHash, record) {
+			return errorf(CodeChecksumMismatch, "broken history chain: (id=%v, version=%v) hash doesn't match its contents", record.ID, record.Version)
+		}
+		prevHash = record.Hash
+	}
+	return nil
+}
+
+// chainHash returns the hash of the record following prevHash. It covers every recorded column
+// except the id, in a fixed order, each prefixed with its length so values can't run into each
+// other.
+func chainHash(key []byte, prevHash string, record HistoryRecord) string {
+	var h hash.Hash
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+
+	appliedAt := ""
+	if !record.AppliedAt.IsZero() {
+		appliedAt = record.AppliedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	fields := []string{
+		prevHash,
+		strconv.FormatInt(record.Version, 10),
+		record.Description,
+		strconv.FormatInt(record.Duration.Milliseconds(), 10),
+		appliedAt,
+		record.AppliedBy.Host,
+		record.AppliedBy.User,
+		record.AppliedBy.AppVersion,
+		record.AppliedBy.ApprovedBy,
+		record.Database,
+		record.Status,
+	}
+	for _, field := range fields {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+		h.Write(length[:])
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (db *Database) readHistory(ctx context.Context, q queryer) ([]HistoryRecord, error) {
+	records := make([]HistoryRecord, 0)
+
+	if db.userVersion {
+		index, err := db.userVersionIndex(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		defined := db.migrations.byVersion()
+		for i, version := range index {
+			records = append(records, HistoryRecord{ID: int64(i + 1), Version: version, Description: defined[version].Description, Status: StatusApplied})
+		}
+		return records, nil
+	}
+
+	exists, err := db.migrationTableExists(ctx, q)
+	if err != nil || !exists {
+		return records, err
+	}
+
+	columns, err := db.stateColumns(ctx, q, db.migrationTable)
+	if err != nil {
+		return nil, err
+	}
+
+	optional := func(column, fallback string) string {
+		if contains(columns, column) {
+			return fmt.Sprintf("COALESCE(%s, %s)", column, fallback)
+		}
+		return fallback
+	}
+
+	query := fmt.Sprintf("SELECT id, version, description, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s FROM %s ORDER BY id ASC;",
+		optional(ColumnDurationMS, "0"),
+		optional(ColumnAppliedAt, "''"),
+		optional(ColumnAppliedHost, "''"),
+		optional(ColumnAppliedUser, "''"),
+		optional(ColumnAppVersion, "''"),
+		optional(ColumnApprovedBy, "''"),
+		optional(ColumnDatabase, "''"),
+		optional(ColumnStatus, "'"+StatusApplied+"'"),
+		optional(ColumnChecksum, "''"),
+		optional(ColumnPrevHash, "''"),
+		optional(ColumnHash, "''"),
+		db.qualify(db.migrationTable))
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			record     HistoryRecord
+			durationMS int64
+			appliedAt  string
+		)
+		err := rows.Scan(&record.ID, &record.Version, &record.Description, &durationMS, &appliedAt,
+			&record.AppliedBy.Host, &record.AppliedBy.User, &record.AppliedBy.AppVersion,
+			&record.AppliedBy.ApprovedBy, &record.Database, &record.Status, &record.Checksum, &record.PrevHash, &record.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Duration = time.Duration(durationMS) * time.Millisecond
+		if appliedAt != "" {
+			if record.AppliedAt, err = time.Parse(time.RFC3339Nano, appliedAt); err != nil {
+				return nil, fmt.Errorf("invalid applied_at of migration (version=%v): %w", record.Version, err)
+			}
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return records, nil
+}
+
+// addHashColumns adds the hash chain columns to an existing migration table and hashes the
+// records it already contains.
+func (db *Database) addHashColumns(ctx context.Context, tx *sql.Tx) error {
+	columns, err := db.stateColumns(ctx, tx, db.migrationTable)
+	if err != nil {
+		return err
+	}
+
+	if contains(columns, ColumnHash) {
+		return nil
+	}
+
+	for _, column := range []string{ColumnPrevHash, ColumnHash} {
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT;", db.qualify(db.migrationTable), column)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return errorf(CodeMigrationTable, "failed to add %s column to migration table: %w", column, err)
+		}
+	}
+
+	records, err := db.readHistory(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return db.rehash(ctx, tx, records, "")
+}
+
+// rehash recomputes the chain for the records, starting from prevHash.
+func (db *Database) rehash(ctx context.Context, tx *sql.Tx, records []HistoryRecord, prevHash string) error {
+	query := fmt.Sprintf("UPDATE %s SET %s = ?, %s = ? WHERE %s = ?;", db.qualify(db.migrationTable), ColumnPrevHash, ColumnHash, ColumnID)
+	for _, record := range records {
+		hash := chainHash(db.hashKey, prevHash, record)
+		if _, err := tx.ExecContext(ctx, query, prevHash, hash, record.ID); err != nil {
+			return errorf(CodeMigrationTable, "failed to hash migration (version=%v): %w", record.Version, err)
+		}
+		prevHash = hash
+	}
+	return nil
+}
+
+// lastHash returns the hash of the most recently applied migration.
+func (db *Database) lastHash(ctx context.Context, tx *sql.Tx) (string, error) {
+	query := fmt.Sprintf("SELECT COALESCE(%s, '') FROM %s ORDER BY %s DESC LIMIT 1;", ColumnHash, db.qualify(db.migrationTable), ColumnID)
+
+	hash := ""
+	err := tx.QueryRowContext(ctx, query).Scan(&hash)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	return hash, nil
+}
+
+// unlink verifies the chain and relinks the records that follow the one with the given version,
+// so the chain stays intact after it is deleted.
+func (db *Database) unlink(ctx context.Context, tx *sql.Tx, version int64) error {
+	records, err := db.readHistory(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyChain(records, db.hashKey); err != nil {
+		return err
+	}
+
+	for i, record := range records {
+		if record.Version == version {
+			return db.rehash(ctx, tx, records[i+1:], record.PrevHash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package litemigrate_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestHashChain(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	key := []byte("secret")
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		tableMigration(3, "three"),
+	}).SetHashChain(key)
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = db.MigrateDown(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = db.VerifyHistory(context.Background())
+	if err != nil {
+		t.Errorf("expected valid chain, got %v", err)
+	}
+
+	var export bytes.Buffer
+	err = db.ExportHistory(context.Background(), &export)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = litemigrate.VerifyExport(bytes.NewReader(export.Bytes()), key)
+	if err != nil {
+		t.Errorf("expected valid export, got %v", err)
+	}
+
+	err = litemigrate.VerifyExport(bytes.NewReader(export.Bytes()), []byte("wrong"))
+	if err == nil {
+		t.Error("expected error verifying with the wrong key, got nil")
+	}
+
+	_, err = conn.Exec(`UPDATE _migrations SET description = 'tampered' WHERE version = 1;`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = db.VerifyHistory(context.Background())
+	if err == nil {
+		t.Error("expected broken chain after tampering, got nil")
+	}
+}
+
+func TestHashChainColumns(t *testing.T) {
+	columns := []string{
+		litemigrate.ColumnDurationMS,
+		litemigrate.ColumnAppliedAt,
+		litemigrate.ColumnAppliedHost,
+		litemigrate.ColumnAppliedUser,
+		litemigrate.ColumnAppVersion,
+		litemigrate.ColumnApprovedBy,
+		litemigrate.ColumnDatabase,
+	}
+
+	for _, column := range columns {
+		conn, err := sql.Open("sqlite3", testDBPath)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer conn.Close()
+		conn.SetMaxOpenConns(1)
+
+		db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users")}).
+			SetHashChain(nil).
+			SetAppliedBy(litemigrate.AppliedBy{Host: "host", User: "user", AppVersion: "1.0.0", ApprovedBy: "approver"})
+		if _, err := db.MigrateUp(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := db.VerifyHistory(context.Background()); err != nil {
+			t.Fatalf("expected valid chain, got %v", err)
+		}
+
+		// Every column is changed to a value of the same type that differs from the recorded one.
+		value := "'2000-01-01T00:00:00Z'"
+		if column == litemigrate.ColumnDurationMS {
+			value = column + " + 1"
+		}
+		if _, err := conn.Exec(fmt.Sprintf("UPDATE _migrations SET %s = %s;", column, value)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if code := litemigrate.Code(db.VerifyHistory(context.Background())); code != litemigrate.CodeChecksumMismatch {
+			t.Errorf("expected code %s after changing %s, got %q", litemigrate.CodeChecksumMismatch, column, code)
+		}
+	}
+}
+
+func TestHashChainExistingHistory(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	migrations := &litemigrate.Migrations{tableMigration(1, "one")}
+
+	_, err = litemigrate.NewWithConn(conn, migrations).MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	*migrations = append(*migrations, tableMigration(2, "two"))
+	db := litemigrate.NewWithConn(conn, migrations).SetHashChain(nil)
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = db.VerifyHistory(context.Background())
+	if err != nil {
+		t.Errorf("expected valid chain, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// historySchema is the schema name the history database is attached as.
+const historySchema = "litemigrate_history"
+
+// SetHistoryDatabase stores the migration table and the other bookkeeping tables, such as the ones
+// of repeatable and failed migrations, in a separate SQLite file instead of the database, so the
+// data file stays free of tables of the tool, e.g. for read-only replicas or strict schemas. The
+// file is created if needed and attached as "litemigrate_history" while migrating or reading the
+// migration state, see Attach. Runs update both files in the same transaction. Backups only cover
+// the database itself, and CopyTable keeps its cursors in the database.
+func (db *Database) SetHistoryDatabase(path string) *Database {
+	db.historyDatabase = path
+	return db
+}
+
+// qualify quotes the name of a bookkeeping table, qualified with the history database if set.
+func (db *Database) qualify(table string) string {
+	if db.historyDatabase == "" {
+		return quoteIdent(table)
+	}
+	return quoteIdent(historySchema) + "." + quoteIdent(table)
+}
+
+// stateSchema returns the schema holding the bookkeeping tables.
+func (db *Database) stateSchema() string {
+	if db.historyDatabase == "" {
+		return "main"
+	}
+	return historySchema
+}
+
+// stateConn returns a connection that can read the bookkeeping tables, and a function releasing
+// it. Without a history database, it is the connection pool itself.
+func (db *Database) stateConn(ctx context.Context) (queryer, func(), error) {
+	if db.historyDatabase == "" {
+		return db.conn, func() {}, nil
+	}
+
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	detach, err := attach(ctx, conn, []attachment{{name: historySchema, path: db.historyDatabase}})
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, func() { detach(); conn.Close() }, nil
+}
+
+// beginState begins a transaction that can access the bookkeeping tables and the attached
+// databases, and returns a function rolling it back unless it was committed and releasing its
+// connection.
+func (db *Database) beginState(ctx context.Context) (*sql.Tx, func(), error) {
+	if len(db.attachments()) == 0 {
+		tx, err := db.conn.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tx, func() { tx.Rollback() }, nil
+	}
+
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	detach, err := attach(ctx, conn, db.attachments())
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		detach()
+		conn.Close()
+		return nil, nil, err
+	}
+	return tx, func() { tx.Rollback(); detach(); conn.Close() }, nil
+}
+
+// stateTableExists reports whether the bookkeeping table exists.
+func (db *Database) stateTableExists(ctx context.Context, q queryer, table string) (bool, error) {
+	query := fmt.Sprintf("SELECT 1 FROM %s.sqlite_master WHERE type = 'table' AND name = ?;", quoteIdent(db.stateSchema()))
+	rows, err := q.QueryContext(ctx, query, table)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	exists := rows.Next()
+	return exists, rows.Err()
+}
+
+// stateColumns returns the column names of the bookkeeping table in declaration order.
+func (db *Database) stateColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_table_info(?, ?);", table, db.stateSchema())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make([]string, 0)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return columns, nil
+}
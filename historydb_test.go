@@ -0,0 +1,65 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestHistoryDatabase(t *testing.T) {
+	dir := t.TempDir()
+	conn, err := sql.Open("sqlite3", filepath.Join(dir, "data.db"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	historyPath := filepath.Join(dir, "history.db")
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+	}).SetHistoryDatabase(historyPath)
+
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var tables int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM main.sqlite_master WHERE name LIKE '\\_migrations%' ESCAPE '\\';").Scan(&tables); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("expected no migration tables in the database, got %d", tables)
+	}
+
+	history, err := sql.Open("sqlite3", historyPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer history.Close()
+
+	var records int
+	if err := history.QueryRow("SELECT COUNT(*) FROM _migrations;").Scan(&records); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if records != 2 {
+		t.Errorf("expected 2 records in the history database, got %d", records)
+	}
+
+	if _, err := db.MigrateDown(ctx, 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	status, err := db.Status(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Version != 1 || len(status.Pending) != 1 || status.Dirty {
+		t.Errorf("expected version 1 with one pending migration, got %+v", status)
+	}
+}
@@ -0,0 +1,34 @@
+// Package httpstatus serves the migration status of a database over HTTP, so orchestrators and
+// humans can check the migration health of a running service. It is a separate package to keep
+// net/http out of the core.
+//
+//	http.Handle("/migrations", httpstatus.Handler(db))
+package httpstatus
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// Handler returns an http.Handler that reports the Status of the database as JSON. It responds
+// with 503 Service Unavailable when the migration table is dirty or can't be read, and 200 OK
+// otherwise, even with pending migrations.
+func Handler(db *litemigrate.Database) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		status, err := db.Status(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		if status.Dirty {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+}
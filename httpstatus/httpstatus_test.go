@@ -0,0 +1,64 @@
+package httpstatus_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/httpstatus"
+)
+
+func TestHandler(t *testing.T) {
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	get := func(db *litemigrate.Database) (int, litemigrate.Status) {
+		recorder := httptest.NewRecorder()
+		httpstatus.Handler(db).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/migrations", nil))
+
+		var status litemigrate.Status
+		if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return recorder.Code, status
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users"), tableMigration(2, "posts")})
+	if _, err := db.MigrateUp(context.Background(), litemigrate.WithMaxVersion(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	code, status := get(db)
+	if code != http.StatusOK || status.Version != 1 || status.Dirty {
+		t.Errorf("expected a clean status at version 1, got %d %+v", code, status)
+	}
+	if len(status.Pending) != 1 || status.Pending[0].Version != 2 || status.Pending[0].Description != "Create posts table" {
+		t.Errorf("expected version 2 to be pending, got %+v", status.Pending)
+	}
+
+	code, status = get(litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(2, "posts")}))
+	if code != http.StatusServiceUnavailable || !status.Dirty || len(status.Problems) != 1 {
+		t.Errorf("expected a dirty status, got %d %+v", code, status)
+	}
+}
+
+func tableMigration(version int64, table string) litemigrate.Migration {
+	return litemigrate.Migration{
+		Version:     version,
+		Description: "Create " + table + " table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("CREATE TABLE " + table + " (id INTEGER PRIMARY KEY);")
+			return err
+		},
+	}
+}
@@ -0,0 +1,167 @@
+package litemigrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// HistoryFormat identifies the migration table layout of another migration tool.
+type HistoryFormat int
+
+const (
+	// GolangMigrate is the schema_migrations table of golang-migrate, which stores the current
+	// version and a dirty flag. Every migration up to the current version is considered applied.
+	GolangMigrate HistoryFormat = iota
+	// Goose is the goose_db_version table of goose, which stores a row for every applied and
+	// rolled back migration.
+	Goose
+)
+
+// ImportHistory records the migrations applied by another migration tool in the migration table,
+// without running them, and returns the imported versions. The table defaults to the other tool's
+// default when empty. Versions that are already recorded are skipped, and the import fails if the
+// other tool's history is dirty or contains versions that aren't defined in code.
+func (db *Database) ImportHistory(ctx context.Context, format HistoryFormat, table string) ([]int64, error) {
+	if err := db.migrations.validate(); err != nil {
+		return nil, err
+	}
+
+	tx, release, err := db.beginState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = db.createMigrationTable(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int64
+	switch format {
+	case GolangMigrate:
+		applied, err = db.golangMigrateHistory(ctx, tx, table)
+	case Goose:
+		applied, err = gooseHistory(ctx, tx, table)
+	default:
+		return nil, errorf(CodeUnknownHistoryFormat, "unknown history format: %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := db.getMigrationRecords(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := db.migrations.byVersion()
+	imported := make([]int64, 0)
+	for _, version := range applied {
+		if _, ok := records[version]; ok {
+			continue
+		}
+
+		migration, ok := migrations[version]
+		if !ok {
+			return nil, errorf(CodeUnknownMigration, "migration (version=%v) is applied but doesn't exist", version)
+		}
+
+		if err := db.insertMigration(ctx, tx, migration.Version, migration.Description); err != nil {
+			return nil, err
+		}
+		imported = append(imported, version)
+
+		log.Printf("imported migration (version=%v, description=%s)", migration.Version, migration.Description)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return imported, nil
+}
+
+// golangMigrateHistory returns the versions of the migrations up to golang-migrate's current
+// version.
+func (db *Database) golangMigrateHistory(ctx context.Context, q queryer, table string) ([]int64, error) {
+	if table == "" {
+		table = "schema_migrations"
+	}
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT version, dirty FROM %s LIMIT 1;", quoteIdent(table)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		current int64
+		dirty   bool
+	)
+	if err := rows.Scan(&current, &dirty); err != nil {
+		return nil, err
+	}
+
+	if dirty {
+		return nil, errorf(CodeDirtyHistory, "%s is dirty at version %v: fix the database and clear the flag before importing", table, current)
+	}
+
+	applied := make([]int64, 0)
+	for _, migration := range db.migrations.sorted() {
+		if migration.Version <= current {
+			applied = append(applied, migration.Version)
+		}
+	}
+
+	if len(applied) == 0 || applied[len(applied)-1] != current {
+		return nil, errorf(CodeUnknownMigration, "migration (version=%v) is applied but doesn't exist", current)
+	}
+	return applied, nil
+}
+
+// gooseHistory returns the versions whose latest goose record marks them as applied. Version 0 is
+// goose's own initial record.
+func gooseHistory(ctx context.Context, q queryer, table string) ([]int64, error) {
+	if table == "" {
+		table = "goose_db_version"
+	}
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT version_id, is_applied FROM %s ORDER BY id ASC;", quoteIdent(table)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	state := map[int64]bool{}
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+		if err := rows.Scan(&version, &applied); err != nil {
+			return nil, err
+		}
+		if version != 0 {
+			state[version] = applied
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+
+	applied := make([]int64, 0)
+	for version, ok := range state {
+		if ok {
+			applied = append(applied, version)
+		}
+	}
+	sort.Slice(applied, func(i, j int) bool { return applied[i] < applied[j] })
+	return applied, nil
+}
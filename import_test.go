@@ -0,0 +1,110 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestImportHistory(t *testing.T) {
+	tests := []struct {
+		name   string
+		format litemigrate.HistoryFormat
+		setup  string
+	}{
+		{
+			name:   "golang-migrate",
+			format: litemigrate.GolangMigrate,
+			setup: `
+				CREATE TABLE schema_migrations (version uint64 NOT NULL PRIMARY KEY, dirty bool NOT NULL);
+				INSERT INTO schema_migrations VALUES (2, false);
+			`,
+		},
+		{
+			name:   "goose",
+			format: litemigrate.Goose,
+			setup: `
+				CREATE TABLE goose_db_version (id INTEGER PRIMARY KEY AUTOINCREMENT, version_id INTEGER NOT NULL, is_applied INTEGER NOT NULL, tstamp TIMESTAMP DEFAULT (datetime('now')));
+				INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, 1), (1, 1), (2, 1), (3, 1), (3, 0);
+			`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, err := sql.Open("sqlite3", testDBPath)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			defer conn.Close()
+			conn.SetMaxOpenConns(1)
+
+			if _, err := conn.Exec(test.setup); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+				tableMigration(1, "one"),
+				tableMigration(2, "two"),
+				tableMigration(3, "three"),
+			})
+
+			imported, err := db.ImportHistory(context.Background(), test.format, "")
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(imported) != 2 || imported[0] != 1 || imported[1] != 2 {
+				t.Errorf("expected imported [1 2], got %v", imported)
+			}
+
+			imported, err = db.ImportHistory(context.Background(), test.format, "")
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(imported) != 0 {
+				t.Errorf("expected nothing imported twice, got %v", imported)
+			}
+
+			// Only the third migration is still pending.
+			if _, err := db.MigrateUp(context.Background()); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			version, err := db.CurrentVersion(context.Background())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if version != 3 {
+				t.Errorf("expected version 3, got %d", version)
+			}
+		})
+	}
+}
+
+func TestImportHistoryDirty(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE schema_migrations (version uint64 NOT NULL PRIMARY KEY, dirty bool NOT NULL);
+		INSERT INTO schema_migrations VALUES (1, true);
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one")})
+
+	if _, err := db.ImportHistory(context.Background(), litemigrate.GolangMigrate, ""); err == nil {
+		t.Error("expected error for dirty history, got nil")
+	}
+}
@@ -0,0 +1,50 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SetIntegrityCheck makes migration runs check the database with PRAGMA integrity_check and
+// PRAGMA foreign_key_check before committing. A run fails and is rolled back, or restored from the
+// backup, if the checks report problems.
+func (db *Database) SetIntegrityCheck(enabled bool) *Database {
+	db.integrityCheck = enabled
+	return db
+}
+
+// checkIntegrity fails if PRAGMA integrity_check reports problems. It is a no-op unless the
+// integrity check is enabled.
+func (db *Database) checkIntegrity(ctx context.Context, tx *sql.Tx) error {
+	if !db.integrityCheck {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, "PRAGMA integrity_check;")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	problems := make([]string, 0)
+	for rows.Next() {
+		var problem string
+		if err := rows.Scan(&problem); err != nil {
+			return err
+		}
+		if problem != "ok" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan rows: %w", err)
+	}
+
+	if len(problems) > 0 {
+		return errorf(CodeIntegrityCheckFailed, "integrity check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestIntegrityCheck(t *testing.T) {
+	ctx := context.Background()
+
+	orphan := litemigrate.Migration{
+		Version:     2,
+		Description: "Insert orphaned post",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id));
+				INSERT INTO posts (id, user_id) VALUES (1, 42);
+			`)
+			return err
+		},
+		Down: func(tx *sql.Tx) error { return nil },
+	}
+
+	db, err := litemigrate.New(testDBPath, &litemigrate.Migrations{tableMigration(1, "users"), orphan})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+	db.SetIntegrityCheck(true)
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Foreign keys aren't enforced on the connection, so only the check catches the orphan.
+	_, err = db.MigrateUp(ctx)
+	if litemigrate.Code(err) != litemigrate.CodeForeignKeyViolation {
+		t.Fatalf("expected foreign key violation, got %v", err)
+	}
+
+	if version, _ := db.CurrentVersion(ctx); version != 1 {
+		t.Errorf("expected the failed run to be rolled back to version 1, got %d", version)
+	}
+}
@@ -0,0 +1,124 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Index describes an index of a table as reported by pragma_index_list.
+type Index struct {
+	Name string
+	// Columns are the indexed columns in index order. Expressions are listed as empty strings.
+	Columns []string
+	Unique  bool
+	// Partial reports that the index has a WHERE clause.
+	Partial bool
+}
+
+// Tables returns the names of the user-defined tables in alphabetical order, excluding SQLite's
+// internal tables and the migration tables.
+func (db *Database) Tables(ctx context.Context) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT name
+		FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != ? AND name NOT LIKE ? ESCAPE '\'
+		ORDER BY name;
+	`, db.rootTable(), db.bookkeepingPattern())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make([]string, 0)
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return tables, nil
+}
+
+// Columns returns the columns of a table in declaration order.
+func (db *Database) Columns(ctx context.Context, table string) ([]Column, error) {
+	columns, err := readTableColumns(ctx, db.conn, table)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s doesn't exist", table)
+	}
+	return columns, nil
+}
+
+// Indexes returns the indexes of a table in alphabetical order, including the ones SQLite creates
+// for PRIMARY KEY and UNIQUE constraints.
+func (db *Database) Indexes(ctx context.Context, table string) ([]Index, error) {
+	exists := false
+	err := db.conn.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?;", table).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes of %s: %w", table, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("table %s doesn't exist", table)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, "SELECT name, \"unique\", partial FROM pragma_index_list(?) ORDER BY name;", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	indexes := make([]Index, 0)
+	for rows.Next() {
+		var index Index
+		if err := rows.Scan(&index.Name, &index.Unique, &index.Partial); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	rows.Close()
+
+	// The columns are read once the index list is closed, so it works on a single connection.
+	for i := range indexes {
+		indexes[i].Columns, err = readIndexColumns(ctx, db.conn, indexes[i].Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return indexes, nil
+}
+
+// readIndexColumns returns the columns of an index in index order.
+func readIndexColumns(ctx context.Context, q queryer, index string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_index_info(?) ORDER BY seqno;", index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of index %s: %w", index, err)
+	}
+	defer rows.Close()
+
+	columns := make([]string, 0)
+	for rows.Next() {
+		var column sql.NullString
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column.String)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return columns, nil
+}
@@ -0,0 +1,92 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestIntrospection(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Create users table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE, name TEXT DEFAULT 'anonymous');
+					CREATE INDEX users_name ON users (name, id) WHERE name IS NOT NULL;
+					CREATE TABLE accounts (id INTEGER PRIMARY KEY);
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec("DROP TABLE users; DROP TABLE accounts;")
+				return err
+			},
+		},
+	})
+
+	ctx := context.Background()
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tables, err := db.Tables(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(tables, []string{"accounts", "users"}) {
+		t.Errorf("expected tables accounts and users, got %v", tables)
+	}
+
+	columns, err := db.Columns(ctx, "users")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(columns) != 3 {
+		t.Fatalf("expected 3 columns, got %+v", columns)
+	}
+	if !columns[0].PrimaryKey || columns[0].Type != "INTEGER" {
+		t.Errorf("expected integer primary key id, got %+v", columns[0])
+	}
+	if !columns[1].NotNull || columns[1].Default.Valid {
+		t.Errorf("expected not null email without default, got %+v", columns[1])
+	}
+	if columns[2].Default.String != "'anonymous'" {
+		t.Errorf("expected name to default to 'anonymous', got %+v", columns[2])
+	}
+
+	indexes, err := db.Indexes(ctx, "users")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(indexes) != 2 {
+		t.Fatalf("expected 2 indexes, got %+v", indexes)
+	}
+	if !indexes[0].Unique || !reflect.DeepEqual(indexes[0].Columns, []string{"email"}) {
+		t.Errorf("expected unique index on email, got %+v", indexes[0])
+	}
+	if indexes[1].Name != "users_name" || !indexes[1].Partial || !reflect.DeepEqual(indexes[1].Columns, []string{"name", "id"}) {
+		t.Errorf("expected partial index users_name on name and id, got %+v", indexes[1])
+	}
+
+	if _, err := db.Columns(ctx, "missing"); err == nil {
+		t.Error("expected error for missing table, got nil")
+	}
+	if _, err := db.Indexes(ctx, "missing"); err == nil {
+		t.Error("expected error for missing table, got nil")
+	}
+}
@@ -0,0 +1,97 @@
+package litemigratetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Clone copies the SQLite database file at path into an in-memory database with SQLite's backup
+// API, and closes the copy when the test finishes. Tests can start from a realistic migrated and
+// seeded state, prepared once, without rebuilding it or modifying the file. Like MustMigrate's
+// database, the copy is limited to one connection.
+func Clone(t testing.TB, path string) *sql.DB {
+	t.Helper()
+
+	conn := open(t)
+	if err := backup(context.Background(), conn, path); err != nil {
+		t.Fatalf("failed to clone %s: %v", path, err)
+	}
+	return conn
+}
+
+// CloneFile copies the SQLite database file at path into a file in a temporary directory, for code
+// under test that needs more than one connection or reopens the database. It returns the
+// connection to the copy, which is closed when the test finishes, and the path of the copy.
+func CloneFile(t testing.TB, path string) (*sql.DB, string) {
+	t.Helper()
+
+	clone := filepath.Join(t.TempDir(), filepath.Base(path))
+	conn, err := sql.Open("sqlite3", clone)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := backup(context.Background(), conn, path); err != nil {
+		t.Fatalf("failed to clone %s: %v", path, err)
+	}
+	return conn, clone
+}
+
+var errUnsupportedDriver = errors.New("backup requires the github.com/mattn/go-sqlite3 driver")
+
+// backup copies the database file at path into dst.
+func backup(ctx context.Context, dst *sql.DB, path string) error {
+	// Opening a missing file would create an empty database instead of failing.
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	src, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	return dstConn.Raw(func(dstDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			to, ok := dstDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errUnsupportedDriver
+			}
+			from, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errUnsupportedDriver
+			}
+
+			b, err := to.Backup("main", from, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := b.Step(-1); err != nil {
+				b.Finish()
+				return fmt.Errorf("failed to copy pages: %w", err)
+			}
+			return b.Finish()
+		})
+	})
+}
@@ -0,0 +1,50 @@
+package litemigratetest_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate/litemigratetest"
+)
+
+func TestClone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seed.db")
+	seed, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer seed.Close()
+
+	if _, err := seed.Exec("CREATE TABLE users (name TEXT); INSERT INTO users VALUES ('ada');"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	memory := litemigratetest.Clone(t, path)
+	file, clonePath := litemigratetest.CloneFile(t, path)
+	if clonePath == path {
+		t.Fatal("expected the clone to be a different file")
+	}
+
+	for name, conn := range map[string]*sql.DB{"memory": memory, "file": file} {
+		if _, err := conn.Exec("INSERT INTO users VALUES ('grace');"); err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+
+		rows := 0
+		if err := conn.QueryRow("SELECT COUNT(*) FROM users;").Scan(&rows); err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if rows != 2 {
+			t.Errorf("%s: expected 2 rows, got %d", name, rows)
+		}
+	}
+
+	rows := 0
+	if err := seed.QueryRow("SELECT COUNT(*) FROM users;").Scan(&rows); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rows != 1 {
+		t.Errorf("expected the original to be untouched, got %d rows", rows)
+	}
+}
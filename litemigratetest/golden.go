@@ -0,0 +1,94 @@
+package litemigratetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertGoldenSchema write the golden file
+// instead of comparing against it, e.g. LITEMIGRATE_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "LITEMIGRATE_UPDATE_GOLDEN"
+
+// AssertGoldenSchema applies the migrations to an in-memory database and compares the schema, as
+// written by DumpSchema, with the golden file at path. The test fails with a line diff when the
+// migrations change the schema unexpectedly. A missing golden file is created, as is every golden
+// file when UpdateGoldenEnv is set.
+func AssertGoldenSchema(t testing.TB, migrations *litemigrate.Migrations, path string) {
+	t.Helper()
+
+	conn := MustMigrate(t, migrations)
+
+	var dump bytes.Buffer
+	if err := litemigrate.NewWithConn(conn, migrations).DumpSchema(context.Background(), &dump); err != nil {
+		t.Fatalf("failed to dump schema: %v", err)
+	}
+
+	golden, err := os.ReadFile(path)
+	if os.Getenv(UpdateGoldenEnv) != "" || errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		if err := os.WriteFile(path, dump.Bytes(), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	if !bytes.Equal(golden, dump.Bytes()) {
+		t.Fatalf("schema doesn't match %s (set %s=1 to update it):\n%s", path, UpdateGoldenEnv, lineDiff(string(golden), dump.String()))
+	}
+}
+
+// lineDiff returns the lines removed from want with a "-" prefix and the lines added in got with a
+// "+" prefix, based on their longest common subsequence.
+func lineDiff(want, got string) string {
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff.WriteString("  " + a[i] + "\n")
+			i, j = i+1, j+1
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			diff.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return diff.String()
+}
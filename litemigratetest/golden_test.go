@@ -0,0 +1,51 @@
+package litemigratetest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/litemigratetest"
+)
+
+func TestAssertGoldenSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "schema.sql")
+	migrations := &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Create users table",
+			Up:          exec(`CREATE TABLE users (id INTEGER PRIMARY KEY);`),
+			Down:        exec(`DROP TABLE users;`),
+		},
+	}
+
+	litemigratetest.AssertGoldenSchema(t, migrations, path)
+
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if string(golden) != "CREATE TABLE users (id INTEGER PRIMARY KEY);\n" {
+		t.Errorf("unexpected golden file: %q", golden)
+	}
+
+	litemigratetest.AssertGoldenSchema(t, migrations, path)
+
+	*migrations = append(*migrations, litemigrate.Migration{
+		Version:     2,
+		Description: "Create posts table",
+		Up:          exec(`CREATE TABLE posts (id INTEGER PRIMARY KEY);`),
+		Down:        exec(`DROP TABLE posts;`),
+	})
+
+	failure := fatal(t, func(tb testing.TB) {
+		litemigratetest.AssertGoldenSchema(tb, migrations, path)
+	})
+
+	if !strings.Contains(failure, "+ CREATE TABLE posts (id INTEGER PRIMARY KEY);\n  CREATE TABLE users") {
+		t.Errorf("expected diff adding posts, got %q", failure)
+	}
+}
@@ -0,0 +1,68 @@
+// Package litemigratetest provides helpers for tests of code that uses migrated databases.
+package litemigratetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// MustMigrate returns an in-memory database with the migrations applied, and closes it when the
+// test finishes. The database is limited to one connection, since every connection to ":memory:"
+// opens a separate database. If a migration fails, the test fails with the error and the
+// migrations that were applied before it.
+func MustMigrate(t testing.TB, migrations *litemigrate.Migrations) *sql.DB {
+	t.Helper()
+
+	conn := open(t)
+	db := litemigrate.NewWithConn(conn, migrations)
+
+	// Apply the migrations one at a time, so a failure shows the ones that succeeded.
+	applied := make([]string, 0)
+	for _, migration := range sorted(migrations) {
+		if _, err := db.MigrateUp(context.Background(), litemigrate.WithMaxVersion(migration.Version)); err != nil {
+			t.Fatalf("failed to migrate up to (version=%v, description=%s): %v\napplied before it:\n%s",
+				migration.Version, migration.Description, err, diagnostics(applied))
+		}
+		applied = append(applied, fmt.Sprintf("  %v %s", migration.Version, migration.Description))
+	}
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return conn
+}
+
+// open returns an in-memory database that is closed when the test finishes.
+func open(t testing.TB) *sql.DB {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func sorted(migrations *litemigrate.Migrations) litemigrate.Migrations {
+	sorted := make(litemigrate.Migrations, len(*migrations))
+	copy(sorted, *migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+func diagnostics(applied []string) string {
+	if len(applied) == 0 {
+		return "  none"
+	}
+	return strings.Join(applied, "\n")
+}
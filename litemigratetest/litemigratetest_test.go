@@ -0,0 +1,42 @@
+package litemigratetest_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/litemigratetest"
+)
+
+func TestMustMigrate(t *testing.T) {
+	conn := litemigratetest.MustMigrate(t, &litemigrate.Migrations{
+		{
+			Version:     2,
+			Description: "Add users email",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE users ADD COLUMN email TEXT;`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE users DROP COLUMN email;`)
+				return err
+			},
+		},
+		{
+			Version:     1,
+			Description: "Create users table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY);`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE users;`)
+				return err
+			},
+		},
+	})
+
+	if _, err := conn.Exec(`INSERT INTO users (email) VALUES ('a@example.com');`); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package litemigratetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// VerifyRoundTrip applies the migrations one at a time to an in-memory database, rolling each one
+// back and applying it again. The test fails if rolling a migration back doesn't restore the
+// schema from before it, or if applying it again doesn't produce the same schema as the first
+// time, which catches Down functions that don't reverse their Up. Irreversible migrations are only
+// applied.
+func VerifyRoundTrip(t testing.TB, migrations *litemigrate.Migrations) {
+	t.Helper()
+
+	ctx := context.Background()
+	conn := open(t)
+	db := litemigrate.NewWithConn(conn, migrations)
+
+	for _, migration := range sorted(migrations) {
+		before := mustSnapshot(t, conn, db.MigrationTable())
+
+		if _, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(migration.Version)); err != nil {
+			t.Fatalf("failed to migrate up (version=%v, description=%s): %v", migration.Version, migration.Description, err)
+		}
+		after := mustSnapshot(t, conn, db.MigrationTable())
+
+		if migration.Down == nil {
+			continue
+		}
+
+		if _, err := db.MigrateDown(ctx, 1); err != nil {
+			t.Fatalf("failed to migrate down (version=%v, description=%s): %v", migration.Version, migration.Description, err)
+		}
+
+		if reverted := mustSnapshot(t, conn, db.MigrationTable()); reverted != before {
+			t.Fatalf("down doesn't reverse up (version=%v, description=%s):\nbefore up:\n%s\nafter down:\n%s",
+				migration.Version, migration.Description, before, reverted)
+		}
+
+		if _, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(migration.Version)); err != nil {
+			t.Fatalf("failed to migrate up again (version=%v, description=%s): %v", migration.Version, migration.Description, err)
+		}
+
+		if reapplied := mustSnapshot(t, conn, db.MigrationTable()); reapplied != after {
+			t.Fatalf("up isn't repeatable after down (version=%v, description=%s):\nfirst up:\n%s\nsecond up:\n%s",
+				migration.Version, migration.Description, after, reapplied)
+		}
+	}
+}
+
+func mustSnapshot(t testing.TB, conn *sql.DB, migrationTable string) string {
+	t.Helper()
+
+	schema, err := snapshot(conn, migrationTable)
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	return schema
+}
+
+// snapshot describes the schema, one object per line. Tables are described by their columns,
+// since SQLite edits their CREATE statements in place when columns are added or dropped.
+func snapshot(conn *sql.DB, migrationTable string) (string, error) {
+	rows, err := conn.Query(`
+		SELECT type, name, COALESCE(sql, '') FROM sqlite_master
+		WHERE name NOT LIKE 'sqlite_%' AND name != ? AND tbl_name != ?
+		ORDER BY type, name;`, migrationTable, migrationTable)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0)
+	tables := make([]string, 0)
+	for rows.Next() {
+		var kind, name, sql string
+		if err := rows.Scan(&kind, &name, &sql); err != nil {
+			rows.Close()
+			return "", err
+		}
+
+		if kind == "table" {
+			tables = append(tables, name)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s: %s", kind, name, strings.Join(strings.Fields(sql), " ")))
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	for _, table := range tables {
+		columns, err := describeColumns(conn, table)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf("table %s: %s", table, columns))
+	}
+
+	sort.Strings(lines)
+	return strings.Join(lines, "\n"), nil
+}
+
+func describeColumns(conn *sql.DB, table string) (string, error) {
+	rows, err := conn.Query("SELECT name, type, \"notnull\", COALESCE(dflt_value, ''), pk FROM pragma_table_info(?);", table)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns := make([]string, 0)
+	for rows.Next() {
+		var (
+			name, kind, dflt string
+			notNull, pk      int
+		)
+		if err := rows.Scan(&name, &kind, &notNull, &dflt, &pk); err != nil {
+			return "", err
+		}
+		columns = append(columns, fmt.Sprintf("%s %s notnull=%d default=%s pk=%d", name, kind, notNull, dflt, pk))
+	}
+	return strings.Join(columns, ", "), rows.Err()
+}
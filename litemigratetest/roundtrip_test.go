@@ -0,0 +1,77 @@
+package litemigratetest_test
+
+import (
+	"database/sql"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/litemigratetest"
+)
+
+// recorder records a fatal failure instead of failing the test.
+type recorder struct {
+	testing.TB
+	failure string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failure = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// fatal runs f and returns the message of the fatal failure it reports, if any.
+func fatal(t *testing.T, f func(tb testing.TB)) string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(r)
+	}()
+	<-done
+	return r.failure
+}
+
+func exec(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	migrations := &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Create users table",
+			Up:          exec(`CREATE TABLE users (id INTEGER PRIMARY KEY);`),
+			Down:        exec(`DROP TABLE users;`),
+		},
+		{
+			Version:     2,
+			Description: "Add users email",
+			Up:          exec(`ALTER TABLE users ADD COLUMN email TEXT; CREATE INDEX users_email ON users (email);`),
+			Down:        exec(`DROP INDEX users_email; ALTER TABLE users DROP COLUMN email;`),
+		},
+	}
+	litemigratetest.VerifyRoundTrip(t, migrations)
+
+	*migrations = append(*migrations, litemigrate.Migration{
+		Version:     3,
+		Description: "Add users name",
+		Up:          exec(`ALTER TABLE users ADD COLUMN name TEXT;`),
+		Down:        exec(`SELECT 1;`),
+	})
+
+	failure := fatal(t, func(tb testing.TB) {
+		litemigratetest.VerifyRoundTrip(tb, migrations)
+	})
+
+	if !strings.Contains(failure, "down doesn't reverse up (version=3") {
+		t.Errorf("expected failure for version 3, got %q", failure)
+	}
+}
@@ -0,0 +1,60 @@
+package litemigrate
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// SetLockFile makes MigrateUp and MigrateDown hold an exclusive OS-level lock on a file for the
+// whole run, typically a sidecar file next to the database such as "app.db.lock". The lock
+// serializes migrators across connections and processes, including the backup, confirmation and
+// shadow verification around the transaction, which the database's own locks don't cover. A run
+// waits for the lock until its context is done, and then fails with CodeLocked. File locks are only
+// supported on Unix systems.
+func (db *Database) SetLockFile(path string) *Database {
+	db.lockFile = path
+	return db
+}
+
+// lock takes the lock file, if one is set, and returns the function that releases it.
+func (db *Database) lock(ctx context.Context) (func(), error) {
+	if db.lockFile == "" {
+		return func() {}, nil
+	}
+
+	f, err := os.OpenFile(db.lockFile, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, errorf(CodeLocked, "failed to open lock file: %w", err)
+	}
+
+	waiting := false
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, errorf(CodeLocked, "failed to lock %s: %w", db.lockFile, err)
+		}
+		if locked {
+			break
+		}
+
+		if !waiting {
+			log.Printf("waiting for lock file (path=%s)", db.lockFile)
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, errorf(CodeLocked, "failed to lock %s: %w", db.lockFile, ctx.Err())
+		case <-time.After(awaitInterval):
+		}
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
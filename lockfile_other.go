@@ -0,0 +1,16 @@
+//go:build !unix
+
+package litemigrate
+
+import (
+	"errors"
+	"os"
+)
+
+func tryLockFile(f *os.File) (bool, error) {
+	return false, errors.New("file locks aren't supported on this platform")
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
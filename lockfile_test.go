@@ -0,0 +1,74 @@
+//go:build unix
+
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestLockFile(t *testing.T) {
+	dir := t.TempDir()
+	dsn := filepath.Join(dir, "app.db")
+	lockFile := filepath.Join(dir, "app.db.lock")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := tableMigration(1, "users")
+	up := blocking.Up
+	blocking.Up = func(tx *sql.Tx) error {
+		close(started)
+		<-release
+		return up(tx)
+	}
+
+	first, err := litemigrate.New(dsn, &litemigrate.Migrations{blocking})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer first.Close()
+	first.SetLockFile(lockFile)
+
+	done := make(chan error)
+	go func() {
+		_, err := first.MigrateUp(context.Background())
+		done <- err
+	}()
+	<-started
+
+	second, err := litemigrate.New(dsn, &litemigrate.Migrations{tableMigration(1, "users")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer second.Close()
+	second.SetLockFile(lockFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := second.MigrateUp(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the run to wait for the lock file, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		close(release)
+	}()
+
+	result, err := second.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Applied) != 0 {
+		t.Errorf("expected the waiting run to apply nothing, got %v", result.Applied)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package litemigrate
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Maintenance is a set of maintenance steps run after a migration run.
+type Maintenance int
+
+const (
+	// Analyze runs ANALYZE, so the query planner's statistics reflect the changed schema and data.
+	Analyze Maintenance = 1 << iota
+	// Vacuum runs VACUUM to rebuild the database file without the space freed by the run.
+	Vacuum
+	// CheckpointWAL runs PRAGMA wal_checkpoint(TRUNCATE), so the write-ahead log doesn't stay large
+	// after data migrations. This matters for backup tools like Litestream that ship the log.
+	CheckpointWAL
+)
+
+// SetMaintenance sets the maintenance steps run after every migration run that committed changes,
+// e.g. Analyze|Vacuum. The migrations are already committed when they run, so a failing step is
+// reported as a WarningMaintenanceFailed instead of failing the run.
+func (db *Database) SetMaintenance(maintenance Maintenance) *Database {
+	db.maintenance = maintenance
+	return db
+}
+
+// maintain runs the maintenance steps after a committed run.
+func (db *Database) maintain(ctx context.Context, result *Result) {
+	if db.maintenance == 0 || result.DryRun || len(result.Applied)+len(result.RolledBack) == 0 {
+		return
+	}
+
+	steps := []struct {
+		step  Maintenance
+		query string
+	}{
+		{Analyze, "ANALYZE;"},
+		{Vacuum, "VACUUM;"},
+		{CheckpointWAL, "PRAGMA wal_checkpoint(TRUNCATE);"},
+	}
+
+	for _, s := range steps {
+		if db.maintenance&s.step == 0 {
+			continue
+		}
+
+		start := time.Now()
+		if err := db.runMaintenance(ctx, s.step, s.query); err != nil {
+			db.warn(result, WarningMaintenanceFailed, result.Version, "%s failed: %v", s.query, err)
+			continue
+		}
+		log.Printf("ran %s after migration run (duration=%s)", s.query, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+func (db *Database) runMaintenance(ctx context.Context, step Maintenance, query string) error {
+	if step != CheckpointWAL {
+		_, err := db.conn.ExecContext(ctx, query)
+		return err
+	}
+
+	// The checkpoint reports whether it was blocked by readers or writers instead of failing. It
+	// is a no-op for databases that don't use WAL mode.
+	var busy, frames, checkpointed int
+	if err := db.conn.QueryRowContext(ctx, query).Scan(&busy, &frames, &checkpointed); err != nil {
+		return err
+	}
+	if busy != 0 {
+		return errorf(CodeLocked, "checkpoint was blocked (frames=%d, checkpointed=%d)", frames, checkpointed)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestMaintenance(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	indexed := litemigrate.Migration{
+		Version:     1,
+		Description: "Create indexed users table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+				CREATE INDEX users_name ON users (name);
+				INSERT INTO users (name) VALUES ('alice'), ('bob');
+			`)
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec("DROP TABLE users;")
+			return err
+		},
+	}
+
+	warnings := make([]litemigrate.Warning, 0)
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{indexed}).
+		SetMaintenance(litemigrate.Analyze | litemigrate.Vacuum).
+		SetWarningHandler(func(w litemigrate.Warning) { warnings = append(warnings, w) })
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stats := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_stat1 WHERE tbl = 'users';").Scan(&stats); err != nil {
+		t.Fatalf("expected statistics after ANALYZE, got %v", err)
+	}
+	if stats == 0 {
+		t.Error("expected statistics for the users table")
+	}
+
+	if _, err := db.MigrateDown(context.Background(), 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+}
+
+func TestCheckpointWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+
+	conn, err := sql.Open("sqlite3", litemigrate.MigrationDSN(path).String())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	warnings := make([]litemigrate.Warning, 0)
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users")}).
+		SetMaintenance(litemigrate.CheckpointWAL).
+		SetWarningHandler(func(w litemigrate.Warning) { warnings = append(warnings, w) })
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+
+	info, err := os.Stat(path + "-wal")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("expected the write-ahead log to be truncated, got %d bytes", info.Size())
+	}
+}
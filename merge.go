@@ -0,0 +1,25 @@
+package litemigrate
+
+// Merge combines the migration sets of several packages into a single set ordered by version. It
+// fails if two sets define the same version, naming both migrations, so collisions are caught when
+// the sets are combined rather than when the database is migrated.
+func Merge(sets ...Migrations) (Migrations, error) {
+	type origin struct {
+		set         int
+		description string
+	}
+
+	merged := make(Migrations, 0)
+	versions := map[int64]origin{}
+	for i, set := range sets {
+		for _, migration := range set {
+			if existing, ok := versions[migration.Version]; ok {
+				return nil, errorf(CodeDuplicateVersion, "duplicate migration: (version=%v) is defined by set %d (description=%s) and set %d (description=%s)",
+					migration.Version, existing.set, existing.description, i, migration.Description)
+			}
+			versions[migration.Version] = origin{set: i, description: migration.Description}
+			merged = append(merged, migration)
+		}
+	}
+	return merged.sorted(), nil
+}
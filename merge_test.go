@@ -0,0 +1,26 @@
+package litemigrate_test
+
+import (
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestMerge(t *testing.T) {
+	users := litemigrate.Migrations{tableMigration(3, "users"), tableMigration(1, "accounts")}
+	billing := litemigrate.Migrations{tableMigration(2, "invoices")}
+
+	merged, err := litemigrate.Merge(users, billing)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(merged) != 3 || merged[0].Version != 1 || merged[1].Version != 2 || merged[2].Version != 3 {
+		t.Fatalf("expected versions 1, 2 and 3 in order, got %v", merged)
+	}
+
+	_, err = litemigrate.Merge(users, litemigrate.Migrations{tableMigration(3, "payments")})
+	if litemigrate.Code(err) != litemigrate.CodeDuplicateVersion {
+		t.Fatalf("expected duplicate version error, got %v", err)
+	}
+}
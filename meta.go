@@ -0,0 +1,70 @@
+package litemigrate
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultMigrationTable is the name of the migration table unless set with SetMigrationTable.
+const DefaultMigrationTable = "_migrations"
+
+// Columns of the migration table. Rows are ordered by ColumnID in the order the migrations were
+// applied. ColumnDurationMS holds the execution time in milliseconds, and is NULL for migrations
+// that were recorded without running them. ColumnAppliedAt holds the time the migration was
+// recorded in RFC 3339 format, and ColumnAppliedHost, ColumnAppliedUser, ColumnAppVersion and
+// ColumnApprovedBy the AppliedBy metadata, and ColumnDatabase the name of the database the
+// migration targets. ColumnStatus tells applied migrations from the ones runs intentionally
+// skipped, and ColumnChecksum holds the Checksum of the migration when it was applied. The
+// metadata columns are NULL for migrations recorded by earlier versions. ColumnPrevHash and
+// ColumnHash only exist when the hash chain is enabled.
+const (
+	ColumnID          = "id"
+	ColumnVersion     = "version"
+	ColumnDescription = "description"
+	ColumnDurationMS  = "duration_ms"
+	ColumnAppliedAt   = "applied_at"
+	ColumnAppliedHost = "applied_host"
+	ColumnAppliedUser = "applied_user"
+	ColumnAppVersion  = "app_version"
+	ColumnApprovedBy  = "approved_by"
+	ColumnDatabase    = "database_name"
+	ColumnStatus      = "status"
+	ColumnChecksum    = "checksum"
+	ColumnPrevHash    = "prev_hash"
+	ColumnHash        = "hash"
+)
+
+// Statuses of the records of the migration table. StatusExcluded records a pending migration that
+// a run skipped because its version was excluded, see Database.SetExcludedVersions. It is replaced
+// by a StatusApplied record once the migration is applied. Records without a status are applied.
+const (
+	StatusApplied  = "applied"
+	StatusExcluded = "excluded"
+)
+
+// MigrationTable returns the name of the migration table.
+func (db *Database) MigrationTable() string {
+	return db.migrationTable
+}
+
+// HistoryQuery returns a query selecting the id, version and description of every applied
+// migration from the given migration table, in the order they were applied. It lets reporting
+// tools read the migration state without depending on the table layout. Tables created by earlier
+// versions get ColumnStatus, which the query relies on, on the next run.
+func HistoryQuery(table string) string {
+	return fmt.Sprintf("SELECT %s, %s, %s FROM %s WHERE COALESCE(%s, '%s') = '%s' ORDER BY %s ASC;",
+		ColumnID, ColumnVersion, ColumnDescription, quoteIdent(table), ColumnStatus, StatusApplied, StatusApplied, ColumnID)
+}
+
+// History returns the records of the migration table in the order the migrations were applied,
+// including the records of excluded migrations, see HistoryRecord.Status. It returns no records if
+// the table doesn't exist yet.
+func (db *Database) History(ctx context.Context) ([]HistoryRecord, error) {
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return db.readHistory(ctx, q)
+}
@@ -0,0 +1,142 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestHistory(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(2, "two"),
+		tableMigration(1, "one"),
+	})
+
+	records, err := db.History(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(records) != 0 {
+		t.Errorf("expected no records before migrating, got %v", records)
+	}
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	records, err = db.History(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(records) != 2 || records[0].Version != 1 || records[1].Description != "Create two table" {
+		t.Errorf("expected records for versions 1 and 2, got %v", records)
+	}
+
+	if db.MigrationTable() != litemigrate.DefaultMigrationTable {
+		t.Errorf("expected migration table %s, got %s", litemigrate.DefaultMigrationTable, db.MigrationTable())
+	}
+
+	rows, err := conn.Query(litemigrate.HistoryQuery(db.MigrationTable()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer rows.Close()
+
+	versions := make([]int64, 0)
+	for rows.Next() {
+		var record litemigrate.HistoryRecord
+		if err := rows.Scan(&record.ID, &record.Version, &record.Description); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		versions = append(versions, record.Version)
+	}
+
+	if len(versions) != 2 || versions[0] != 1 || versions[1] != 2 {
+		t.Errorf("expected versions [1 2], got %v", versions)
+	}
+}
+
+func TestHistoryDuration(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	// A migration table created before the duration column existed.
+	_, err = conn.Exec(`
+		CREATE TABLE _migrations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			version INTEGER UNIQUE NOT NULL,
+			description VARCHAR(255) UNIQUE NOT NULL
+		);
+		INSERT INTO _migrations (version, description) VALUES (1, 'Create one table');
+		CREATE TABLE one (id INTEGER PRIMARY KEY);
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	slow := tableMigration(2, "two")
+	up := slow.Up
+	slow.Up = func(tx *sql.Tx) error {
+		time.Sleep(20 * time.Millisecond)
+		return up(tx)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one"), slow})
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	records, err := db.History(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(records) != 2 || records[0].Duration != 0 || records[1].Duration < 20*time.Millisecond {
+		t.Errorf("expected no duration for version 1 and at least 20ms for version 2, got %v", records)
+	}
+}
+
+func TestHistoryAppliedBy(t *testing.T) {
+	db, err := litemigrate.New(testDBPath, &litemigrate.Migrations{tableMigration(1, "one")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	appliedBy := litemigrate.AppliedBy{Host: "ci-runner", User: "deploy", AppVersion: "1.2.3"}
+	db.SetAppliedBy(appliedBy)
+
+	before := time.Now()
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	records, err := db.History(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(records) != 1 || records[0].AppliedBy != appliedBy {
+		t.Fatalf("expected a record applied by %v, got %v", appliedBy, records)
+	}
+
+	if records[0].AppliedAt.Before(before.Add(-time.Second)) || records[0].AppliedAt.After(time.Now()) {
+		t.Errorf("expected the record to be applied during the run, got %v", records[0].AppliedAt)
+	}
+}
@@ -0,0 +1,49 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Direction is the direction a migration is run in.
+type Direction string
+
+const (
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+// Step is a single migration run in one direction.
+type Step struct {
+	Migration Migration
+	Direction Direction
+}
+
+// Runner runs a migration step inside the transaction of a migration run.
+type Runner func(ctx context.Context, tx *sql.Tx, step Step) error
+
+// Middleware wraps a Runner, e.g. to add logging, metrics, retries or policy checks. It calls
+// next to run the step, or returns an error to stop the run.
+type Middleware func(next Runner) Runner
+
+// Use adds middleware wrapping every migration run by MigrateUp and MigrateDown, and by the
+// rolled back run of Plan. The first middleware is the outermost one.
+func (db *Database) Use(middleware ...Middleware) *Database {
+	db.middleware = append(db.middleware, middleware...)
+	return db
+}
+
+// runner returns the Runner that runs a step through the middleware.
+func (db *Database) runner() Runner {
+	run := Runner(func(ctx context.Context, tx *sql.Tx, step Step) error {
+		if step.Direction == Down {
+			return step.Migration.Down(tx)
+		}
+		return step.Migration.Up(tx)
+	})
+
+	for i := len(db.middleware) - 1; i >= 0; i-- {
+		run = db.middleware[i](run)
+	}
+	return run
+}
@@ -0,0 +1,60 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestMiddleware(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	calls := make([]string, 0)
+	trace := func(name string) litemigrate.Middleware {
+		return func(next litemigrate.Runner) litemigrate.Runner {
+			return func(ctx context.Context, tx *sql.Tx, step litemigrate.Step) error {
+				calls = append(calls, fmt.Sprintf("%s %s %d", name, step.Direction, step.Migration.Version))
+				return next(ctx, tx, step)
+			}
+		}
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+	}).Use(trace("outer"), trace("inner"))
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := db.MigrateDown(context.Background(), 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "outer up 1, inner up 1, outer up 2, inner up 2, outer down 2, inner down 2"
+	if strings.Join(calls, ", ") != expected {
+		t.Errorf("expected calls %q, got %q", expected, strings.Join(calls, ", "))
+	}
+
+	denied := errors.New("denied")
+	db.Use(func(next litemigrate.Runner) litemigrate.Runner {
+		return func(ctx context.Context, tx *sql.Tx, step litemigrate.Step) error {
+			return denied
+		}
+	})
+
+	if _, err := db.MigrateUp(context.Background()); !errors.Is(err, denied) {
+		t.Errorf("expected error %v, got %v", denied, err)
+	}
+}
@@ -3,27 +3,82 @@ package litemigrate
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
-
-	"golang.org/x/exp/slices"
-
-	_ "github.com/mattn/go-sqlite3"
+	"strings"
+	"time"
 )
 
 // Migration represents a database migration with a version, description, up and down functions.
 type Migration struct {
-	Version     uint
+	Version     int64
 	Description string
 	Up          func(tx *sql.Tx) error
-	Down        func(tx *sql.Tx) error
+	// Down reverses Up. Migrations without one are irreversible, and rolling them back fails
+	// with ErrIrreversible.
+	Down func(tx *sql.Tx) error
+	// Role is the role required to run the migration, such as "ddl", "data" or "destructive".
+	// Migrations without a role can always run. See Database.SetAllowedRoles.
+	Role string
+	// Objects are the names of the tables, indexes, views or triggers the migration creates, and
+	// Applied reports whether the migration's changes are already present. They are only used when
+	// adopting existing databases. See Database.SetAdoptExisting.
+	Objects []string
+	Applied func(tx *sql.Tx) (bool, error)
+	// Tags group migrations, so subsets such as heavy data backfills can be run separately. See
+	// WithTags and WithoutTags.
+	Tags []string
+	// Condition decides at runtime whether the migration applies, e.g. only when a legacy table
+	// exists. A migration whose condition isn't met is neither run nor recorded, so the condition
+	// is evaluated again on every run until it is met.
+	Condition func(ctx context.Context, tx *sql.Tx) (bool, error)
+	// Precondition is checked before the migration runs, e.g. that a table exists or has fewer
+	// than a million rows, and aborts the run with its error if it isn't met. Unlike Condition, an
+	// unmet precondition is an error. See TableExists and MaxRows.
+	Precondition func(ctx context.Context, tx *sql.Tx) error
+	// Verify is called after Up succeeds, inside the same transaction, to assert invariants of the
+	// schema or data, e.g. that a backfill left no NULL values. The run is rolled back if it fails.
+	Verify func(ctx context.Context, tx *sql.Tx) error
+	// Timeout is the time the migration may take in either direction before it is aborted. It
+	// overrides the default set with Database.SetMigrationTimeout.
+	Timeout time.Duration
+	// DestructiveStatements lists the statements of the migration's Up function that destroy data,
+	// such as "DROP TABLE users". SQL migrations set it from their statements. Migrations with
+	// destructive statements only run when allowed, see Database.SetAllowDestructive.
+	DestructiveStatements []string
+	// NonTransactionalStatements lists the statements of the migration that can't run inside a
+	// transaction, such as VACUUM. SQL migrations set it from their statements. Since migrations
+	// always run inside a transaction, migrations with such statements fail validation.
+	NonTransactionalStatements []string
+	// DependsOn lists the versions that must be applied before the migration, regardless of their
+	// version numbers, so migrations written in parallel branches can express real dependencies.
+	// Migrations are applied in version order as far as their dependencies allow.
+	DependsOn []int64
+	// Phase is the phase of the migration in an expand/contract deployment. It defaults to Expand.
+	// See WithPhase.
+	Phase Phase
+	// PostDeploy marks a migration that MigrateUp skips and MigratePostDeploy applies, e.g. a long
+	// backfill that should run after the new application code is live.
+	PostDeploy bool
+	// MinAppVersion is the oldest application version, such as "1.4.0", that may apply the
+	// migration. Runs of older versions defer it. See WithAppVersion.
+	MinAppVersion string
+	// Database is the name of the attached database the migration targets, see Database.Attach.
+	// It defaults to "main" and is recorded with the migration. Statements still have to qualify
+	// the objects of attached databases, e.g. analytics.events.
+	Database string
+	// Checksum identifies the content of the migration, e.g. a hash of its SQL. SQL migrations set
+	// it from their statements. It is recorded when the migration is applied, and Validate reports
+	// applied migrations whose checksum changed since.
+	Checksum string
 }
 
 // Migrations is a slice of Migration.
 type Migrations []Migration
 
-// Sorted returns a sorted slice of migrations based on their versions.
+// Sorted returns a sorted slice of migrations based on their versions and dependencies.
 func (ms *Migrations) sorted() []Migration {
 	sortedMigrations := make([]Migration, len(*ms))
 	copy(sortedMigrations, *ms)
@@ -31,17 +86,95 @@ func (ms *Migrations) sorted() []Migration {
 	sort.Slice(sortedMigrations, func(i, j int) bool {
 		return sortedMigrations[i].Version < sortedMigrations[j].Version
 	})
-	return sortedMigrations
+	return topological(sortedMigrations)
+}
+
+// byVersion returns the migrations keyed by their versions.
+func (ms *Migrations) byVersion() map[int64]Migration {
+	migrations := make(map[int64]Migration, len(*ms))
+	for _, migration := range *ms {
+		migrations[migration.Version] = migration
+	}
+	return migrations
+}
+
+// validate checks that every migration is well-formed and that no version is used twice.
+func (ms *Migrations) validate() error {
+	migrationExists := map[int64]bool{}
+	for _, migration := range ms.sorted() {
+		if migration.Version == 0 || migration.Description == "" {
+			return errorf(CodeInvalidMigration, "invalid migration: version and description must be set")
+		}
+
+		if migration.Version < 0 {
+			return errorf(CodeInvalidMigration, "invalid migration: (version=%v, description=%s) version must be positive", migration.Version, migration.Description)
+		}
+
+		if migration.Up == nil {
+			return errorf(CodeInvalidMigration, "invalid migration: up must be set")
+		}
+
+		if migration.Phase != "" && migration.Phase != Expand && migration.Phase != Contract {
+			return errorf(CodeInvalidMigration, "invalid migration: (version=%v, description=%s) has unknown phase %s", migration.Version, migration.Description, migration.Phase)
+		}
+
+		if migration.MinAppVersion != "" {
+			if _, _, err := parseVersion(migration.MinAppVersion); err != nil {
+				return errorf(CodeInvalidMigration, "invalid migration: (version=%v, description=%s) has invalid minimum application version: %w", migration.Version, migration.Description, err)
+			}
+		}
+
+		if len(migration.NonTransactionalStatements) > 0 {
+			return errorf(CodeInvalidMigration, "invalid migration: (version=%v, description=%s) can't run inside a transaction: %s", migration.Version, migration.Description, strings.Join(migration.NonTransactionalStatements, "; "))
+		}
+
+		if migrationExists[migration.Version] {
+			return errorf(CodeDuplicateVersion, "duplicate migration: (version=%v, description=%s) already exists", migration.Version, migration.Description)
+		}
+		migrationExists[migration.Version] = true
+	}
+	return ms.validateDependencies()
 }
 
 // Database represents a database connection and migration data.
 type Database struct {
-	conn           *sql.DB
-	migrationTable string
-	migrations     *Migrations
+	conn               *sql.DB
+	migrationTable     string
+	namespaceRoot      string
+	migrations         *Migrations
+	hashChain          bool
+	hashKey            []byte
+	allowedRoles       []string
+	adoptExisting      bool
+	repeatables        []Repeatable
+	warningHandler     func(Warning)
+	slowThreshold      time.Duration
+	shadowVerification bool
+	onlineRowThreshold int64
+	middleware         []Middleware
+	backupDir          string
+	progressHandler    func(Event)
+	foreignKeyMode     ForeignKeyMode
+	integrityCheck     bool
+	maintenance        Maintenance
+	appliedBy          *AppliedBy
+	migrationTimeout   time.Duration
+	confirm            func(context.Context, *Plan) (bool, error)
+	allowDestructive   bool
+	awaitTimeout       time.Duration
+	lockFile           string
+	protected          bool
+	keepApplied        bool
+	commitEach         bool
+	excludedVersions   []int64
+	userVersion        bool
+	historyDatabase    string
+	attached           []attachment
 }
 
-// New creates a new database instance with a DSN string and migrations.
+// New creates a new database instance with a DSN string and migrations. It opens the database with
+// the driver registered as "sqlite3", which the application has to import, e.g. with
+// import _ "github.com/mattn/go-sqlite3". The package itself doesn't depend on a driver.
 func New(dsn string, migrations *Migrations) (*Database, error) {
 	conn, err := sql.Open("sqlite3", dsn)
 	if err != nil {
@@ -54,7 +187,7 @@ func New(dsn string, migrations *Migrations) (*Database, error) {
 func NewWithConn(conn *sql.DB, migrations *Migrations) *Database {
 	return &Database{
 		conn:           conn,
-		migrationTable: "_migrations",
+		migrationTable: DefaultMigrationTable,
 		migrations:     migrations,
 	}
 }
@@ -64,131 +197,667 @@ func (db *Database) Close() error {
 	return db.conn.Close()
 }
 
-// SetMigrationTable sets the name of the migration table.
+// SetMigrationTable sets the name of the migration table. The name is quoted in every statement,
+// so it may contain any character except NUL, but it can't be empty or start with "sqlite_", which
+// SQLite reserves. Invalid names make runs fail with CodeMigrationTable.
 func (db *Database) SetMigrationTable(table string) *Database {
 	db.migrationTable = table
 	return db
 }
 
-// MigrateUp migrates the database up to the current version (highest version).
-func (db *Database) MigrateUp(ctx context.Context) error {
-	tx, err := db.conn.BeginTx(ctx, nil)
-	if err != nil {
+// checkMigrationTable returns an error if the name of the migration table is invalid.
+func (db *Database) checkMigrationTable() error {
+	if err := checkTableName(db.migrationTable); err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	return db.checkNamespace()
+}
+
+// checkTableName returns an error if the name can't be used for a migration table.
+func checkTableName(table string) error {
+	switch {
+	case table == "":
+		return errorf(CodeMigrationTable, "invalid migration table name: name is empty")
+	case strings.ContainsRune(table, 0):
+		return errorf(CodeMigrationTable, "invalid migration table name %q: name contains a NUL character", table)
+	case strings.HasPrefix(strings.ToLower(table), "sqlite_"):
+		return errorf(CodeMigrationTable, "invalid migration table name %q: names starting with sqlite_ are reserved", table)
+	}
+	return nil
+}
+
+// SetAllowedRoles restricts the migrations that can run to those without a role or with one of
+// the given roles. A run that would apply or roll back any other migration fails before making
+// changes.
+func (db *Database) SetAllowedRoles(roles ...string) *Database {
+	db.allowedRoles = roles
+	return db
+}
+
+// checkRole returns an error if the migration requires a role that isn't allowed.
+func (c *runConfig) checkRole(migration Migration) error {
+	if migration.Role == "" || c.allowedRoles == nil || contains(c.allowedRoles, migration.Role) {
+		return nil
+	}
+	return errorf(CodeRoleNotAllowed, "migration (version=%v, description=%s) requires role %s", migration.Version, migration.Description, migration.Role)
+}
+
+// SetAdoptExisting makes MigrateUp record pending migrations as applied without running them when
+// their changes are already present: Applied returns true, or every object in Objects exists.
+// This helps adopting databases whose schema was partially created outside of litemigrate.
+func (db *Database) SetAdoptExisting(adopt bool) *Database {
+	db.adoptExisting = adopt
+	return db
+}
+
+// adopt reports whether a pending migration's changes are already present in the database.
+func (c *runConfig) adopt(ctx context.Context, tx *sql.Tx, migration Migration) (bool, error) {
+	if !c.adoptExisting || (migration.Applied == nil && len(migration.Objects) == 0) {
+		return false, nil
+	}
+
+	if migration.Applied != nil {
+		applied, err := migration.Applied(tx)
+		if err != nil {
+			return false, errorf(CodeMigrationFailed, "failed to check migration (version=%v, description=%s): %w", migration.Version, migration.Description, err)
+		}
+		if !applied {
+			return false, nil
+		}
+	}
+
+	for _, object := range migration.Objects {
+		exists := false
+		err := tx.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM sqlite_master WHERE name = ?;", object).Scan(&exists)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// applies reports whether a pending migration's condition is met.
+func applies(ctx context.Context, tx *sql.Tx, migration Migration) (bool, error) {
+	if migration.Condition == nil {
+		return true, nil
+	}
+
+	ok, err := migration.Condition(ctx, tx)
+	if err != nil {
+		return false, errorf(CodeMigrationFailed, "failed to check condition of migration (version=%v, description=%s): %w", migration.Version, migration.Description, err)
+	}
+	return ok, nil
+}
+
+// outcome is what a run does with one of the migrations it selected.
+type outcome int
+
+const (
+	outcomeApply outcome = iota
+	outcomeApplied
+	outcomeDeferred
+	outcomeUnmet
+	outcomeAdopted
+	outcomeBlocked
+)
+
+// selection decides what a run does with each of the migrations it selected, in order. MigrateUp,
+// Plan and await share it, so they agree on which migrations are pending.
+type selection struct {
+	cfg         *runConfig
+	index       []int64
+	userVersion bool
+	// gap is the lowest pending version the run skips when tracking the user_version. Applying a
+	// later version would mark it as applied, so later versions are blocked.
+	gap int64
+}
+
+// newSelection returns the selection of a run that selected the migrations.
+func (db *Database) newSelection(cfg *runConfig, index []int64, selected []Migration) *selection {
+	s := &selection{cfg: cfg, index: index, userVersion: db.userVersion}
+	if !s.userVersion {
+		return s
+	}
+
+	versions := map[int64]bool{}
+	for _, migration := range selected {
+		versions[migration.Version] = true
+	}
+	for _, migration := range *db.migrations {
+		if !versions[migration.Version] && !contains(index, migration.Version) {
+			s.skip(migration.Version)
+		}
+	}
+	return s
+}
+
+// skip records that the run doesn't apply the pending version.
+func (s *selection) skip(version int64) {
+	if s.userVersion && (s.gap == 0 || version < s.gap) {
+		s.gap = version
+	}
+}
+
+// decide returns what the run does with the migration, checking whether it is applied, blocked,
+// deferred, unmet or adopted in that order.
+func (s *selection) decide(ctx context.Context, tx *sql.Tx, migration Migration) (outcome, error) {
+	if contains(s.index, migration.Version) {
+		return outcomeApplied, nil
+	}
+
+	if s.gap != 0 && migration.Version > s.gap {
+		return outcomeBlocked, nil
+	}
+
+	deferred, err := s.cfg.deferred(migration)
+	if err != nil {
+		return 0, err
+	}
+	if deferred {
+		s.skip(migration.Version)
+		return outcomeDeferred, nil
+	}
+
+	ok, err := applies(ctx, tx, migration)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		s.skip(migration.Version)
+		return outcomeUnmet, nil
+	}
+
+	adopted, err := s.cfg.adopt(ctx, tx, migration)
+	if err != nil {
+		return 0, err
+	}
+	if adopted {
+		return outcomeAdopted, nil
+	}
+	return outcomeApply, nil
+}
+
+// MigrateUp migrates the database up to the current version (highest version) and returns a report
+// of the run. Migrations marked PostDeploy are skipped, see MigratePostDeploy. Options override the
+// database's settings for this run only. When a migration fails and the ones before it are kept,
+// see SetKeepApplied, it returns the report of the kept ones along with the error.
+func (db *Database) MigrateUp(ctx context.Context, opts ...RunOption) (*Result, error) {
+	cfg := db.runConfig(opts)
+
+	unlock, err := db.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if db.shadowVerification {
+		if err := db.verifyShadow(ctx, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if !cfg.dryRun {
+		if err := db.confirmUp(ctx, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		result *Result
+		failed error
+	)
+	run := func() (err error) {
+		result, err = db.migrateUp(ctx, cfg)
+		if cfg.awaitTimeout > 0 && !cfg.dryRun && isBusy(err) {
+			result, err = db.await(ctx, cfg)
+		}
+		// The migrations before a failed one were kept, so the backup mustn't be restored.
+		if err != nil && result != nil {
+			failed = err
+			return nil
+		}
+		return lockedError(err)
+	}
+	if db.backupDir != "" && !cfg.dryRun {
+		err = db.withBackup(ctx, run)
+	} else {
+		err = run()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	db.maintain(ctx, result)
+	return result, failed
+}
+
+func (db *Database) migrateUp(ctx context.Context, cfg *runConfig) (_ *Result, err error) {
+	start := time.Now()
+	result := newResult(Up)
+	defer func() {
+		result.Duration = time.Since(start)
+		db.progress(Event{Type: RunCompleted, Direction: Up, Duration: result.Duration, Migrations: len(result.Applied), Err: err})
+	}()
+
+	parent := ctx
+	ctx, cancel := cfg.deadline(ctx)
+	defer cancel()
+	defer func() { err = cfg.deadlineError(parent, err) }()
+
+	tx, release, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// With commitEach, the transaction is replaced after every migration.
+	defer func() { release() }()
 
 	err = db.createMigrationTable(ctx, tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	index, err := db.getMigrationIndex(ctx, tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	migrationExists := map[uint]bool{}
-	for _, migration := range db.migrations.sorted() {
-		if migration.Version == 0 || migration.Description == "" {
-			return fmt.Errorf("invalid migration: version and description must be set")
+	if err := db.migrations.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := db.checkAttached(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.checkVersions(db.migrations); err != nil {
+		return nil, err
+	}
+
+	marker, err := db.readFailed(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if marker != nil && contains(index, marker.Version) {
+		if err := db.clearFailed(ctx, tx); err != nil {
+			return nil, err
+		}
+		marker = nil
+	}
+	if marker != nil {
+		log.Printf("resuming from failed migration (version=%v, error=%s)", marker.Version, marker.Error)
+	}
+
+	if err := db.validateRepeatables(); err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]bool{}
+	for _, version := range index {
+		applied[version] = true
+	}
+
+	migrations := cfg.limit(db.migrations.sorted())
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			deferred, err := cfg.deferred(migration)
+			if err != nil {
+				return nil, err
+			}
+			if deferred {
+				continue
+			}
+			if err := cfg.checkRole(migration); err != nil {
+				return nil, err
+			}
+			if err := cfg.checkDestructive(migration); err != nil {
+				return nil, err
+			}
+			if err := checkDependencies(migration, applied); err != nil {
+				return nil, err
+			}
+			applied[migration.Version] = true
 		}
+	}
 
-		if migration.Up == nil || migration.Down == nil {
-			return fmt.Errorf("invalid migration: up and down must be set")
+	defined := db.migrations.byVersion()
+	for _, version := range index {
+		if _, ok := defined[version]; !ok {
+			db.warn(result, WarningUnknownMigration, version, "applied migration isn't defined in code")
 		}
+	}
 
-		if migrationExists[migration.Version] {
-			return fmt.Errorf("duplicate migration: (version=%v, description=%s) already exists", migration.Version, migration.Description)
+	run := db.runner()
+	latest := int64(0)
+	if len(index) > 0 {
+		latest = index[len(index)-1]
+	}
+
+	excluded := make([]Migration, 0)
+	for _, migration := range db.migrations.sorted() {
+		if contains(cfg.excludedVersions, migration.Version) && !contains(index, migration.Version) {
+			excluded = append(excluded, migration)
+			result.Excluded = append(result.Excluded, migration.Version)
 		}
-		migrationExists[migration.Version] = true
+	}
 
-		if slices.Contains(index, migration.Version) {
+	// failed is the error of a failed migration when the ones before it are kept.
+	var failed error
+	commitEach := cfg.commitEach && !cfg.dryRun
+
+	selection := db.newSelection(cfg, index, migrations)
+	for _, migration := range migrations {
+		outcome, err := selection.decide(ctx, tx, migration)
+		if err != nil {
+			return nil, err
+		}
+
+		switch outcome {
+		case outcomeApplied:
 			log.Printf("skipping migration: (version=%v, description=%s) already exists", migration.Version, migration.Description)
+			result.Skipped = append(result.Skipped, migration.Version)
+			continue
+		case outcomeDeferred:
+			log.Printf("skipping migration: (version=%v, description=%s) requires application version %s", migration.Version, migration.Description, migration.MinAppVersion)
+			result.Deferred = append(result.Deferred, migration.Version)
+			continue
+		case outcomeUnmet:
+			log.Printf("skipping migration: (version=%v, description=%s) condition isn't met", migration.Version, migration.Description)
+			result.Unmet = append(result.Unmet, migration.Version)
+			continue
+		case outcomeBlocked:
+			log.Printf("skipping migration: (version=%v, description=%s) the user_version can't move past skipped version %v", migration.Version, migration.Description, selection.gap)
+			continue
+		case outcomeAdopted:
+			if err := db.insertMigration(ctx, tx, migration.Version, migration.Description); err != nil {
+				return nil, err
+			}
+			log.Printf("adopted migration: (version=%v, description=%s) schema already exists", migration.Version, migration.Description)
+			result.Adopted = append(result.Adopted, migration.Version)
 			continue
 		}
 
-		if err := migration.Up(tx); err != nil {
-			return err
+		if migration.Version < latest {
+			db.warn(result, WarningOutOfOrder, migration.Version, "applied after version %v", latest)
 		}
 
-		if err := db.insertMigration(ctx, tx, migration.Version, migration.Description); err != nil {
-			return err
+		err = savepoint(ctx, tx, fmt.Sprintf("litemigrate_%d", migration.Version), func() error {
+			return db.apply(ctx, tx, run, migration, result)
+		})
+		if err != nil && commitEach {
+			// The transaction only holds the failed migration, and rolling it back keeps the
+			// committed ones. The run's context may be the reason it failed, so the marker must
+			// not depend on it.
+			release()
+			release = func() {}
+			if markErr := db.markFailed(context.Background(), migration, err); markErr != nil {
+				log.Printf("failed to record failed migration (version=%v): %v", migration.Version, markErr)
+			}
+			if len(result.Applied)+len(result.Adopted) == 0 {
+				return nil, err
+			}
+			result.Failed = migration.Version
+			result.setVersion(latest)
+			return result, err
+		}
+		if err != nil {
+			if !cfg.keepApplied || len(result.Applied)+len(result.Adopted) == 0 || errors.Is(err, errSavepoint) {
+				return nil, err
+			}
+			log.Printf("keeping the migrations applied before the failed one (version=%v, description=%s)", migration.Version, migration.Description)
+			result.Failed = migration.Version
+			failed = err
+			break
 		}
 
-		log.Printf("migrated database up (version=%v, description=%s)", migration.Version, migration.Description)
+		if marker != nil && marker.Version == migration.Version {
+			if err := db.clearFailed(ctx, tx); err != nil {
+				return nil, err
+			}
+			marker = nil
+		}
+
+		if commitEach {
+			if err := tx.Commit(); err != nil {
+				return nil, err
+			}
+			release()
+			tx, release, err = db.begin(ctx)
+			if err != nil {
+				release = func() {}
+				return nil, err
+			}
+		}
+	}
+
+	result.setVersion(latest)
+
+	if err := db.recordExcluded(ctx, tx, excluded); err != nil {
+		return nil, err
 	}
-	return tx.Commit()
+
+	// Repeatable migrations only run once every migration is applied.
+	if failed == nil {
+		if err := db.runRepeatables(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.checkIntegrity(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := db.checkForeignKeys(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if cfg.dryRun {
+		log.Printf("dry run: rolling back migration run")
+		result.DryRun = true
+		return result, failed
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, failed
 }
 
-// MigrateDown migrates the database down by the specified amount.
-func (db *Database) MigrateDown(ctx context.Context, amount int) error {
-	tx, err := db.conn.BeginTx(ctx, nil)
+// apply runs a pending migration and records it as applied.
+func (db *Database) apply(ctx context.Context, tx *sql.Tx, run Runner, migration Migration, result *Result) error {
+	if err := checkPrecondition(ctx, tx, migration); err != nil {
+		return err
+	}
+
+	elapsed, err := db.runStep(ctx, tx, run, Step{Migration: migration, Direction: Up})
 	if err != nil {
+		return errorf(CodeMigrationFailed, "migration (version=%v, description=%s) failed: %w", migration.Version, migration.Description, err)
+	}
+	if err := verify(ctx, tx, migration); err != nil {
+		return err
+	}
+
+	if db.slowThreshold > 0 && elapsed > db.slowThreshold {
+		db.warn(result, WarningSlowMigration, migration.Version, "took %s", elapsed.Round(time.Millisecond))
+	}
+
+	if err := db.insertMigration(ctx, tx, migration.Version, migration.Description); err != nil {
+		return err
+	}
+
+	if err := db.recordDuration(ctx, tx, migration.Version, elapsed); err != nil {
 		return err
 	}
-	defer tx.Rollback()
+
+	result.Applied = append(result.Applied, migration.Version)
+	result.Durations[migration.Version] = elapsed
+	log.Printf("migrated database up (version=%v, description=%s)", migration.Version, migration.Description)
+	return nil
+}
+
+// MigrateDown migrates the database down by the specified amount and returns a report of the run.
+// It fails if the database is protected, see SetProtected. Options override the database's
+// settings for this run only.
+func (db *Database) MigrateDown(ctx context.Context, amount int, opts ...RunOption) (*Result, error) {
+	cfg := db.runConfig(opts)
+	if err := cfg.checkProtected(); err != nil {
+		return nil, err
+	}
+
+	unlock, err := db.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := db.confirmDown(ctx, amount); err != nil {
+		return nil, err
+	}
+
+	result, err := db.migrateDown(ctx, amount, cfg)
+	if err != nil {
+		return nil, lockedError(err)
+	}
+
+	db.maintain(ctx, result)
+	return result, nil
+}
+
+func (db *Database) migrateDown(ctx context.Context, amount int, cfg *runConfig) (_ *Result, err error) {
+	start := time.Now()
+	result := newResult(Down)
+	defer func() {
+		result.Duration = time.Since(start)
+		db.progress(Event{Type: RunCompleted, Direction: Down, Duration: result.Duration, Migrations: len(result.RolledBack), Err: err})
+	}()
+
+	parent := ctx
+	ctx, cancel := cfg.deadline(ctx)
+	defer cancel()
+	defer func() { err = cfg.deadlineError(parent, err) }()
+
+	tx, release, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	err = db.createMigrationTable(ctx, tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	index, err := db.getMigrationIndex(ctx, tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(index) == 0 {
-		return fmt.Errorf("no migrations to rollback")
+		return nil, errorf(CodeNothingToRollback, "no migrations to rollback")
+	}
+
+	if err := db.migrations.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := db.checkAttached(); err != nil {
+		return nil, err
 	}
 
 	if amount > len(index) {
 		amount = len(index)
 	}
 
-	for i := len(index) - 1; i >= len(index)-amount; i-- {
-		migration := db.migrations.sorted()[index[i]-1]
-
-		if migration.Version == 0 || migration.Description == "" {
-			return fmt.Errorf("invalid migration: version and description must be set")
+	order := db.migrations.rollbackOrder(index)
+	migrations := db.migrations.byVersion()
+	for _, version := range order[:amount] {
+		migration, ok := migrations[version]
+		if !ok {
+			return nil, errorf(CodeUnknownMigration, "migration (version=%v) is applied but doesn't exist", version)
 		}
 
-		if migration.Up == nil || migration.Down == nil {
-			return fmt.Errorf("invalid migration: up and down must be set")
+		if err := cfg.checkRole(migration); err != nil {
+			return nil, err
 		}
 
-		if !slices.Contains(index, migration.Version) {
-			return fmt.Errorf("migration (version=%v, description=%s) doesn't exists", migration.Version, migration.Description)
+		if migration.Down == nil {
+			return nil, &Error{Code: CodeIrreversible, Err: ErrIrreversible{Version: migration.Version}}
 		}
+	}
 
-		if err := migration.Down(tx); err != nil {
-			return err
+	run := db.runner()
+	for _, version := range order[:amount] {
+		migration := migrations[version]
+
+		elapsed, err := db.runStep(ctx, tx, run, Step{Migration: migration, Direction: Down})
+		if err != nil {
+			return nil, errorf(CodeMigrationFailed, "migration (version=%v, description=%s) failed: %w", migration.Version, migration.Description, err)
 		}
+		result.RolledBack = append(result.RolledBack, migration.Version)
+		result.Durations[migration.Version] = elapsed
 
 		if err := db.deleteMigration(ctx, tx, migration.Version); err != nil {
-			return err
+			return nil, err
 		}
 
 		log.Printf("migrated database down (version=%v, description=%s)", migration.Version, migration.Description)
 	}
-	return tx.Commit()
+
+	for _, version := range order[amount:] {
+		if version > result.Version {
+			result.Version = version
+		}
+	}
+
+	if err := db.checkIntegrity(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := db.checkForeignKeys(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // CurrentVersion returns the current version of the database.
-func (db *Database) CurrentVersion(ctx context.Context) (uint, error) {
-	query := fmt.Sprintf("SELECT version FROM %s ORDER BY version DESC LIMIT 1;", db.migrationTable)
+func (db *Database) CurrentVersion(ctx context.Context) (int64, error) {
+	if db.userVersion {
+		return readUserVersion(ctx, db.conn)
+	}
 
-	rows, err := db.conn.QueryContext(ctx, query)
+	q, release, err := db.stateConn(ctx)
 	if err != nil {
 		return 0, err
 	}
+	defer release()
+
+	exists, err := db.migrationTableExists(ctx, q)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	applied, err := db.appliedCondition(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf("SELECT version FROM %s%s ORDER BY version DESC LIMIT 1;", db.qualify(db.migrationTable), applied)
+
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
 
 	if !rows.Next() {
 		return 0, nil
 	}
 
-	version := uint(0)
+	version := int64(0)
 	if err := rows.Scan(&version); err != nil {
 		return 0, err
 	}
@@ -196,21 +865,45 @@ func (db *Database) CurrentVersion(ctx context.Context) (uint, error) {
 }
 
 func (db *Database) createMigrationTable(ctx context.Context, tx *sql.Tx) error {
+	if db.userVersion {
+		return db.checkUserVersion()
+	}
+
+	if err := db.checkMigrationTable(); err != nil {
+		return err
+	}
+
 	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS %s (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			version INTEGER UNIQUE NOT NULL,
 			description VARCHAR(255) UNIQUE NOT NULL
 		);
-	`, db.migrationTable))
+	`, db.qualify(db.migrationTable)))
 	if err != nil {
-		return fmt.Errorf("failed to create migration table: %w", err)
+		return errorf(CodeMigrationTable, "failed to create migration table: %w", err)
+	}
+
+	if err := db.addMetadataColumns(ctx, tx); err != nil {
+		return err
+	}
+
+	if db.hashChain {
+		return db.addHashColumns(ctx, tx)
 	}
 	return nil
 }
 
-func (db *Database) getMigrationIndex(ctx context.Context, tx *sql.Tx) ([]uint, error) {
-	query := fmt.Sprintf("SELECT version FROM %s ORDER BY version ASC;", db.migrationTable)
+func (db *Database) getMigrationIndex(ctx context.Context, tx *sql.Tx) ([]int64, error) {
+	if db.userVersion {
+		return db.userVersionIndex(ctx, tx)
+	}
+
+	applied, err := db.appliedCondition(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT version FROM %s%s ORDER BY version ASC;", db.qualify(db.migrationTable), applied)
 
 	rows, err := tx.QueryContext(ctx, query)
 	if err != nil {
@@ -218,9 +911,9 @@ func (db *Database) getMigrationIndex(ctx context.Context, tx *sql.Tx) ([]uint,
 	}
 	defer rows.Close()
 
-	index := make([]uint, 0)
+	index := make([]int64, 0)
 	for rows.Next() {
-		var version uint
+		var version int64
 		if err := rows.Scan(&version); err != nil {
 			return nil, err
 		}
@@ -233,20 +926,145 @@ func (db *Database) getMigrationIndex(ctx context.Context, tx *sql.Tx) ([]uint,
 	return index, nil
 }
 
-func (db *Database) insertMigration(ctx context.Context, tx *sql.Tx, version uint, description string) error {
-	query := fmt.Sprintf("INSERT INTO %s (version, description) VALUES (?, ?);", db.migrationTable)
-	_, err := tx.ExecContext(ctx, query, version, description)
+func (db *Database) insertMigration(ctx context.Context, tx *sql.Tx, version int64, description string) error {
+	if db.userVersion {
+		return db.insertUserVersion(ctx, tx, version)
+	}
+
+	// The record of an excluded migration is replaced, so the version stays unique.
+	excluded, err := db.excludedRecorded(ctx, tx, version)
 	if err != nil {
-		return fmt.Errorf("failed to insert migration (version=%v, description=%s): %w", version, description, err)
+		return err
+	}
+	if excluded {
+		if err := db.deleteMigration(ctx, tx, version); err != nil {
+			return err
+		}
+	}
+	return db.insertRecord(ctx, tx, version, description, StatusApplied)
+}
+
+// insertRecord adds a record with the status to the migration table.
+func (db *Database) insertRecord(ctx context.Context, tx *sql.Tx, version int64, description, status string) error {
+	record := HistoryRecord{
+		Version:     version,
+		Description: description,
+		AppliedAt:   time.Now().UTC(),
+		AppliedBy:   db.currentAppliedBy(),
+		Database:    db.migrations.byVersion()[version].target(),
+		Status:      status,
+	}
+	checksum := ""
+	if status == StatusApplied {
+		checksum = db.migrations.byVersion()[version].Checksum
+	}
+	columns := []string{ColumnVersion, ColumnDescription, ColumnAppliedAt, ColumnAppliedHost, ColumnAppliedUser, ColumnAppVersion, ColumnApprovedBy, ColumnDatabase, ColumnStatus, ColumnChecksum}
+	args := []any{version, description, record.AppliedAt.Format(time.RFC3339Nano), record.AppliedBy.Host, record.AppliedBy.User, record.AppliedBy.AppVersion, record.AppliedBy.ApprovedBy, record.Database, record.Status, checksum}
+
+	if db.hashChain {
+		prevHash, err := db.lastHash(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		columns = append(columns, ColumnPrevHash, ColumnHash)
+		args = append(args, prevHash, chainHash(db.hashKey, prevHash, record))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", db.qualify(db.migrationTable), strings.Join(columns, ", "), placeholders)
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errorf(CodeMigrationTable, "failed to insert migration (version=%v, description=%s): %w", version, description, err)
+	}
+	return nil
+}
+
+// metadataColumns are the columns added to the migration table after its initial layout, with
+// their types. They are added to existing tables on the next run.
+var metadataColumns = []struct {
+	name string
+	typ  string
+}{
+	{ColumnDurationMS, "INTEGER"},
+	{ColumnAppliedAt, "TEXT"},
+	{ColumnAppliedHost, "TEXT"},
+	{ColumnAppliedUser, "TEXT"},
+	{ColumnAppVersion, "TEXT"},
+	{ColumnApprovedBy, "TEXT"},
+	{ColumnDatabase, "TEXT"},
+	{ColumnStatus, "TEXT"},
+	{ColumnChecksum, "TEXT"},
+}
+
+// addMetadataColumns adds the metadata columns that the migration table doesn't have yet.
+func (db *Database) addMetadataColumns(ctx context.Context, tx *sql.Tx) error {
+	columns, err := db.stateColumns(ctx, tx, db.migrationTable)
+	if err != nil {
+		return err
+	}
+
+	for _, column := range metadataColumns {
+		if contains(columns, column.name) {
+			continue
+		}
+
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", db.qualify(db.migrationTable), column.name, column.typ)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return errorf(CodeMigrationTable, "failed to add %s column to migration table: %w", column.name, err)
+		}
 	}
 	return nil
 }
 
-func (db *Database) deleteMigration(ctx context.Context, tx *sql.Tx, version uint) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE version = ?;", db.migrationTable)
+// recordDuration stores the execution time of the migration applied last, and updates its hash.
+func (db *Database) recordDuration(ctx context.Context, tx *sql.Tx, version int64, duration time.Duration) error {
+	if db.userVersion {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s = ? WHERE version = ?;", db.qualify(db.migrationTable), ColumnDurationMS)
+	if _, err := tx.ExecContext(ctx, query, duration.Milliseconds(), version); err != nil {
+		return errorf(CodeMigrationTable, "failed to record duration of migration (version=%v): %w", version, err)
+	}
+
+	if !db.hashChain {
+		return nil
+	}
+	records, err := db.readHistory(ctx, tx)
+	if err != nil {
+		return err
+	}
+	last := records[len(records)-1]
+	return db.rehash(ctx, tx, records[len(records)-1:], last.PrevHash)
+}
+
+func (db *Database) deleteMigration(ctx context.Context, tx *sql.Tx, version int64) error {
+	if db.userVersion {
+		return db.deleteUserVersion(ctx, tx, version)
+	}
+
+	if db.hashChain {
+		if err := db.unlink(ctx, tx, version); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE version = ?;", db.qualify(db.migrationTable))
 	_, err := tx.ExecContext(ctx, query, version)
 	if err != nil {
-		return fmt.Errorf("failed to delete migration (version=%v): %w", version, err)
+		return errorf(CodeMigrationTable, "failed to delete migration (version=%v): %w", version, err)
 	}
 	return nil
 }
+
+// contains reports whether v is in s.
+func contains[T comparable](s []T, v T) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
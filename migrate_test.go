@@ -3,10 +3,13 @@ package litemigrate_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/joeychilson/litemigrate"
+
+	_ "github.com/mattn/go-sqlite3"
 )
 
 const testDBPath = ":memory:"
@@ -50,7 +53,7 @@ func TestMigrateUp(t *testing.T) {
 	}
 	defer db.Close()
 
-	err = db.MigrateUp(context.Background())
+	_, err = db.MigrateUp(context.Background())
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -87,12 +90,12 @@ func TestMigrateDown(t *testing.T) {
 	}
 	defer db.Close()
 
-	err = db.MigrateUp(context.Background())
+	_, err = db.MigrateUp(context.Background())
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
 
-	err = db.MigrateDown(context.Background(), 1)
+	_, err = db.MigrateDown(context.Background(), 1)
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -107,6 +110,47 @@ func TestMigrateDown(t *testing.T) {
 	}
 }
 
+func TestIrreversibleMigration(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	irreversible := tableMigration(2, "two")
+	irreversible.Down = nil
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one"), irreversible, tableMigration(3, "three")})
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := db.MigrateDown(ctx, 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = db.MigrateDown(ctx, 2)
+	var irreversibleErr litemigrate.ErrIrreversible
+	if !errors.As(err, &irreversibleErr) || irreversibleErr.Version != 2 {
+		t.Fatalf("expected ErrIrreversible for version 2, got %v", err)
+	}
+
+	if code := litemigrate.Code(err); code != litemigrate.CodeIrreversible {
+		t.Errorf("expected error code %s, got %s", litemigrate.CodeIrreversible, code)
+	}
+
+	version, err := db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 2 {
+		t.Errorf("expected nothing to be rolled back, got version %d", version)
+	}
+}
+
 func TestInvalidMigration(t *testing.T) {
 	migrations := &litemigrate.Migrations{
 		{
@@ -123,9 +167,201 @@ func TestInvalidMigration(t *testing.T) {
 	}
 	defer db.Close()
 
-	err = db.MigrateUp(context.Background())
+	_, err = db.MigrateUp(context.Background())
 	expectedErr := fmt.Errorf("invalid migration: version and description must be set")
 	if err == nil || err.Error() != expectedErr.Error() {
 		t.Errorf("expected error %v, got %v", expectedErr, err)
 	}
 }
+
+func TestMigrationTableName(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users")})
+	db.SetMigrationTable(`migrations"; DROP TABLE users; --`)
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	version, err := db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 1 {
+		t.Fatalf("expected version 1, got %d", version)
+	}
+
+	if _, err := conn.Exec("SELECT * FROM users;"); err != nil {
+		t.Fatalf("expected users table to exist, got %v", err)
+	}
+
+	for _, table := range []string{"", "sqlite_migrations", "migrations\x00"} {
+		db.SetMigrationTable(table)
+		if _, err := db.MigrateUp(ctx); litemigrate.Code(err) != litemigrate.CodeMigrationTable {
+			t.Errorf("expected error code %s for table name %q, got %v", litemigrate.CodeMigrationTable, table, err)
+		}
+	}
+}
+
+func TestTimestampVersions(t *testing.T) {
+	migrations := &litemigrate.Migrations{
+		tableMigration(20240613090000, "second"),
+		tableMigration(20240612153000, "first"),
+	}
+
+	db, err := litemigrate.New(testDBPath, migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = db.MigrateDown(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	version, err := db.CurrentVersion(context.Background())
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if version != 20240612153000 {
+		t.Errorf("expected version 20240612153000, got %d", version)
+	}
+}
+
+func TestAllowedRoles(t *testing.T) {
+	destructive := tableMigration(2, "two")
+	destructive.Role = "destructive"
+
+	migrations := &litemigrate.Migrations{tableMigration(1, "one"), destructive}
+
+	db, err := litemigrate.New(testDBPath, migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	db.SetAllowedRoles("ddl", "data")
+
+	_, err = db.MigrateUp(context.Background())
+	if err == nil {
+		t.Fatal("expected error for disallowed role, got nil")
+	}
+
+	version, err := db.CurrentVersion(context.Background())
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if version != 0 {
+		t.Errorf("expected version 0, got %d", version)
+	}
+
+	db.SetAllowedRoles("ddl", "data", "destructive")
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestAdoptExisting(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`CREATE TABLE one (id INTEGER PRIMARY KEY);`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	one := tableMigration(1, "one")
+	one.Objects = []string{"one"}
+
+	two := tableMigration(2, "two")
+	two.Applied = func(tx *sql.Tx) (bool, error) {
+		return false, nil
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{one, two})
+
+	_, err = db.MigrateUp(context.Background())
+	if err == nil {
+		t.Fatal("expected error creating an existing table, got nil")
+	}
+
+	_, err = db.SetAdoptExisting(true).MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	version, err := db.CurrentVersion(context.Background())
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if version != 2 {
+		t.Errorf("expected version 2, got %d", version)
+	}
+}
+
+func tableMigration(version int64, table string) litemigrate.Migration {
+	return litemigrate.Migration{
+		Version:     version,
+		Description: "Create " + table + " table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(fmt.Sprintf(`CREATE TABLE %s (id INTEGER PRIMARY KEY);`, table))
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec(fmt.Sprintf(`DROP TABLE %s;`, table))
+			return err
+		},
+	}
+}
+
+func TestCondition(t *testing.T) {
+	ctx := context.Background()
+
+	legacy := tableMigration(2, "legacy_copy")
+	legacy.Condition = func(ctx context.Context, tx *sql.Tx) (bool, error) {
+		exists := false
+		err := tx.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM sqlite_master WHERE name = 'legacy';").Scan(&exists)
+		return exists, err
+	}
+
+	db, err := litemigrate.New(testDBPath, &litemigrate.Migrations{tableMigration(1, "one"), legacy})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 1 || len(result.Unmet) != 1 || result.Unmet[0] != 2 {
+		t.Fatalf("expected version 2 to be unmet, got %+v", result)
+	}
+
+	if version, _ := db.CurrentVersion(ctx); version != 1 {
+		t.Fatalf("expected version 1, got %d", version)
+	}
+}
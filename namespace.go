@@ -0,0 +1,52 @@
+package litemigrate
+
+import "strings"
+
+// Namespace returns a database that shares the connection and settings of db but applies an
+// independent set of migrations, tracked in its own table named after the migration table with
+// the namespace as a suffix, e.g. "_migrations_billing". It lets the packages or plugins of a
+// modular application own their migrations against the same database. Versions only need to be
+// unique within a namespace. Names ending in "failed", "repeatable" or "copy" are reserved for the
+// tables kept next to a migration table, and fail runs with CodeMigrationTable.
+func (db *Database) Namespace(name string, migrations *Migrations) *Database {
+	namespaced := *db
+	namespaced.migrations = migrations
+	namespaced.migrationTable = db.rootTable() + "_" + name
+	namespaced.namespaceRoot = db.rootTable()
+	return &namespaced
+}
+
+// rootTable returns the migration table that the tables of all namespaces are named after.
+func (db *Database) rootTable() string {
+	if db.namespaceRoot != "" {
+		return db.namespaceRoot
+	}
+	return db.migrationTable
+}
+
+// bookkeepingPattern returns a LIKE pattern, with \ as the escape character, matching the tables
+// of every namespace.
+func (db *Database) bookkeepingPattern() string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(db.rootTable())
+	return escaped + `\_%`
+}
+
+// bookkeepingSuffixes are the suffixes of the tables kept next to a migration table, see
+// failedTable, repeatableTable and copyCursorTable.
+var bookkeepingSuffixes = []string{"_failed", "_repeatable", "_copy"}
+
+// checkNamespace returns an error if the migration table of the namespace would be named like a
+// table kept next to the root's or another namespace's migration table.
+func (db *Database) checkNamespace() error {
+	if db.namespaceRoot == "" {
+		return nil
+	}
+
+	name := strings.TrimPrefix(db.migrationTable, db.namespaceRoot+"_")
+	for _, suffix := range bookkeepingSuffixes {
+		if strings.HasSuffix("_"+name, suffix) {
+			return errorf(CodeMigrationTable, "invalid namespace %q: names ending in %s are reserved", name, suffix[1:])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package litemigrate_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	app := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users")})
+	billing := app.Namespace("billing", &litemigrate.Migrations{tableMigration(1, "invoices")})
+
+	if billing.MigrationTable() != "_migrations_billing" {
+		t.Fatalf("expected migration table _migrations_billing, got %s", billing.MigrationTable())
+	}
+
+	if _, err := app.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Version 1 of the billing namespace is independent of version 1 of the application.
+	result, err := billing.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 1 || result.Applied[0] != 1 {
+		t.Fatalf("expected version 1 to be applied, got %v", result.Applied)
+	}
+
+	var dump bytes.Buffer
+	if err := billing.DumpSchema(ctx, &dump); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if strings.Contains(dump.String(), "_migrations") {
+		t.Errorf("expected no migration tables in the schema, got %s", dump.String())
+	}
+	if !strings.Contains(dump.String(), "invoices") || !strings.Contains(dump.String(), "users") {
+		t.Errorf("expected the tables of both namespaces in the schema, got %s", dump.String())
+	}
+}
+
+func TestNamespaceReserved(t *testing.T) {
+	db, err := litemigrate.New(testDBPath, &litemigrate.Migrations{tableMigration(1, "users")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	for _, name := range []string{"failed", "billing_repeatable", "copy"} {
+		namespace := db.Namespace(name, &litemigrate.Migrations{tableMigration(1, "invoices")})
+		if _, err := namespace.MigrateUp(context.Background()); litemigrate.Code(err) != litemigrate.CodeMigrationTable {
+			t.Errorf("expected code %s for namespace %s, got %v", litemigrate.CodeMigrationTable, name, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package litemigrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Availability describes whether readers can keep using the database while a migration runs.
+type Availability string
+
+const (
+	// OnlineSafe migrations can run while the application keeps reading the database.
+	OnlineSafe Availability = "online-safe"
+	// Blocking migrations hold readers up or break their queries, and may need a maintenance
+	// window.
+	Blocking Availability = "blocking"
+)
+
+// DefaultOnlineRowThreshold is the number of rewritten rows above which a migration is considered
+// blocking when the database doesn't use write-ahead logging.
+const DefaultOnlineRowThreshold = 10000
+
+// SetOnlineRowThreshold sets the number of rows a migration may rewrite before Plan labels it as
+// blocking. It only applies to databases that don't use write-ahead logging, where readers wait
+// for the migration to commit.
+func (db *Database) SetOnlineRowThreshold(rows int64) *Database {
+	db.onlineRowThreshold = rows
+	return db
+}
+
+// journalMode returns the journal mode of the database, e.g. "wal" or "delete".
+func journalMode(ctx context.Context, q queryer) (string, error) {
+	rows, err := q.QueryContext(ctx, "PRAGMA journal_mode;")
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	mode := ""
+	if rows.Next() {
+		if err := rows.Scan(&mode); err != nil {
+			return "", err
+		}
+	}
+	return strings.ToLower(mode), rows.Err()
+}
+
+// classify labels a planned migration as online-safe or blocking. Dropping objects breaks the
+// queries of readers that still use them. Without write-ahead logging, readers also wait while
+// the migration rewrites tables or builds indexes, so large rewrites are blocking. rows holds the
+// number of rows of each table before the migration.
+func (db *Database) classify(planned *PlannedMigration, after map[string]schemaObject, rows map[string]int64, wal bool) {
+	planned.Availability = OnlineSafe
+
+	if planned.Destructive() {
+		planned.BlockingReasons = append(planned.BlockingReasons, "drops objects or columns that readers may still use")
+	}
+
+	if !wal {
+		rewritten := planned.Changes
+		for _, count := range planned.Rows {
+			rewritten += count
+		}
+		for _, key := range planned.Created {
+			if object := after[key]; object.Type == "index" {
+				rewritten += rows[object.Table]
+			}
+		}
+
+		threshold := db.onlineRowThreshold
+		if threshold == 0 {
+			threshold = DefaultOnlineRowThreshold
+		}
+
+		if rewritten > threshold {
+			planned.BlockingReasons = append(planned.BlockingReasons, fmt.Sprintf("rewrites about %d rows without write-ahead logging, readers wait until it commits", rewritten))
+		}
+	}
+
+	if len(planned.BlockingReasons) > 0 {
+		planned.Availability = Blocking
+	}
+}
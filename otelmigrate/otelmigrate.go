@@ -0,0 +1,92 @@
+// Package otelmigrate traces migration runs with OpenTelemetry, so slow startup migrations show up
+// in distributed traces. It is a separate package to keep the core free of dependencies.
+//
+//	db.Use(otelmigrate.Middleware(tp))
+//	result, err := otelmigrate.MigrateUp(ctx, tp, db)
+package otelmigrate
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+const instrumentationName = "github.com/joeychilson/litemigrate/otelmigrate"
+
+// Attribute keys of the spans.
+const (
+	VersionKey     = attribute.Key("litemigrate.version")
+	DescriptionKey = attribute.Key("litemigrate.description")
+	DirectionKey   = attribute.Key("litemigrate.direction")
+	AmountKey      = attribute.Key("litemigrate.amount")
+)
+
+// Middleware returns middleware that emits a span for every migration, with its version,
+// description and direction as attributes. A nil provider emits no spans.
+func Middleware(tp trace.TracerProvider) litemigrate.Middleware {
+	tracer := tracer(tp)
+	return func(next litemigrate.Runner) litemigrate.Runner {
+		return func(ctx context.Context, tx *sql.Tx, step litemigrate.Step) error {
+			ctx, span := tracer.Start(ctx, "litemigrate.migration", trace.WithAttributes(
+				VersionKey.Int64(step.Migration.Version),
+				DescriptionKey.String(step.Migration.Description),
+				DirectionKey.String(string(step.Direction)),
+			))
+			defer span.End()
+
+			err := next(ctx, tx, step)
+			record(span, err)
+			return err
+		}
+	}
+}
+
+// MigrateUp runs db.MigrateUp inside a span for the whole run. Together with Middleware, the spans
+// of the individual migrations are its children.
+func MigrateUp(ctx context.Context, tp trace.TracerProvider, db *litemigrate.Database, opts ...litemigrate.RunOption) (*litemigrate.Result, error) {
+	ctx, span := tracer(tp).Start(ctx, "litemigrate.migrate_up", trace.WithAttributes(
+		DirectionKey.String(string(litemigrate.Up)),
+	))
+	defer span.End()
+
+	result, err := db.MigrateUp(ctx, opts...)
+	record(span, err)
+	return result, err
+}
+
+// MigrateDown runs db.MigrateDown inside a span for the whole run.
+func MigrateDown(ctx context.Context, tp trace.TracerProvider, db *litemigrate.Database, amount int, opts ...litemigrate.RunOption) (*litemigrate.Result, error) {
+	ctx, span := tracer(tp).Start(ctx, "litemigrate.migrate_down", trace.WithAttributes(
+		DirectionKey.String(string(litemigrate.Down)),
+		AmountKey.Int(amount),
+	))
+	defer span.End()
+
+	result, err := db.MigrateDown(ctx, amount, opts...)
+	record(span, err)
+	return result, err
+}
+
+func tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = trace.NewNoopTracerProvider()
+	}
+	return tp.Tracer(instrumentationName)
+}
+
+// record marks the span as failed if err is non-nil, with the error code of litemigrate if any.
+func record(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	if code := litemigrate.Code(err); code != "" {
+		span.SetAttributes(attribute.String("litemigrate.error_code", string(code)))
+	}
+}
@@ -0,0 +1,100 @@
+package otelmigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/otelmigrate"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSpans(t *testing.T) {
+	ctx := context.Background()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	failure := errors.New("boom")
+	migrations := &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "create users",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY);")
+				return err
+			},
+			Down: func(tx *sql.Tx) error { return nil },
+		},
+		{
+			Version:     2,
+			Description: "failing",
+			Up:          func(tx *sql.Tx) error { return failure },
+			Down:        func(tx *sql.Tx) error { return nil },
+		},
+	}
+
+	db, err := litemigrate.New(":memory:", migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+	db.Use(otelmigrate.Middleware(tp))
+
+	if _, err := otelmigrate.MigrateUp(ctx, tp, db); !errors.Is(err, failure) {
+		t.Fatalf("expected the migration error, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	run := spans[2]
+	if run.Name() != "litemigrate.migrate_up" {
+		t.Fatalf("expected the run span to end last, got %s", run.Name())
+	}
+
+	for i, span := range spans[:2] {
+		if span.Name() != "litemigrate.migration" {
+			t.Fatalf("expected a migration span, got %s", span.Name())
+		}
+		if span.Parent().SpanID() != run.SpanContext().SpanID() {
+			t.Fatalf("expected the migration span to be a child of the run span")
+		}
+
+		version := int64(0)
+		for _, attr := range span.Attributes() {
+			if attr.Key == otelmigrate.VersionKey {
+				version = attr.Value.AsInt64()
+			}
+		}
+		if version != int64(i+1) {
+			t.Fatalf("expected version %d, got %d", i+1, version)
+		}
+	}
+
+	if spans[0].Status().Code != codes.Unset || spans[1].Status().Code != codes.Error {
+		t.Fatalf("expected only the failing migration to be marked as failed, got %v and %v", spans[0].Status(), spans[1].Status())
+	}
+}
+
+func TestNilProvider(t *testing.T) {
+	db, err := litemigrate.New(":memory:", &litemigrate.Migrations{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+	db.Use(otelmigrate.Middleware(nil))
+
+	if _, err := otelmigrate.MigrateUp(context.Background(), nil, db); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
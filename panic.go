@@ -0,0 +1,38 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error of a migration whose Up or Down function panicked. The run is rolled
+// back like for any other failing migration.
+type PanicError struct {
+	Version int64
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverStep runs a migration step, converting a panic into a PanicError.
+func recoverStep(ctx context.Context, tx *sql.Tx, run Runner, step Step) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = &PanicError{Version: step.Migration.Version, Value: value, Stack: debug.Stack()}
+		}
+	}()
+	return run(ctx, tx, step)
+}
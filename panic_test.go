@@ -0,0 +1,62 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Minute} {
+		conn, err := sql.Open("sqlite3", testDBPath)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer conn.Close()
+		conn.SetMaxOpenConns(1)
+
+		panicking := litemigrate.Migration{
+			Version:     2,
+			Description: "Panicking migration",
+			Up: func(tx *sql.Tx) error {
+				var users map[string]int
+				users["alice"] = 1
+				return nil
+			},
+			Down:    func(tx *sql.Tx) error { return nil },
+			Timeout: timeout,
+		}
+
+		db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users"), panicking})
+
+		_, err = db.MigrateUp(context.Background())
+		var panicErr *litemigrate.PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("expected a panic error, got %v", err)
+		}
+		if panicErr.Version != 2 {
+			t.Errorf("expected version 2, got %d", panicErr.Version)
+		}
+		if !strings.Contains(string(panicErr.Stack), "panic_test.go") {
+			t.Errorf("expected the stack trace of the migration, got %s", panicErr.Stack)
+		}
+
+		var runtimeErr interface{ RuntimeError() }
+		if !errors.As(err, &runtimeErr) {
+			t.Errorf("expected the runtime error to be unwrapped, got %v", err)
+		}
+
+		count := 0
+		if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'users';").Scan(&count); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if count != 0 {
+			t.Error("expected the run to be rolled back")
+		}
+	}
+}
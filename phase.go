@@ -0,0 +1,29 @@
+package litemigrate
+
+// Phase groups migrations for zero-downtime deployments following the expand/contract pattern:
+// expand migrations make additive changes the old and new application code both work with, and
+// contract migrations remove what only the old code used, once it is retired.
+type Phase string
+
+const (
+	// Expand is the phase of migrations without a phase.
+	Expand Phase = "expand"
+	// Contract migrations run after the application code that needs the old schema is retired.
+	Contract Phase = "contract"
+)
+
+// phase returns the migration's phase, which defaults to Expand.
+func (m Migration) phase() Phase {
+	if m.Phase == "" {
+		return Expand
+	}
+	return m.Phase
+}
+
+// WithPhase makes MigrateUp and Plan ignore migrations of other phases, e.g. to run the expand
+// migrations before deploying new code and the contract migrations after the old code is gone.
+func WithPhase(phase Phase) RunOption {
+	return func(c *runConfig) {
+		c.phase = phase
+	}
+}
@@ -0,0 +1,71 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestWithPhase(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	contract := tableMigration(2, "two")
+	contract.Phase = litemigrate.Contract
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		contract,
+		tableMigration(3, "three"),
+	})
+
+	ctx := context.Background()
+	plan, err := db.Plan(ctx, litemigrate.WithPhase(litemigrate.Contract))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(plan.Migrations) != 1 || plan.Migrations[0].Phase != litemigrate.Contract {
+		t.Fatalf("expected one contract migration to be planned, got %+v", plan.Migrations)
+	}
+
+	result, err := db.MigrateUp(ctx, litemigrate.WithPhase(litemigrate.Expand))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 2 || result.Applied[0] != 1 || result.Applied[1] != 3 {
+		t.Fatalf("expected versions 1 and 3 to be applied, got %v", result.Applied)
+	}
+
+	result, err = db.MigrateUp(ctx, litemigrate.WithPhase(litemigrate.Contract))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 1 || result.Applied[0] != 2 {
+		t.Fatalf("expected version 2 to be applied, got %v", result.Applied)
+	}
+}
+
+func TestUnknownPhase(t *testing.T) {
+	migration := tableMigration(1, "one")
+	migration.Phase = "cleanup"
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{migration})
+	if _, err := db.MigrateUp(context.Background()); err == nil {
+		t.Fatal("expected error for unknown phase, got nil")
+	}
+}
@@ -0,0 +1,302 @@
+package litemigrate
+
+import (
+	"context"
+	"sort"
+)
+
+// PlannedMigration describes a pending migration and the estimated impact of applying it.
+type PlannedMigration struct {
+	Version     int64  `json:"version"`
+	Description string `json:"description"`
+	Phase       Phase  `json:"phase"`
+	// Database is the name of the database the migration targets. See Migration.Database.
+	Database string `json:"database,omitempty"`
+	// Created, Altered and Dropped list the schema objects changed by the migration, e.g. "table users".
+	Created []string `json:"created,omitempty"`
+	Altered []string `json:"altered,omitempty"`
+	Dropped []string `json:"dropped,omitempty"`
+	// DroppedColumns lists the columns removed from existing tables, e.g. "users.email".
+	DroppedColumns []string `json:"dropped_columns,omitempty"`
+	// DestructiveStatements lists the statements of the migration flagged as destructive. See
+	// Migration.DestructiveStatements.
+	DestructiveStatements []string `json:"destructive_statements,omitempty"`
+	// Rows is the number of rows in each existing table touched by the migration, before it runs.
+	Rows map[string]int64 `json:"rows"`
+	// Changes is the number of rows inserted, updated or deleted by the migration.
+	Changes int64 `json:"changes"`
+	// Availability tells whether readers can keep using the database while the migration runs,
+	// and BlockingReasons explains why they can't.
+	Availability    Availability `json:"availability"`
+	BlockingReasons []string     `json:"blocking_reasons,omitempty"`
+	// Irreversible reports, in plans of rollbacks, that the migration has no Down function or
+	// isn't defined in code, so it can't be rolled back.
+	Irreversible bool `json:"irreversible,omitempty"`
+}
+
+// Destructive reports whether the migration drops tables, indexes, views, triggers or columns, or
+// has statements flagged as destructive.
+func (m PlannedMigration) Destructive() bool {
+	return len(m.Dropped) > 0 || len(m.DroppedColumns) > 0 || len(m.DestructiveStatements) > 0
+}
+
+// Plan lists the pending migrations in the order they would be applied.
+type Plan struct {
+	// Direction is Up, except for the plans returned by PlanDown, which list the migrations to
+	// roll back with only their versions, descriptions, phases and whether they are irreversible.
+	Direction  Direction          `json:"direction"`
+	Migrations []PlannedMigration `json:"migrations"`
+}
+
+// Blocking reports whether any planned migration is blocking for readers.
+func (p *Plan) Blocking() bool {
+	for _, migration := range p.Migrations {
+		if migration.Availability == Blocking {
+			return true
+		}
+	}
+	return false
+}
+
+// Destructive reports whether any planned migration is destructive.
+func (p *Plan) Destructive() bool {
+	for _, migration := range p.Migrations {
+		if migration.Destructive() {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan returns the pending migrations along with an estimate of their impact. The estimate is
+// made by applying the migrations inside a transaction that is always rolled back, so the
+// database is left untouched. Side effects of Up functions outside the database are not undone.
+// The migrations run through the middleware, with their timeouts, and a panicking Up function
+// fails the plan with a PanicError. Options are applied as they would be by MigrateUp.
+func (db *Database) Plan(ctx context.Context, opts ...RunOption) (*Plan, error) {
+	cfg := db.runConfig(opts)
+
+	if err := db.migrations.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := db.checkAttached(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.checkVersions(db.migrations); err != nil {
+		return nil, err
+	}
+
+	tx, release, err := db.beginState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = db.createMigrationTable(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := db.getMigrationIndex(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := db.readSchema(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := journalMode(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := map[string]int64{}
+	for _, object := range schema {
+		if object.Type != "table" {
+			continue
+		}
+		if rows[object.Name], err = countRows(ctx, tx, object.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &Plan{Direction: Up}
+	run := db.runner()
+	migrations := cfg.limit(db.migrations.sorted())
+	selection := db.newSelection(cfg, index, migrations)
+	for _, migration := range migrations {
+		outcome, err := selection.decide(ctx, tx, migration)
+		if err != nil {
+			return nil, err
+		}
+		if outcome != outcomeApply {
+			continue
+		}
+
+		changes, err := totalChanges(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		columns := map[string][]string{}
+		for _, object := range schema {
+			if object.Type != "table" {
+				continue
+			}
+			if columns[object.Name], err = readColumns(ctx, tx, object.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := db.runWithTimeout(ctx, tx, run, Step{Migration: migration, Direction: Up}); err != nil {
+			return nil, errorf(CodeMigrationFailed, "failed to estimate migration (version=%v, description=%s): %w", migration.Version, migration.Description, err)
+		}
+
+		after, err := db.readSchema(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		planned := PlannedMigration{
+			Version:               migration.Version,
+			Description:           migration.Description,
+			Phase:                 migration.phase(),
+			Database:              migration.target(),
+			Rows:                  map[string]int64{},
+			DestructiveStatements: migration.DestructiveStatements,
+		}
+
+		if planned.Changes, err = totalChanges(ctx, tx); err != nil {
+			return nil, err
+		}
+		planned.Changes -= changes
+
+		for key, object := range after {
+			previous, existed := schema[key]
+			switch {
+			case !existed:
+				planned.Created = append(planned.Created, key)
+			case previous.SQL != object.SQL:
+				planned.Altered = append(planned.Altered, key)
+			}
+		}
+
+		for key, object := range schema {
+			if _, exists := after[key]; !exists {
+				planned.Dropped = append(planned.Dropped, key)
+			}
+			if object.Type != "table" {
+				continue
+			}
+
+			if _, touched := after[key]; !touched || contains(planned.Altered, key) {
+				planned.Rows[object.Name] = rows[object.Name]
+			}
+
+			if _, exists := after[key]; !exists {
+				continue
+			}
+
+			remaining, err := readColumns(ctx, tx, object.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, column := range columns[object.Name] {
+				if !contains(remaining, column) {
+					planned.DroppedColumns = append(planned.DroppedColumns, object.Name+"."+column)
+				}
+			}
+		}
+
+		sort.Strings(planned.Created)
+		sort.Strings(planned.Altered)
+		sort.Strings(planned.Dropped)
+		sort.Strings(planned.DroppedColumns)
+
+		db.classify(&planned, after, rows, mode == "wal")
+
+		for _, key := range append(planned.Created, planned.Altered...) {
+			if object := after[key]; object.Type == "table" {
+				if rows[object.Name], err = countRows(ctx, tx, object.Name); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		schema = after
+		plan.Migrations = append(plan.Migrations, planned)
+	}
+	return plan, nil
+}
+
+// totalChanges returns the number of rows modified on the connection since it was opened.
+func totalChanges(ctx context.Context, q queryer) (int64, error) {
+	rows, err := q.QueryContext(ctx, "SELECT total_changes();")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	changes := int64(0)
+	if rows.Next() {
+		if err := rows.Scan(&changes); err != nil {
+			return 0, err
+		}
+	}
+	return changes, rows.Err()
+}
+
+// PlanDown returns the migrations MigrateDown would roll back, in the order it would roll them
+// back, flagging the irreversible ones. Nothing is executed, so it previews a rollback without
+// touching the database.
+func (db *Database) PlanDown(ctx context.Context, amount int) (*Plan, error) {
+	if err := db.migrations.validate(); err != nil {
+		return nil, err
+	}
+
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := db.getMigrationRecords(ctx, q)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make([]int64, 0, len(records))
+	for version := range records {
+		index = append(index, version)
+	}
+	sort.Slice(index, func(i, j int) bool { return index[i] < index[j] })
+
+	if amount > len(index) {
+		amount = len(index)
+	}
+
+	plan := &Plan{Direction: Down, Migrations: make([]PlannedMigration, 0, amount)}
+	defined := db.migrations.byVersion()
+	for _, version := range db.migrations.rollbackOrder(index)[:amount] {
+		planned := PlannedMigration{Version: version, Description: records[version], Irreversible: true}
+		if migration, ok := defined[version]; ok {
+			planned.Phase = migration.phase()
+			planned.Irreversible = migration.Down == nil
+		}
+		plan.Migrations = append(plan.Migrations, planned)
+	}
+	return plan, nil
+}
+
+// Irreversible reports whether any planned migration can't be rolled back.
+func (p *Plan) Irreversible() bool {
+	for _, migration := range p.Migrations {
+		if migration.Irreversible {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestPlan(t *testing.T) {
+	migrations := &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Create users table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT);
+					INSERT INTO users (email) VALUES ('a@example.com'), ('b@example.com');
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE users;`)
+				return err
+			},
+		},
+	}
+
+	db, err := litemigrate.New(testDBPath, migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	*migrations = append(*migrations, litemigrate.Migration{
+		Version:     2,
+		Description: "Drop email column",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`ALTER TABLE users DROP COLUMN email;`)
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`ALTER TABLE users ADD COLUMN email TEXT;`)
+			return err
+		},
+	})
+
+	plan, err := db.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(plan.Migrations) != 1 {
+		t.Fatalf("expected 1 planned migration, got %d", len(plan.Migrations))
+	}
+
+	planned := plan.Migrations[0]
+	if !planned.Destructive() {
+		t.Error("expected destructive migration")
+	}
+
+	if len(planned.DroppedColumns) != 1 || planned.DroppedColumns[0] != "users.email" {
+		t.Errorf("expected dropped column users.email, got %v", planned.DroppedColumns)
+	}
+
+	if planned.Rows["users"] != 2 {
+		t.Errorf("expected 2 rows in users, got %d", planned.Rows["users"])
+	}
+
+	version, err := db.CurrentVersion(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 1 {
+		t.Errorf("expected plan to leave version 1, got %d", version)
+	}
+}
+
+func TestPlanAvailability(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT);
+		INSERT INTO users (email) VALUES ('a@example.com'), ('b@example.com'), ('c@example.com');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Index users email",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE INDEX users_email ON users (email);`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP INDEX users_email;`)
+				return err
+			},
+		},
+	})
+
+	plan, err := db.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if plan.Blocking() || plan.Migrations[0].Availability != litemigrate.OnlineSafe {
+		t.Errorf("expected online-safe migration, got %v", plan.Migrations[0].BlockingReasons)
+	}
+
+	plan, err = db.SetOnlineRowThreshold(2).Plan(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !plan.Blocking() || len(plan.Migrations[0].BlockingReasons) != 1 {
+		t.Errorf("expected blocking migration for an index on 3 rows, got %v", plan.Migrations[0].BlockingReasons)
+	}
+}
+
+func TestPlanDown(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	irreversible := tableMigration(2, "two")
+	irreversible.Down = nil
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one"), irreversible, tableMigration(3, "three")})
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	plan, err := db.PlanDown(ctx, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if plan.Direction != litemigrate.Down || len(plan.Migrations) != 2 {
+		t.Fatalf("expected 2 migrations to be rolled back, got %+v", plan)
+	}
+	if plan.Migrations[0].Version != 3 || plan.Migrations[0].Irreversible {
+		t.Errorf("expected reversible version 3 first, got %+v", plan.Migrations[0])
+	}
+	if plan.Migrations[1].Version != 2 || !plan.Migrations[1].Irreversible {
+		t.Errorf("expected irreversible version 2 second, got %+v", plan.Migrations[1])
+	}
+	if !plan.Irreversible() {
+		t.Error("expected the plan to be irreversible")
+	}
+
+	version, err := db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 3 {
+		t.Errorf("expected nothing to be rolled back, got version %d", version)
+	}
+}
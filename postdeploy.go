@@ -0,0 +1,18 @@
+package litemigrate
+
+import "context"
+
+// WithPostDeploy makes MigrateUp and Plan consider only the migrations marked PostDeploy, which
+// they skip otherwise. MigratePostDeploy is a shorthand for it.
+func WithPostDeploy() RunOption {
+	return func(c *runConfig) {
+		c.postDeploy = true
+	}
+}
+
+// MigratePostDeploy applies the pending migrations marked PostDeploy, such as long backfills that
+// should run once the new application code is live, and returns a report of the run. MigrateUp
+// skips these migrations.
+func (db *Database) MigratePostDeploy(ctx context.Context, opts ...RunOption) (*Result, error) {
+	return db.MigrateUp(ctx, append(opts, WithPostDeploy())...)
+}
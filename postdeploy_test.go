@@ -0,0 +1,64 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestMigratePostDeploy(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	backfill := tableMigration(2, "two")
+	backfill.PostDeploy = true
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		backfill,
+		tableMigration(3, "three"),
+	})
+
+	ctx := context.Background()
+	result, err := db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 2 || result.Applied[0] != 1 || result.Applied[1] != 3 {
+		t.Fatalf("expected versions 1 and 3 to be applied, got %v", result.Applied)
+	}
+
+	status, err := db.Status(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(status.Pending) != 1 || !status.Pending[0].PostDeploy {
+		t.Fatalf("expected the post-deploy migration to be pending, got %+v", status.Pending)
+	}
+
+	result, err = db.MigratePostDeploy(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 1 || result.Applied[0] != 2 {
+		t.Fatalf("expected version 2 to be applied, got %v", result.Applied)
+	}
+
+	result, err = db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 0 {
+		t.Fatalf("expected no migrations to be applied, got %v", result.Applied)
+	}
+}
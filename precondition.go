@@ -0,0 +1,50 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// TableExists returns a precondition that requires the table to exist.
+func TableExists(table string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		exists := false
+		err := tx.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?;", table).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("table %s doesn't exist", table)
+		}
+		return nil
+	}
+}
+
+// MaxRows returns a precondition that requires the table to have at most max rows, e.g. to keep a
+// migration that rewrites the table from locking the database for too long.
+func MaxRows(table string, max int64) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		var count int64
+		err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+quoteIdent(table)+";").Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count > max {
+			return fmt.Errorf("table %s has %d rows, more than %d", table, count, max)
+		}
+		return nil
+	}
+}
+
+// checkPrecondition returns an error if a pending migration's precondition isn't met.
+func checkPrecondition(ctx context.Context, tx *sql.Tx, migration Migration) error {
+	if migration.Precondition == nil {
+		return nil
+	}
+
+	if err := migration.Precondition(ctx, tx); err != nil {
+		return errorf(CodePreconditionFailed, "precondition of migration (version=%v, description=%s) isn't met: %w", migration.Version, migration.Description, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestPrecondition(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	rewrite := tableMigration(2, "two")
+	rewrite.Precondition = litemigrate.MaxRows("one", 0)
+
+	copyLegacy := tableMigration(3, "three")
+	copyLegacy.Precondition = litemigrate.TableExists("legacy")
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one"), rewrite, copyLegacy})
+	_, err = db.MigrateUp(ctx)
+	if code := litemigrate.Code(err); code != litemigrate.CodePreconditionFailed {
+		t.Fatalf("expected error code %s, got %v", litemigrate.CodePreconditionFailed, err)
+	}
+
+	version, err := db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 0 {
+		t.Fatalf("expected the run to be rolled back, got version %d", version)
+	}
+
+	if _, err := conn.Exec("CREATE TABLE legacy (id INTEGER PRIMARY KEY);"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 3 {
+		t.Fatalf("expected 3 migrations to be applied, got %v", result.Applied)
+	}
+}
@@ -0,0 +1,74 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// EventType identifies the kind of a progress event.
+type EventType string
+
+const (
+	// MigrationStarted is reported before a migration runs.
+	MigrationStarted EventType = "migration_started"
+	// MigrationFinished is reported after a migration ran, with its duration and error, if any.
+	// The changes are only kept if the run completes.
+	MigrationFinished EventType = "migration_finished"
+	// RunCompleted is reported when MigrateUp or MigrateDown ends, with the duration of the run, the
+	// number of migrations run and its error, if any.
+	RunCompleted EventType = "run_completed"
+	// WarningRaised is reported with every warning of a run. See Warning.
+	WarningRaised EventType = "warning_raised"
+)
+
+// Event reports the progress of a migration run.
+type Event struct {
+	Type        EventType
+	Direction   Direction
+	Version     int64
+	Description string
+	Duration    time.Duration
+	// Migrations is the number of migrations run. It is only set for RunCompleted.
+	Migrations int
+	// Warning is the warning reported. It is only set for WarningRaised.
+	Warning *Warning
+	Err     error
+}
+
+// SetProgressHandler sets the function called with the progress events of migration runs, e.g. to
+// display live progress in a UI or deploy tool. The handler is called synchronously from the run.
+func (db *Database) SetProgressHandler(handler func(Event)) *Database {
+	db.progressHandler = handler
+	return db
+}
+
+func (db *Database) progress(event Event) {
+	if db.progressHandler != nil {
+		db.progressHandler(event)
+	}
+}
+
+// runStep runs a migration step and reports its progress. It returns the duration of the step.
+func (db *Database) runStep(ctx context.Context, tx *sql.Tx, run Runner, step Step) (time.Duration, error) {
+	db.progress(Event{
+		Type:        MigrationStarted,
+		Direction:   step.Direction,
+		Version:     step.Migration.Version,
+		Description: step.Migration.Description,
+	})
+
+	start := time.Now()
+	err := db.runWithTimeout(ctx, tx, run, step)
+	elapsed := time.Since(start)
+
+	db.progress(Event{
+		Type:        MigrationFinished,
+		Direction:   step.Direction,
+		Version:     step.Migration.Version,
+		Description: step.Migration.Description,
+		Duration:    elapsed,
+		Err:         err,
+	})
+	return elapsed, err
+}
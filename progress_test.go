@@ -0,0 +1,64 @@
+package litemigrate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestProgressEvents(t *testing.T) {
+	db, err := litemigrate.New(testDBPath, &litemigrate.Migrations{
+		tableMigration(1, "users"),
+		tableMigration(2, "posts"),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	events := make([]litemigrate.Event, 0)
+	db.SetProgressHandler(func(e litemigrate.Event) {
+		events = append(events, e)
+	})
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := db.MigrateDown(context.Background(), 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []struct {
+		typ       litemigrate.EventType
+		direction litemigrate.Direction
+		version   int64
+	}{
+		{litemigrate.MigrationStarted, litemigrate.Up, 1},
+		{litemigrate.MigrationFinished, litemigrate.Up, 1},
+		{litemigrate.MigrationStarted, litemigrate.Up, 2},
+		{litemigrate.MigrationFinished, litemigrate.Up, 2},
+		{litemigrate.RunCompleted, litemigrate.Up, 0},
+		{litemigrate.MigrationStarted, litemigrate.Down, 2},
+		{litemigrate.MigrationFinished, litemigrate.Down, 2},
+		{litemigrate.RunCompleted, litemigrate.Down, 0},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(events), events)
+	}
+
+	for i, e := range expected {
+		if events[i].Type != e.typ || events[i].Direction != e.direction || events[i].Version != e.version {
+			t.Errorf("expected event %d to be %s %s (version=%v), got %+v", i, e.typ, e.direction, e.version, events[i])
+		}
+		if events[i].Err != nil {
+			t.Errorf("expected no error in event %d, got %v", i, events[i].Err)
+		}
+	}
+
+	if events[4].Migrations != 2 || events[7].Migrations != 1 {
+		t.Errorf("expected 2 and 1 migrations, got %d and %d", events[4].Migrations, events[7].Migrations)
+	}
+}
@@ -0,0 +1,24 @@
+package litemigrate
+
+// SetProtected makes MigrateDown fail before making changes, to protect databases such as
+// production ones from accidental rollbacks. WithProtected(false) overrides it for a run that
+// really has to roll back.
+func (db *Database) SetProtected(protected bool) *Database {
+	db.protected = protected
+	return db
+}
+
+// WithProtected overrides SetProtected for a run.
+func WithProtected(protected bool) RunOption {
+	return func(c *runConfig) {
+		c.protected = protected
+	}
+}
+
+// checkProtected returns an error if the run would roll back a protected database.
+func (c *runConfig) checkProtected() error {
+	if !c.protected {
+		return nil
+	}
+	return errorf(CodeProtected, "database is protected against rollbacks: override the protection for this run to roll back")
+}
@@ -0,0 +1,38 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestProtected(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one")}).SetProtected(true)
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = db.MigrateDown(ctx, 1)
+	if code := litemigrate.Code(err); code != litemigrate.CodeProtected {
+		t.Fatalf("expected error code %s, got %v", litemigrate.CodeProtected, err)
+	}
+
+	result, err := db.MigrateDown(ctx, 1, litemigrate.WithProtected(false))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.RolledBack) != 1 {
+		t.Errorf("expected 1 migration to be rolled back, got %v", result.RolledBack)
+	}
+}
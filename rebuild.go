@@ -0,0 +1,162 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// RebuildTable changes the definition of a table the way SQLite recommends for changes ALTER TABLE
+// can't make, such as changing the type or constraints of a column. It creates the table from
+// newDDL under a temporary name, copies the rows, drops the old table, renames the new one and
+// recreates the indexes, triggers and views that depended on the old table.
+//
+// newDDL is the complete CREATE TABLE statement of the table. copyExpr is the select list that
+// produces the rows of the new table from the old one, in the column order of newDDL, e.g.
+// "id, CAST(price AS INTEGER), NULL". When empty, the columns the old and new table have in common
+// are copied by name.
+//
+// Foreign key enforcement must be off, otherwise dropping the old table would delete or fail on
+// the rows referencing it; run the migration with SetForeignKeyMode(ForeignKeysDisabled). The
+// foreign keys of the rebuilt table are checked before RebuildTable returns.
+func RebuildTable(tx *sql.Tx, name, newDDL, copyExpr string) error {
+	ctx := context.Background()
+
+	var enforced bool
+	if err := tx.QueryRowContext(ctx, "PRAGMA foreign_keys;").Scan(&enforced); err != nil {
+		return err
+	}
+	if enforced {
+		return fmt.Errorf("failed to rebuild %s: foreign keys must be disabled, see SetForeignKeyMode", name)
+	}
+
+	open := strings.Index(newDDL, "(")
+	if open < 0 || !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(newDDL)), "CREATE TABLE") {
+		return fmt.Errorf("failed to rebuild %s: new definition must be a CREATE TABLE statement with a column list", name)
+	}
+
+	dependents, err := readDependents(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+
+	temporary := name + "_new"
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s %s", quoteIdent(temporary), newDDL[open:])); err != nil {
+		return fmt.Errorf("failed to create %s: %w", temporary, err)
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s SELECT %s FROM %s;", quoteIdent(temporary), copyExpr, quoteIdent(name))
+	if copyExpr == "" {
+		insert, err = copyCommonColumns(ctx, tx, name, temporary)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, insert); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", name, err)
+	}
+
+	for _, view := range dependents {
+		if view.Type != "view" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP VIEW %s;", quoteIdent(view.Name))); err != nil {
+			return fmt.Errorf("failed to drop view %s: %w", view.Name, err)
+		}
+	}
+
+	statements := []string{
+		fmt.Sprintf("DROP TABLE %s;", quoteIdent(name)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", quoteIdent(temporary), quoteIdent(name)),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to rebuild %s: %w", name, err)
+		}
+	}
+
+	for _, object := range dependents {
+		if _, err := tx.ExecContext(ctx, object.SQL); err != nil {
+			return fmt.Errorf("failed to recreate %s %s: %w", object.Type, object.Name, err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT 1 FROM pragma_foreign_key_check(?);", name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return errorf(CodeForeignKeyViolation, "failed to rebuild %s: rows violate its foreign keys", name)
+	}
+	return rows.Err()
+}
+
+// readDependents returns the indexes and triggers of a table and every view, in the order they
+// were created. Views are included whether or not they reference the table, since SQLite refuses
+// to rename a table while a view refers to a table that doesn't exist.
+func readDependents(ctx context.Context, q queryer, table string) ([]schemaObject, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT type, name, tbl_name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND (type = 'view' OR (type IN ('index', 'trigger') AND tbl_name = ?))
+		ORDER BY CASE type WHEN 'index' THEN 0 WHEN 'trigger' THEN 1 ELSE 2 END, rowid;
+	`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dependents of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	objects := make([]schemaObject, 0)
+	for rows.Next() {
+		var object schemaObject
+		if err := rows.Scan(&object.Type, &object.Name, &object.Table, &object.SQL); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return objects, nil
+}
+
+// copyCommonColumns returns the statement that copies the columns two tables have in common.
+func copyCommonColumns(ctx context.Context, q queryer, from, to string) (string, error) {
+	columns, err := commonColumns(ctx, q, from, to)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", quoteIdent(to), columns, columns, quoteIdent(from)), nil
+}
+
+// commonColumns returns the quoted, comma-separated list of the columns two tables have in common,
+// in the column order of the second table.
+func commonColumns(ctx context.Context, q queryer, from, to string) (string, error) {
+	current, err := readTableColumns(ctx, q, from)
+	if err != nil {
+		return "", err
+	}
+
+	desired, err := readTableColumns(ctx, q, to)
+	if err != nil {
+		return "", err
+	}
+
+	common := make([]string, 0)
+	for _, column := range desired {
+		for _, existing := range current {
+			if strings.EqualFold(existing.Name, column.Name) {
+				common = append(common, quoteIdent(column.Name))
+				break
+			}
+		}
+	}
+
+	if len(common) == 0 {
+		return "", fmt.Errorf("failed to copy %s: %s has no column in common with it", from, to)
+	}
+	return strings.Join(common, ", "), nil
+}
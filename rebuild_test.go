@@ -0,0 +1,161 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestRebuildTable(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price TEXT);
+		CREATE INDEX products_name ON products (name);
+		CREATE TABLE audit (product_id INTEGER);
+		CREATE TRIGGER products_audit AFTER INSERT ON products BEGIN INSERT INTO audit VALUES (new.id); END;
+		CREATE VIEW cheap_products AS SELECT id FROM products WHERE price < 10;
+		INSERT INTO products (id, name, price) VALUES (1, 'pen', '2'), (2, 'book', '12');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rebuild := litemigrate.Migration{
+		Version:     1,
+		Description: "Make price an integer",
+		Up: func(tx *sql.Tx) error {
+			return litemigrate.RebuildTable(tx, "products",
+				"CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL, price INTEGER NOT NULL)",
+				"id, name, CAST(price AS INTEGER)",
+			)
+		},
+		Down: func(tx *sql.Tx) error { return nil },
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{rebuild})
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	typ := ""
+	if err := conn.QueryRow("SELECT type FROM pragma_table_info('products') WHERE name = 'price';").Scan(&typ); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if typ != "INTEGER" {
+		t.Errorf("expected price to be an INTEGER, got %s", typ)
+	}
+
+	for _, name := range []string{"products_name", "products_audit", "cheap_products"} {
+		count := 0
+		if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = ?;", name).Scan(&count); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected %s to be recreated", name)
+		}
+	}
+
+	cheap := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM cheap_products;").Scan(&cheap); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cheap != 1 {
+		t.Errorf("expected 1 cheap product, got %d", cheap)
+	}
+
+	if _, err := conn.Exec("INSERT INTO products (id, name, price) VALUES (3, 'cup', 5);"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	audited := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM audit;").Scan(&audited); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if audited != 3 {
+		t.Errorf("expected 3 audited products, got %d", audited)
+	}
+}
+
+func TestRebuildTableCommonColumns(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, legacy TEXT);
+		INSERT INTO users (id, name, legacy) VALUES (1, 'alice', 'x');
+	`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rebuild := litemigrate.Migration{
+		Version:     1,
+		Description: "Drop legacy column",
+		Up: func(tx *sql.Tx) error {
+			return litemigrate.RebuildTable(tx, "users", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT UNIQUE)", "")
+		},
+		Down: func(tx *sql.Tx) error { return nil },
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{rebuild})
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	name := ""
+	if err := conn.QueryRow("SELECT name FROM users WHERE id = 1;").Scan(&name); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if name != "alice" {
+		t.Errorf("expected alice, got %s", name)
+	}
+}
+
+func TestRebuildTableForeignKeysEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	if _, err := conn.Exec("PRAGMA foreign_keys = ON; CREATE TABLE users (id INTEGER PRIMARY KEY);"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rebuild := litemigrate.Migration{
+		Version:     1,
+		Description: "Rebuild users table",
+		Up: func(tx *sql.Tx) error {
+			return litemigrate.RebuildTable(tx, "users", "CREATE TABLE users (id INTEGER PRIMARY KEY NOT NULL)", "")
+		},
+		Down: func(tx *sql.Tx) error { return nil },
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{rebuild})
+	if _, err := db.MigrateUp(ctx); err == nil {
+		t.Fatal("expected an error while foreign keys are enforced")
+	}
+
+	db.SetForeignKeyMode(litemigrate.ForeignKeysDisabled)
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package litemigrate
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   Migrations
+)
+
+// Register adds a migration to the package-level registry, so every migration can live in its own
+// file and register itself in an init function instead of being listed in one slice literal:
+//
+//	func init() {
+//		litemigrate.Register(litemigrate.Migration{Version: 20240612153000, ...})
+//	}
+//
+// Like database/sql.Register, it panics if the version is already registered.
+func Register(migration Migration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, registered := range registry {
+		if registered.Version == migration.Version {
+			panic(fmt.Sprintf("litemigrate: Register called twice for version %v (description=%s)", migration.Version, migration.Description))
+		}
+	}
+	registry = append(registry, migration)
+}
+
+// Registered returns the registered migrations ordered by version.
+func Registered() *Migrations {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	migrations := Migrations(registry.sorted())
+	return &migrations
+}
@@ -0,0 +1,37 @@
+package litemigrate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func init() {
+	litemigrate.Register(tableMigration(2, "registered_two"))
+	litemigrate.Register(tableMigration(1, "registered_one"))
+}
+
+func TestRegister(t *testing.T) {
+	migrations := litemigrate.Registered()
+	if len(*migrations) != 2 || (*migrations)[0].Version != 1 || (*migrations)[1].Version != 2 {
+		t.Fatalf("expected versions 1 and 2 in order, got %v", *migrations)
+	}
+
+	db, err := litemigrate.New(testDBPath, migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a duplicate version, got none")
+		}
+	}()
+	litemigrate.Register(tableMigration(1, "duplicate"))
+}
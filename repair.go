@@ -0,0 +1,71 @@
+package litemigrate
+
+import (
+	"context"
+	"log"
+	"sort"
+)
+
+// Repair removes records from the migration table whose versions are no longer defined in code,
+// and returns the removed versions. If confirm is not nil, it is called with the versions that
+// would be removed and nothing is changed unless it returns true.
+func (db *Database) Repair(ctx context.Context, confirm func(versions []int64) (bool, error)) ([]int64, error) {
+	if err := db.migrations.validate(); err != nil {
+		return nil, err
+	}
+
+	tx, release, err := db.beginState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = db.createMigrationTable(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := db.getMigrationRecords(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := map[int64]bool{}
+	for _, migration := range *db.migrations {
+		defined[migration.Version] = true
+	}
+
+	orphans := make([]int64, 0)
+	for version := range records {
+		if !defined[version] {
+			orphans = append(orphans, version)
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i] < orphans[j] })
+
+	if len(orphans) == 0 {
+		return orphans, nil
+	}
+
+	if confirm != nil {
+		ok, err := confirm(orphans)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, nil
+		}
+	}
+
+	for _, version := range orphans {
+		if err := db.deleteMigration(ctx, tx, version); err != nil {
+			return nil, err
+		}
+		log.Printf("removed orphan migration record (version=%v, description=%s)", version, records[version])
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return orphans, nil
+}
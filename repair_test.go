@@ -0,0 +1,58 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestRepair(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		tableMigration(3, "three"),
+	}).MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one")})
+
+	removed, err := db.Repair(context.Background(), func(versions []int64) (bool, error) {
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(removed) != 0 {
+		t.Errorf("expected nothing removed without confirmation, got %v", removed)
+	}
+
+	removed, err = db.Repair(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(removed) != 2 || removed[0] != 2 || removed[1] != 3 {
+		t.Errorf("expected removed [2 3], got %v", removed)
+	}
+
+	report, err := db.Validate(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !report.Valid() {
+		t.Errorf("expected valid report after repair, got %v", report)
+	}
+}
@@ -0,0 +1,137 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Repeatable is a migration that runs again whenever its checksum changes instead of once, like
+// Flyway's R__ migrations. It suits objects that are recreated as a whole, such as views, triggers
+// or lookup data, so its Up function must be safe to run repeatedly. Repeatable migrations run after
+// the versioned migrations, in the order of their names.
+type Repeatable struct {
+	Name string
+	// Checksum identifies the content of the migration, e.g. a hash of its SQL.
+	Checksum string
+	Up       func(tx *sql.Tx) error
+}
+
+// SetRepeatables sets the repeatable migrations run by MigrateUp. Their checksums are recorded in
+// a second table named after the migration table with a "_repeatable" suffix. They are limited by
+// the timeout set with SetMigrationTimeout, and a panic fails the run with a PanicError.
+func (db *Database) SetRepeatables(repeatables ...Repeatable) *Database {
+	db.repeatables = repeatables
+	return db
+}
+
+func (db *Database) repeatableTable() string {
+	return db.migrationTable + "_repeatable"
+}
+
+// validateRepeatables checks that every repeatable migration is well-formed and that no name is
+// used twice.
+func (db *Database) validateRepeatables() error {
+	names := map[string]bool{}
+	for _, repeatable := range db.repeatables {
+		if repeatable.Name == "" || repeatable.Checksum == "" || repeatable.Up == nil {
+			return errorf(CodeInvalidRepeatable, "invalid repeatable migration: name, checksum and up must be set")
+		}
+
+		if names[repeatable.Name] {
+			return errorf(CodeInvalidRepeatable, "duplicate repeatable migration: (name=%s) already exists", repeatable.Name)
+		}
+		names[repeatable.Name] = true
+	}
+	return nil
+}
+
+// runUp runs the Up function of a step, without middleware.
+func runUp(ctx context.Context, tx *sql.Tx, step Step) error {
+	return step.Migration.Up(tx)
+}
+
+// runRepeatables runs the repeatable migrations that are new or whose checksum changed.
+func (db *Database) runRepeatables(ctx context.Context, tx *sql.Tx) error {
+	if len(db.repeatables) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			name TEXT PRIMARY KEY NOT NULL,
+			checksum TEXT NOT NULL
+		);
+	`, db.qualify(db.repeatableTable())))
+	if err != nil {
+		return errorf(CodeMigrationTable, "failed to create repeatable migration table: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT name, checksum FROM %s;", db.qualify(db.repeatableTable())))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	checksums := map[string]string{}
+	for rows.Next() {
+		var name, checksum string
+		if err := rows.Scan(&name, &checksum); err != nil {
+			return err
+		}
+		checksums[name] = checksum
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan rows: %w", err)
+	}
+
+	repeatables := make([]Repeatable, len(db.repeatables))
+	copy(repeatables, db.repeatables)
+	sort.Slice(repeatables, func(i, j int) bool { return repeatables[i].Name < repeatables[j].Name })
+
+	query := fmt.Sprintf("INSERT OR REPLACE INTO %s (name, checksum) VALUES (?, ?);", db.qualify(db.repeatableTable()))
+	for _, repeatable := range repeatables {
+		if checksums[repeatable.Name] == repeatable.Checksum {
+			continue
+		}
+
+		step := Step{Migration: Migration{Description: repeatable.Name, Up: repeatable.Up}, Direction: Up}
+		if err := db.runWithTimeout(ctx, tx, runUp, step); err != nil {
+			return errorf(CodeMigrationFailed, "repeatable migration (name=%s) failed: %w", repeatable.Name, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, repeatable.Name, repeatable.Checksum); err != nil {
+			return errorf(CodeMigrationTable, "failed to record repeatable migration (name=%s): %w", repeatable.Name, err)
+		}
+
+		log.Printf("ran repeatable migration (name=%s, checksum=%s)", repeatable.Name, repeatable.Checksum)
+	}
+	return nil
+}
+
+// clearRepeatables deletes the recorded checksums of the repeatable migrations, so that the next run
+// runs them all again.
+func (db *Database) clearRepeatables(ctx context.Context) error {
+	if len(db.repeatables) == 0 {
+		return nil
+	}
+
+	tx, release, err := db.beginState(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	exists, err := db.stateTableExists(ctx, tx, db.repeatableTable())
+	if err != nil || !exists {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s;", db.qualify(db.repeatableTable()))); err != nil {
+		return errorf(CodeMigrationTable, "failed to clear repeatable migrations: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,87 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestRepeatable(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	runs := 0
+	view := func(checksum, query string) litemigrate.Repeatable {
+		return litemigrate.Repeatable{
+			Name:     "user ids",
+			Checksum: checksum,
+			Up: func(tx *sql.Tx) error {
+				runs++
+				_, err := tx.Exec(`DROP VIEW IF EXISTS user_ids; CREATE VIEW user_ids AS ` + query + `;`)
+				return err
+			},
+		}
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users")})
+
+	db.SetRepeatables(view("a", "SELECT id FROM users"))
+	for i := 0; i < 2; i++ {
+		if _, err := db.MigrateUp(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if runs != 1 {
+		t.Errorf("expected 1 run with an unchanged checksum, got %d", runs)
+	}
+
+	db.SetRepeatables(view("b", "SELECT id FROM users ORDER BY id"))
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if runs != 2 {
+		t.Errorf("expected 2 runs after the checksum changed, got %d", runs)
+	}
+
+	db.SetRepeatables(view("c", "SELECT id FROM users"), view("d", "SELECT id FROM users"))
+	if _, err := db.MigrateUp(context.Background()); err == nil {
+		t.Error("expected error for duplicate repeatable migration, got nil")
+	}
+}
+
+func TestRepeatablePanic(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users")}).
+		SetRepeatables(litemigrate.Repeatable{
+			Name:     "seed",
+			Checksum: "a",
+			Up: func(tx *sql.Tx) error {
+				panic("boom")
+			},
+		})
+
+	_, err = db.MigrateUp(context.Background())
+	var panicErr *litemigrate.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a panic error, got %v", err)
+	}
+	if litemigrate.Code(err) != litemigrate.CodeMigrationFailed || !strings.Contains(err.Error(), "name=seed") {
+		t.Errorf("expected a failed migration naming the repeatable, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package litemigrate
+
+import "context"
+
+// Reset rolls back every applied migration and applies them all again, along with every repeatable
+// migration, e.g. to start over while iterating on a schema locally. It returns the report of the reapplying run, with the versions
+// rolled back before it in RolledBack. The two runs are separate transactions, so a failure while
+// reapplying leaves the database rolled back. Like MigrateDown, it fails if the database is
+// protected or a migration is irreversible. Options apply to both runs, except that dry runs
+// aren't supported.
+func (db *Database) Reset(ctx context.Context, opts ...RunOption) (*Result, error) {
+	if db.runConfig(opts).dryRun {
+		return nil, errorf(CodeInvalidOption, "reset doesn't support dry runs")
+	}
+
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := db.getMigrationRecords(ctx, q)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	var down *Result
+	if len(records) > 0 {
+		down, err = db.MigrateDown(ctx, len(records), opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.clearRepeatables(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := db.MigrateUp(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if down != nil {
+		result.RolledBack = down.RolledBack
+		result.Duration += down.Duration
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestReset(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	seeds := 0
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one"), tableMigration(2, "two")}).
+		SetRepeatables(litemigrate.Repeatable{
+			Name:     "seed",
+			Checksum: "a",
+			Up: func(tx *sql.Tx) error {
+				seeds++
+				_, err := tx.Exec("INSERT INTO two (id) VALUES (1);")
+				return err
+			},
+		})
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := conn.Exec("INSERT INTO one (id) VALUES (1);"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := db.Reset(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.RolledBack) != 2 || result.RolledBack[0] != 2 || result.RolledBack[1] != 1 {
+		t.Errorf("expected versions 2 and 1 to be rolled back, got %v", result.RolledBack)
+	}
+	if len(result.Applied) != 2 || result.Version != 2 {
+		t.Errorf("expected versions 1 and 2 to be applied, got %v (version %d)", result.Applied, result.Version)
+	}
+
+	rows := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM one;").Scan(&rows); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rows != 0 {
+		t.Errorf("expected the table to be recreated empty, got %d rows", rows)
+	}
+
+	if err := conn.QueryRow("SELECT COUNT(*) FROM two;").Scan(&rows); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if seeds != 2 || rows != 1 {
+		t.Errorf("expected the repeatable migration to run again, got %d runs and %d rows", seeds, rows)
+	}
+
+	db.SetProtected(true)
+	if _, err := db.Reset(ctx); litemigrate.Code(err) != litemigrate.CodeProtected {
+		t.Errorf("expected error code %s, got %v", litemigrate.CodeProtected, err)
+	}
+
+	if _, err := db.Reset(ctx, litemigrate.WithDryRun()); err == nil {
+		t.Error("expected error for a dry run, got nil")
+	}
+}
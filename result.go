@@ -0,0 +1,62 @@
+package litemigrate
+
+import "time"
+
+// Result reports what a migration run did, so callers don't have to query the database again
+// after a run.
+type Result struct {
+	Direction Direction `json:"direction"`
+	// Applied are the versions migrated up, and RolledBack the versions migrated down, in the order
+	// they ran.
+	Applied    []int64 `json:"applied"`
+	RolledBack []int64 `json:"rolled_back"`
+	// Skipped are the versions that were already applied, and Adopted the versions recorded as
+	// applied without running them. See Database.SetAdoptExisting.
+	Skipped []int64 `json:"skipped"`
+	Adopted []int64 `json:"adopted"`
+	// Unmet are the versions whose Condition wasn't met. They weren't run or recorded.
+	Unmet []int64 `json:"unmet"`
+	// Excluded are the pending versions bypassed because they were excluded. See
+	// Database.SetExcludedVersions.
+	Excluded []int64 `json:"excluded"`
+	// Deferred are the pending versions that require a newer application version. See
+	// WithAppVersion.
+	Deferred []int64 `json:"deferred"`
+	// Failed is the version of the migration that failed when the ones applied before it were
+	// kept. See Database.SetKeepApplied.
+	Failed int64 `json:"failed,omitempty"`
+	// Warnings are the warnings reported during the run. See Database.SetWarningHandler.
+	Warnings []Warning `json:"warnings"`
+	// Durations are the durations of the migrations that ran, by version.
+	Durations map[int64]time.Duration `json:"durations"`
+	// Version is the current version after the run.
+	Version  int64         `json:"version"`
+	Duration time.Duration `json:"duration"`
+	// DryRun reports that the run's changes were rolled back. See WithDryRun.
+	DryRun bool `json:"dry_run"`
+}
+
+func newResult(direction Direction) *Result {
+	return &Result{
+		Direction:  direction,
+		Applied:    make([]int64, 0),
+		RolledBack: make([]int64, 0),
+		Skipped:    make([]int64, 0),
+		Adopted:    make([]int64, 0),
+		Unmet:      make([]int64, 0),
+		Excluded:   make([]int64, 0),
+		Deferred:   make([]int64, 0),
+		Warnings:   make([]Warning, 0),
+		Durations:  map[int64]time.Duration{},
+	}
+}
+
+// setVersion sets the version after an up run from the latest version before it.
+func (r *Result) setVersion(latest int64) {
+	r.Version = latest
+	for _, version := range append(r.Applied, r.Adopted...) {
+		if version > r.Version {
+			r.Version = version
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestResult(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one")}).MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		tableMigration(3, "three"),
+	})
+
+	result, err := db.MigrateUp(ctx, litemigrate.WithDryRun())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.DryRun || result.Version != 3 {
+		t.Fatalf("expected a dry run up to version 3, got %+v", result)
+	}
+
+	result, err = db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.Direction != litemigrate.Up || result.DryRun {
+		t.Fatalf("expected a committed up run, got %+v", result)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != 1 {
+		t.Fatalf("expected version 1 to be skipped, got %v", result.Skipped)
+	}
+	if len(result.Applied) != 2 || result.Applied[0] != 2 || result.Applied[1] != 3 {
+		t.Fatalf("expected versions 2 and 3 to be applied, got %v", result.Applied)
+	}
+	if _, ok := result.Durations[3]; !ok || len(result.Durations) != 2 {
+		t.Fatalf("expected durations for versions 2 and 3, got %v", result.Durations)
+	}
+	if result.Version != 3 {
+		t.Fatalf("expected version 3, got %d", result.Version)
+	}
+
+	result, err = db.MigrateDown(ctx, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.RolledBack) != 2 || result.RolledBack[0] != 3 || result.RolledBack[1] != 2 {
+		t.Fatalf("expected versions 3 and 2 to be rolled back, got %v", result.RolledBack)
+	}
+	if result.Version != 1 {
+		t.Fatalf("expected version 1, got %d", result.Version)
+	}
+}
@@ -0,0 +1,170 @@
+package litemigrate
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RunOption overrides the database's settings for a single migration run, so one Database can
+// serve runs with different behaviors.
+type RunOption func(*runConfig)
+
+type runConfig struct {
+	dryRun           bool
+	maxVersion       int64
+	allowedRoles     []string
+	adoptExisting    bool
+	tags             []string
+	excludedTags     []string
+	maxDuration      time.Duration
+	allowDestructive bool
+	awaitTimeout     time.Duration
+	phase            Phase
+	postDeploy       bool
+	protected        bool
+	keepApplied      bool
+	commitEach       bool
+	versions         []int64
+	excludedVersions []int64
+	appVersion       string
+}
+
+// WithDryRun makes MigrateUp apply the migrations inside a transaction that is rolled back, so the
+// database is left untouched. Side effects of Up functions outside the database are not undone.
+func WithDryRun() RunOption {
+	return func(c *runConfig) {
+		c.dryRun = true
+	}
+}
+
+// WithMaxVersion makes MigrateUp and Plan ignore migrations with a higher version.
+func WithMaxVersion(version int64) RunOption {
+	return func(c *runConfig) {
+		c.maxVersion = version
+	}
+}
+
+// WithAllowedRoles overrides SetAllowedRoles for a run.
+func WithAllowedRoles(roles ...string) RunOption {
+	return func(c *runConfig) {
+		c.allowedRoles = roles
+	}
+}
+
+// WithAdoptExisting overrides SetAdoptExisting for a run.
+func WithAdoptExisting(adopt bool) RunOption {
+	return func(c *runConfig) {
+		c.adoptExisting = adopt
+	}
+}
+
+// WithTags makes MigrateUp and Plan ignore migrations without any of the tags, e.g. to run heavy
+// data backfills separately from schema changes.
+func WithTags(tags ...string) RunOption {
+	return func(c *runConfig) {
+		c.tags = tags
+	}
+}
+
+// WithoutTags makes MigrateUp and Plan ignore migrations with any of the tags.
+func WithoutTags(tags ...string) RunOption {
+	return func(c *runConfig) {
+		c.excludedTags = tags
+	}
+}
+
+// WithMaxDuration makes MigrateUp and MigrateDown roll back and fail if the run doesn't complete
+// within the duration, e.g. to enforce that deployments finish their migrations within five
+// minutes. Unlike a deadline on the caller's context, it only covers the migration transaction,
+// so backups can still be restored once it expires.
+func WithMaxDuration(d time.Duration) RunOption {
+	return func(c *runConfig) {
+		c.maxDuration = d
+	}
+}
+
+// runConfig returns the database's settings with the options applied.
+func (db *Database) runConfig(opts []RunOption) *runConfig {
+	c := &runConfig{
+		allowedRoles:     db.allowedRoles,
+		adoptExisting:    db.adoptExisting,
+		allowDestructive: db.allowDestructive,
+		awaitTimeout:     db.awaitTimeout,
+		protected:        db.protected,
+		keepApplied:      db.keepApplied,
+		commitEach:       db.commitEach,
+		excludedVersions: db.excludedVersions,
+	}
+	if db.appliedBy != nil {
+		c.appVersion = db.appliedBy.AppVersion
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// limit returns the sorted migrations up to the run's maximum version that match its selected
+// versions, phase, tags and whether it runs post-deploy migrations, without the excluded versions.
+func (c *runConfig) limit(migrations []Migration) []Migration {
+	limited := make([]Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		if c.maxVersion != 0 && migration.Version > c.maxVersion {
+			continue
+		}
+		if len(c.versions) > 0 {
+			if !contains(c.versions, migration.Version) {
+				continue
+			}
+		} else if migration.PostDeploy != c.postDeploy {
+			continue
+		}
+		if contains(c.excludedVersions, migration.Version) {
+			continue
+		}
+		if c.phase != "" && migration.phase() != c.phase {
+			continue
+		}
+		if c.matchesTags(migration) {
+			limited = append(limited, migration)
+		}
+	}
+	return limited
+}
+
+func (c *runConfig) matchesTags(migration Migration) bool {
+	for _, tag := range c.excludedTags {
+		if contains(migration.Tags, tag) {
+			return false
+		}
+	}
+
+	if len(c.tags) == 0 {
+		return true
+	}
+	for _, tag := range c.tags {
+		if contains(migration.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// deadline returns the context of the run's transaction, which expires when the run exceeds its
+// maximum duration.
+func (c *runConfig) deadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.maxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.maxDuration)
+}
+
+// deadlineError explains an error caused by the run exceeding its maximum duration, unless the
+// caller's context expired.
+func (c *runConfig) deadlineError(ctx context.Context, err error) error {
+	if c.maxDuration > 0 && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return errorf(CodeMaxDuration, "run exceeded its maximum duration of %s: %w", c.maxDuration, err)
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestRunOptions(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	destructive := tableMigration(3, "three")
+	destructive.Role = "destructive"
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		destructive,
+	}).SetAllowedRoles("ddl")
+
+	ctx := context.Background()
+	if _, err := db.MigrateUp(ctx, litemigrate.WithDryRun()); err == nil {
+		t.Fatal("expected error for disallowed role, got nil")
+	}
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithDryRun(), litemigrate.WithMaxVersion(2)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	version, err := db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 0 {
+		t.Errorf("expected version 0 after a dry run, got %d", version)
+	}
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(2)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version, _ := db.CurrentVersion(ctx); version != 2 {
+		t.Errorf("expected version 2, got %d", version)
+	}
+
+	if _, err := db.MigrateUp(ctx, litemigrate.WithAllowedRoles("destructive")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version, _ := db.CurrentVersion(ctx); version != 3 {
+		t.Errorf("expected version 3, got %d", version)
+	}
+
+	if _, err := db.MigrateDown(ctx, 1); err == nil {
+		t.Error("expected error for disallowed role after the run, got nil")
+	}
+}
+
+func TestTagOptions(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	backfill := tableMigration(2, "two")
+	backfill.Tags = []string{"data", "slow"}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		backfill,
+		tableMigration(3, "three"),
+	})
+
+	ctx := context.Background()
+	result, err := db.MigrateUp(ctx, litemigrate.WithoutTags("slow"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 2 || result.Applied[0] != 1 || result.Applied[1] != 3 {
+		t.Fatalf("expected versions 1 and 3 to be applied, got %v", result.Applied)
+	}
+
+	result, err = db.MigrateUp(ctx, litemigrate.WithTags("data"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Applied) != 1 || result.Applied[0] != 2 {
+		t.Fatalf("expected version 2 to be applied, got %v", result.Applied)
+	}
+}
@@ -0,0 +1,46 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// errSavepoint reports that a failed migration couldn't be rolled back to its savepoint, so none
+// of the run's changes can be kept.
+var errSavepoint = errors.New("failed to roll back to savepoint")
+
+// SetKeepApplied makes MigrateUp commit the migrations applied before a failed one, instead of
+// rolling back the whole run. Every migration runs inside a savepoint of the run's transaction, so
+// the failed migration is rolled back either way. Repeatable migrations don't run after a failure.
+func (db *Database) SetKeepApplied(keep bool) *Database {
+	db.keepApplied = keep
+	return db
+}
+
+// WithKeepApplied overrides SetKeepApplied for a run.
+func WithKeepApplied(keep bool) RunOption {
+	return func(c *runConfig) {
+		c.keepApplied = keep
+	}
+}
+
+// savepoint runs f inside a savepoint of the transaction, and rolls back to it if f fails, leaving
+// the changes made before it intact.
+func savepoint(ctx context.Context, tx *sql.Tx, name string, f func() error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name+";"); err != nil {
+		return err
+	}
+
+	if err := f(); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO "+name+";"); rollbackErr != nil {
+			return fmt.Errorf("%w; %w: %v", err, errSavepoint, rollbackErr)
+		}
+		tx.ExecContext(ctx, "RELEASE "+name+";")
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "RELEASE "+name+";")
+	return err
+}
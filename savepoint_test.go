@@ -0,0 +1,67 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestKeepApplied(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	failing := tableMigration(2, "two")
+	up := failing.Up
+	failing.Up = func(tx *sql.Tx) error {
+		if err := up(tx); err != nil {
+			return err
+		}
+		return errors.New("backfill failed")
+	}
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "one"), failing, tableMigration(3, "three")})
+	if _, err := db.MigrateUp(ctx); litemigrate.Code(err) != litemigrate.CodeMigrationFailed {
+		t.Fatalf("expected error code %s, got %v", litemigrate.CodeMigrationFailed, err)
+	}
+
+	version, err := db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version != 0 {
+		t.Fatalf("expected the whole run to be rolled back, got version %d", version)
+	}
+
+	result, err := db.MigrateUp(ctx, litemigrate.WithKeepApplied(true))
+	if litemigrate.Code(err) != litemigrate.CodeMigrationFailed {
+		t.Fatalf("expected error code %s, got %v", litemigrate.CodeMigrationFailed, err)
+	}
+
+	if result == nil || len(result.Applied) != 1 || result.Applied[0] != 1 || result.Failed != 2 || result.Version != 1 {
+		t.Fatalf("expected version 1 to be kept and version 2 to fail, got %+v", result)
+	}
+
+	version, err = db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+
+	exists := false
+	if err := conn.QueryRow("SELECT COUNT(*) > 0 FROM sqlite_master WHERE name = 'two';").Scan(&exists); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if exists {
+		t.Error("expected the failed migration to be rolled back to its savepoint")
+	}
+}
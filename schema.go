@@ -0,0 +1,155 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// schemaObject is a single entry of sqlite_master.
+type schemaObject struct {
+	Type  string
+	Name  string
+	Table string
+	SQL   string
+}
+
+// String returns the object as "type name", e.g. "table users".
+func (o schemaObject) String() string {
+	return o.Type + " " + o.Name
+}
+
+// readSchema returns the user-defined schema objects keyed by "type name", excluding
+// SQLite's internal objects and the migration tables of every namespace.
+func (db *Database) readSchema(ctx context.Context, q queryer) (map[string]schemaObject, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT type, name, tbl_name, COALESCE(sql, '')
+		FROM sqlite_master
+		WHERE name NOT LIKE 'sqlite_%' AND tbl_name != ? AND tbl_name NOT LIKE ? ESCAPE '\';
+	`, db.rootTable(), db.bookkeepingPattern())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	defer rows.Close()
+
+	objects := map[string]schemaObject{}
+	for rows.Next() {
+		var object schemaObject
+		if err := rows.Scan(&object.Type, &object.Name, &object.Table, &object.SQL); err != nil {
+			return nil, err
+		}
+		objects[object.String()] = object
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return objects, nil
+}
+
+// tableExists reports whether the table exists.
+func tableExists(ctx context.Context, q queryer, table string) (bool, error) {
+	rows, err := q.QueryContext(ctx, "SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?;", table)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	exists := rows.Next()
+	return exists, rows.Err()
+}
+
+// readColumns returns the column names of a table in declaration order.
+func readColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_table_info(?);", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make([]string, 0)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return columns, nil
+}
+
+// countRows returns the number of rows in a table.
+func countRows(ctx context.Context, q queryer, table string) (int64, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s;", quoteIdent(table)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	count := int64(0)
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, err
+		}
+	}
+	return count, rows.Err()
+}
+
+// quoteIdent quotes an identifier for use in a SQL statement.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Column describes a table column as reported by pragma_table_info.
+type Column struct {
+	Name string
+	// Type is the declared type, which is empty for columns declared without one.
+	Type    string
+	NotNull bool
+	// Default is the SQL expression of the column's default value.
+	Default    sql.NullString
+	PrimaryKey bool
+}
+
+// definition returns the column definition as used by ALTER TABLE ... ADD COLUMN.
+func (c Column) definition() string {
+	definition := quoteIdent(c.Name)
+	if c.Type != "" {
+		definition += " " + c.Type
+	}
+	if c.NotNull {
+		definition += " NOT NULL"
+	}
+	if c.Default.Valid {
+		definition += " DEFAULT " + c.Default.String
+	}
+	return definition
+}
+
+// readTableColumns returns the columns of a table in declaration order.
+func readTableColumns(ctx context.Context, q queryer, table string) ([]Column, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?);", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make([]Column, 0)
+	for rows.Next() {
+		var c Column
+		if err := rows.Scan(&c.Name, &c.Type, &c.NotNull, &c.Default, &c.PrimaryKey); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return columns, nil
+}
@@ -0,0 +1,101 @@
+package litemigrate
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretProvider resolves named secrets, such as encryption keys or auth tokens, at run time.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// SecretFunc adapts a function, such as a call to a key management service, to a SecretProvider.
+type SecretFunc func(ctx context.Context, name string) (string, error)
+
+// Secret calls f(ctx, name).
+func (f SecretFunc) Secret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// EnvSecrets resolves secrets from environment variables.
+type EnvSecrets struct{}
+
+// Secret returns the value of the environment variable with the given name.
+func (EnvSecrets) Secret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", errorf(CodeInvalidDSN, "secret %s: environment variable is not set", name)
+	}
+	return value, nil
+}
+
+// FileSecrets resolves secrets from files in a directory, one secret per file, as used by
+// Docker and Kubernetes secret mounts. Trailing newlines are removed.
+type FileSecrets struct {
+	Dir string
+}
+
+// Secret returns the contents of the file with the given name.
+func (s FileSecrets) Secret(_ context.Context, name string) (string, error) {
+	if name != filepath.Base(name) {
+		return "", errorf(CodeInvalidDSN, "secret %s: invalid name", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if err != nil {
+		return "", errorf(CodeInvalidDSN, "secret %s: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// ExpandDSN replaces ${name} placeholders in the DSN with secrets from the provider. Values
+// substituted into the query string, which starts at the first "?" of the DSN itself, are escaped.
+func ExpandDSN(ctx context.Context, dsn string, provider SecretProvider) (string, error) {
+	var b strings.Builder
+	query := false
+	for {
+		start := strings.Index(dsn, "${")
+		if start < 0 {
+			b.WriteString(dsn)
+			return b.String(), nil
+		}
+
+		end := strings.Index(dsn[start:], "}")
+		if end < 0 {
+			return "", errorf(CodeInvalidDSN, "invalid dsn: unterminated placeholder")
+		}
+		end += start
+
+		name := dsn[start+2 : end]
+		if name == "" {
+			return "", errorf(CodeInvalidDSN, "invalid dsn: empty placeholder")
+		}
+
+		value, err := provider.Secret(ctx, name)
+		if err != nil {
+			return "", err
+		}
+
+		query = query || strings.Contains(dsn[:start], "?")
+		b.WriteString(dsn[:start])
+		if query {
+			value = url.QueryEscape(value)
+		}
+		b.WriteString(value)
+		dsn = dsn[end+1:]
+	}
+}
+
+// NewWithSecrets creates a new database instance with a DSN string whose ${name} placeholders
+// are resolved by the secret provider, and migrations.
+func NewWithSecrets(ctx context.Context, dsn string, provider SecretProvider, migrations *Migrations) (*Database, error) {
+	dsn, err := ExpandDSN(ctx, dsn, provider)
+	if err != nil {
+		return nil, err
+	}
+	return New(dsn, migrations)
+}
@@ -0,0 +1,81 @@
+package litemigrate_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestExpandDSN(t *testing.T) {
+	provider := litemigrate.SecretFunc(func(ctx context.Context, name string) (string, error) {
+		return map[string]string{"DIR": "/var/lib/app", "PASS": "p&ss word"}[name], nil
+	})
+
+	dsn, err := litemigrate.ExpandDSN(context.Background(), "file:${DIR}/app.db?_auth_pass=${PASS}", provider)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "file:/var/lib/app/app.db?_auth_pass=p%26ss+word"
+	if dsn != expected {
+		t.Errorf("expected %s, got %s", expected, dsn)
+	}
+
+	_, err = litemigrate.ExpandDSN(context.Background(), "file:${DIR", provider)
+	if err == nil {
+		t.Error("expected error for unterminated placeholder, got nil")
+	}
+}
+
+func TestExpandDSNQueryInSecret(t *testing.T) {
+	provider := litemigrate.SecretFunc(func(ctx context.Context, name string) (string, error) {
+		return map[string]string{"DIR": "/data/a?b&c", "NAME": "my app", "PASS": "p?ss&word"}[name], nil
+	})
+
+	dsn, err := litemigrate.ExpandDSN(context.Background(), "file:${DIR}/${NAME}.db?_auth_pass=${PASS}", provider)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "file:/data/a?b&c/my app.db?_auth_pass=p%3Fss%26word"
+	if dsn != expected {
+		t.Errorf("expected %s, got %s", expected, dsn)
+	}
+}
+
+func TestFileSecrets(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "db_key"), []byte("secret\n"), 0o600)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	provider := litemigrate.FileSecrets{Dir: dir}
+
+	value, err := provider.Secret(context.Background(), "db_key")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if value != "secret" {
+		t.Errorf("expected secret, got %q", value)
+	}
+
+	_, err = provider.Secret(context.Background(), "../db_key")
+	if err == nil {
+		t.Error("expected error for invalid name, got nil")
+	}
+}
+
+func TestNewWithSecrets(t *testing.T) {
+	t.Setenv("LITEMIGRATE_TEST_DB", testDBPath)
+
+	db, err := litemigrate.NewWithSecrets(context.Background(), "${LITEMIGRATE_TEST_DB}", litemigrate.EnvSecrets{}, &litemigrate.Migrations{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+}
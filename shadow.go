@@ -0,0 +1,45 @@
+package litemigrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// SetShadowVerification makes MigrateUp replay the full migration chain into a temporary shadow
+// database file before touching the real database, and fail without changes if the replay fails.
+// It catches broken chains, such as a migration that depends on one that was edited or removed,
+// before production is modified. The shadow database is opened with the same driver and removed
+// afterwards.
+func (db *Database) SetShadowVerification(enabled bool) *Database {
+	db.shadowVerification = enabled
+	return db
+}
+
+// verifyShadow applies the migrations to an empty temporary database.
+func (db *Database) verifyShadow(ctx context.Context, opts []RunOption) error {
+	f, err := os.CreateTemp("", "litemigrate-shadow-*.db")
+	if err != nil {
+		return errorf(CodeShadowFailed, "failed to create shadow database: %w", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.Remove(path + "-journal")
+
+	conn := openWithDriver(db.conn, path)
+	defer conn.Close()
+
+	shadow := db.withConn(conn).SetWarningHandler(func(Warning) {}).SetProgressHandler(nil)
+	for _, attachment := range db.attached {
+		attachedPath := fmt.Sprintf("%s-%s", path, attachment.name)
+		defer os.Remove(attachedPath)
+		defer os.Remove(attachedPath + "-journal")
+		shadow.Attach(attachment.name, attachedPath)
+	}
+
+	if _, err := shadow.MigrateUp(ctx, opts...); err != nil {
+		return errorf(CodeShadowFailed, "shadow verification failed: %w", err)
+	}
+	return nil
+}
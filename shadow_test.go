@@ -0,0 +1,104 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestShadowVerification(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	// The table already exists in the real database, but the chain can't create it from scratch.
+	if _, err := conn.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY);`); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Add users email",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE users ADD COLUMN email TEXT;`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE users DROP COLUMN email;`)
+				return err
+			},
+		},
+	}).SetShadowVerification(true)
+
+	if _, err := db.MigrateUp(context.Background()); err == nil {
+		t.Fatal("expected error for a chain that fails on an empty database, got nil")
+	}
+
+	version, err := db.CurrentVersion(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 0 {
+		t.Errorf("expected version 0, got %d", version)
+	}
+
+	db.SetShadowVerification(false)
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Errorf("expected no error without shadow verification, got %v", err)
+	}
+}
+
+func TestShadowVerificationRehearsal(t *testing.T) {
+	dir := t.TempDir()
+	backups := filepath.Join(dir, "backups")
+	if err := os.Mkdir(backups, 0o755); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The migration runs on the shadow database first and then on the real one, and records the
+	// backups that exist while it runs.
+	existing := make([]int, 0)
+	migration := tableMigration(1, "users")
+	up := migration.Up
+	migration.Up = func(tx *sql.Tx) error {
+		entries, err := os.ReadDir(backups)
+		if err != nil {
+			return err
+		}
+		existing = append(existing, len(entries))
+		return up(tx)
+	}
+
+	calls := 0
+	db, err := litemigrate.New(filepath.Join(dir, "test.db"), &litemigrate.Migrations{migration})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+	db.SetShadowVerification(true).SetBackup(backups).Use(func(next litemigrate.Runner) litemigrate.Runner {
+		return func(ctx context.Context, tx *sql.Tx, step litemigrate.Step) error {
+			calls++
+			return next(ctx, tx, step)
+		}
+	})
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(existing) != 2 || existing[0] != 0 || existing[1] != 1 {
+		t.Errorf("expected a backup only for the real run, got %v", existing)
+	}
+	if calls != 1 {
+		t.Errorf("expected middleware to run only for the real run, got %d calls", calls)
+	}
+}
@@ -0,0 +1,102 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Check is a verification query run by VerifyOnSnapshot. The query must return a single value,
+// and the check passes when it is true, e.g. SELECT COUNT(*) = 0 FROM users WHERE email IS NULL.
+type Check struct {
+	Name  string
+	Query string
+}
+
+// CheckResult is the outcome of a check. Err is set when the query failed.
+type CheckResult struct {
+	Name   string
+	Passed bool
+	Err    error
+}
+
+// SnapshotReport describes a rehearsal of the pending migrations on a snapshot of the database.
+type SnapshotReport struct {
+	// Applied lists the versions of the migrations applied to the snapshot.
+	Applied []int64
+	Checks  []CheckResult
+}
+
+// Passed reports whether every check passed.
+func (r *SnapshotReport) Passed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyOnSnapshot rehearses the pending migrations against real data without touching it: it
+// copies the database into a temporary file with VACUUM INTO, which gives a consistent snapshot
+// while the database stays online, applies the pending migrations to the copy and runs the checks
+// against the result. It returns an error if the snapshot can't be taken or a migration fails.
+func (db *Database) VerifyOnSnapshot(ctx context.Context, checks []Check, opts ...RunOption) (*SnapshotReport, error) {
+	dir, err := os.MkdirTemp("", "litemigrate-snapshot-*")
+	if err != nil {
+		return nil, errorf(CodeSnapshotFailed, "failed to create snapshot directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshot.db")
+	if _, err := db.conn.ExecContext(ctx, "VACUUM INTO ?;", path); err != nil {
+		return nil, errorf(CodeSnapshotFailed, "failed to take snapshot: %w", err)
+	}
+
+	conn := openWithDriver(db.conn, path)
+	defer conn.Close()
+	snapshot := db.withConn(conn)
+	if err := db.snapshotAttachments(ctx, dir, snapshot); err != nil {
+		return nil, err
+	}
+
+	result, err := snapshot.MigrateUp(ctx, opts...)
+	if err != nil {
+		return nil, errorf(CodeSnapshotFailed, "failed to migrate snapshot: %w", err)
+	}
+
+	report := &SnapshotReport{Applied: result.Applied}
+	for _, check := range checks {
+		report.Checks = append(report.Checks, runCheck(ctx, conn, check))
+	}
+	return report, nil
+}
+
+func runCheck(ctx context.Context, conn *sql.DB, check Check) CheckResult {
+	result := CheckResult{Name: check.Name}
+	if err := conn.QueryRowContext(ctx, check.Query).Scan(&result.Passed); err != nil {
+		result.Err = fmt.Errorf("check %s failed: %w", check.Name, err)
+	}
+	return result
+}
+
+// withConn returns a copy of the database with the same settings using another connection. The
+// copy only rehearses the run, so it neither verifies on a shadow database, asks for confirmation,
+// takes the lock file or a backup, runs maintenance, middleware nor reports progress. It keeps its
+// bookkeeping tables in the other database and attaches no databases.
+func (db *Database) withConn(conn *sql.DB) *Database {
+	copy := *db
+	copy.conn = conn
+	copy.historyDatabase = ""
+	copy.attached = nil
+	copy.shadowVerification = false
+	copy.confirm = nil
+	copy.lockFile = ""
+	copy.backupDir = ""
+	copy.maintenance = 0
+	copy.middleware = nil
+	copy.progressHandler = nil
+	return &copy
+}
@@ -0,0 +1,77 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestVerifyOnSnapshot(t *testing.T) {
+	migrations := &litemigrate.Migrations{
+		{
+			Version:     1,
+			Description: "Create users table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+					INSERT INTO users (name) VALUES ('alice'), (NULL);
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE users;`)
+				return err
+			},
+		},
+	}
+
+	db, err := litemigrate.New(filepath.Join(t.TempDir(), "test.db"), migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	*migrations = append(*migrations, litemigrate.Migration{
+		Version:     2,
+		Description: "Fill user names",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`UPDATE users SET name = 'unknown' WHERE name IS NULL;`)
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			return nil
+		},
+	})
+
+	report, err := db.VerifyOnSnapshot(context.Background(), []litemigrate.Check{
+		{Name: "names filled", Query: `SELECT COUNT(*) = 0 FROM users WHERE name IS NULL;`},
+		{Name: "users kept", Query: `SELECT COUNT(*) = 3 FROM users;`},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(report.Applied) != 1 || report.Applied[0] != 2 {
+		t.Errorf("expected version 2 applied to the snapshot, got %v", report.Applied)
+	}
+
+	if report.Passed() || !report.Checks[0].Passed || report.Checks[1].Passed {
+		t.Errorf("expected only the first check to pass, got %+v", report.Checks)
+	}
+
+	version, err := db.CurrentVersion(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 1 {
+		t.Errorf("expected the database to stay at version 1, got %d", version)
+	}
+}
@@ -0,0 +1,138 @@
+package sqlfile
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// OpenArchive reads a zip or tar.gz archive from a file, see ReadArchive.
+func OpenArchive(name string) (fs.FS, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fsys, err := ReadArchive(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return fsys, nil
+}
+
+// ReadArchive reads a zip or tar.gz archive, detected from its content, and returns its files as a
+// file system for Load and LoadRepeatable, so all migrations can be shipped as a single, signed
+// artifact. The files must be at the root of the archive or all in the same top-level directory,
+// which becomes the root.
+func ReadArchive(r io.Reader) (fs.FS, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string][]byte
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")), bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		files, err = readZip(data)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		files, err = readTarGz(data)
+	default:
+		return nil, errors.New("unknown archive format: expected zip or tar.gz")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return archiveRoot(files)
+}
+
+func readZip(data []byte) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		files[file.Name] = content
+	}
+	return files, nil
+}
+
+func readTarGz(data []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		files[header.Name] = content
+	}
+}
+
+// archiveRoot returns the files of an archive by their names relative to its root, which is the
+// top-level directory when all files are in the same one.
+func archiveRoot(files map[string][]byte) (fs.FS, error) {
+	prefix := ""
+	for name := range files {
+		dir, _, ok := strings.Cut(strings.TrimPrefix(name, "./"), "/")
+		if !ok {
+			prefix = ""
+			break
+		}
+		if prefix == "" {
+			prefix = dir + "/"
+		} else if prefix != dir+"/" {
+			prefix = ""
+			break
+		}
+	}
+
+	root := memFS{}
+	for name, content := range files {
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "./"), prefix)
+		if strings.Contains(name, "/") {
+			return nil, fmt.Errorf("archive must have its files at the root or in a single top-level directory, got %s", name)
+		}
+		root[name] = content
+	}
+	return root, nil
+}
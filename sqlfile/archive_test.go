@@ -0,0 +1,111 @@
+package sqlfile_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+var archived = map[string]string{
+	"0001_create_users.up.sql":   "CREATE TABLE users (id INTEGER PRIMARY KEY);",
+	"0001_create_users.down.sql": "DROP TABLE users;",
+	"0002_add_email.sql":         "-- +goose Up\nALTER TABLE users ADD COLUMN email TEXT;\n-- +goose Down\n",
+}
+
+func zipArchive(t *testing.T, dir string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range archived {
+		w, err := zw.Create(dir + name)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, dir string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range archived {
+		if err := tw.WriteHeader(&tar.Header{Name: dir + name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		tw.Write([]byte(content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadArchive(t *testing.T) {
+	archives := map[string][]byte{
+		"zip":                 zipArchive(t, ""),
+		"zip in directory":    zipArchive(t, "migrations/"),
+		"tar.gz":              tarGzArchive(t, "./"),
+		"tar.gz in directory": tarGzArchive(t, "release-42/"),
+	}
+
+	for name, archive := range archives {
+		fsys, err := sqlfile.ReadArchive(bytes.NewReader(archive))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+
+		migrations, err := sqlfile.Load(fsys)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if len(migrations) != 2 {
+			t.Errorf("%s: expected 2 migrations, got %d", name, len(migrations))
+		}
+	}
+
+	if _, err := sqlfile.ReadArchive(strings.NewReader("CREATE TABLE users;")); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+
+	if _, err := sqlfile.ReadArchive(bytes.NewReader(zipArchive(t, "a/b/"))); err == nil {
+		t.Error("expected an error for nested directories")
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrations.tar.gz")
+	if err := os.WriteFile(path, tarGzArchive(t, ""), 0o644); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	fsys, err := sqlfile.OpenArchive(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Errorf("expected 2 migrations, got %d", len(migrations))
+	}
+}
@@ -0,0 +1,97 @@
+package sqlfile
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// ExecutedStatement is a statement executed by a SQL migration.
+type ExecutedStatement struct {
+	Version   int64
+	Direction litemigrate.Direction
+	SQL       string
+	Duration  time.Duration
+}
+
+// AuditSink receives the statements executed by SQL migrations, after each one succeeded. It is
+// called with the migration's transaction, so records written to the database are kept only if
+// the migration run commits. An error fails the migration.
+type AuditSink func(tx *sql.Tx, statement ExecutedStatement) error
+
+// WithAudit passes every statement executed by the loaded migrations to the sink, together with
+// its timing, so post-incident reviews can see exactly what ran against the database. The
+// migration files are split into single statements to time them individually.
+func WithAudit(sink AuditSink) Option {
+	return func(o *options) {
+		o.audit = sink
+	}
+}
+
+// AuditTable returns an AuditSink that records the executed statements in a table of the migrated
+// database, which is created if it doesn't exist. The table holds the version, the direction, the
+// statement, its duration in milliseconds and the time it was executed.
+func AuditTable(table string) AuditSink {
+	return func(tx *sql.Tx, s ExecutedStatement) error {
+		_, err := tx.Exec(fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				version INTEGER NOT NULL,
+				direction TEXT NOT NULL,
+				statement TEXT NOT NULL,
+				duration_ms INTEGER NOT NULL,
+				executed_at TEXT NOT NULL
+			);
+		`, quoteIdent(table)))
+		if err != nil {
+			return fmt.Errorf("failed to create audit table: %w", err)
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (version, direction, statement, duration_ms, executed_at) VALUES (?, ?, ?, ?, ?);", quoteIdent(table))
+		_, err = tx.Exec(query, s.Version, string(s.Direction), s.SQL, s.Duration.Milliseconds(), time.Now().UTC().Format(time.RFC3339Nano))
+		if err != nil {
+			return fmt.Errorf("failed to record executed statement: %w", err)
+		}
+		return nil
+	}
+}
+
+// audited returns a function executing the statements one at a time and passing each to the
+// sink. Chunks that can't be split are executed and recorded as a whole.
+func audited(version int64, direction litemigrate.Direction, chunks []string, sink AuditSink) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, chunk := range chunks {
+			statements := []string{chunk}
+			if split, err := split(chunk); err == nil {
+				statements = statements[:0]
+				for _, s := range split {
+					statements = append(statements, s.text)
+				}
+			}
+
+			for _, statement := range statements {
+				if strings.TrimSpace(statement) == "" {
+					continue
+				}
+
+				start := time.Now()
+				if _, err := tx.Exec(statement); err != nil {
+					return err
+				}
+
+				executed := ExecutedStatement{Version: version, Direction: direction, SQL: statement, Duration: time.Since(start)}
+				if err := sink(tx, executed); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
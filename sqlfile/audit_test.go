@@ -0,0 +1,104 @@
+package sqlfile_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+func TestAuditTable(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_users.up.sql": {Data: []byte(`
+			-- users of the application
+			CREATE TABLE users (id INTEGER PRIMARY KEY);
+			CREATE INDEX users_id ON users (id);
+		`)},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	}
+
+	migrations, err := sqlfile.Load(fsys, sqlfile.WithAudit(sqlfile.AuditTable("_migrations_audit")))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &migrations)
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := db.MigrateDown(context.Background(), 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rows, err := conn.Query("SELECT version, direction, statement FROM _migrations_audit ORDER BY id;")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer rows.Close()
+
+	expected := []struct {
+		direction string
+		statement string
+	}{
+		{"up", "CREATE TABLE users (id INTEGER PRIMARY KEY);"},
+		{"up", "CREATE INDEX users_id ON users (id);"},
+		{"down", "DROP TABLE users;"},
+	}
+
+	i := 0
+	for ; rows.Next(); i++ {
+		var (
+			version              int64
+			direction, statement string
+		)
+		if err := rows.Scan(&version, &direction, &statement); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if i < len(expected) && (version != 1 || direction != expected[i].direction || statement != expected[i].statement) {
+			t.Errorf("expected %v, got (%d, %s, %s)", expected[i], version, direction, statement)
+		}
+	}
+	if i != len(expected) {
+		t.Errorf("expected %d audit records, got %d", len(expected), i)
+	}
+}
+
+func TestAuditSinkRollback(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_broken.up.sql": {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY); INSERT INTO missing VALUES (1);")},
+	}
+
+	executed := make([]sqlfile.ExecutedStatement, 0)
+	migrations, err := sqlfile.Load(fsys, sqlfile.WithAudit(func(tx *sql.Tx, s sqlfile.ExecutedStatement) error {
+		executed = append(executed, s)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	if _, err := litemigrate.NewWithConn(conn, &migrations).MigrateUp(context.Background()); err == nil {
+		t.Fatal("expected the migration to fail")
+	}
+
+	if len(executed) != 1 || executed[0].SQL != "CREATE TABLE users (id INTEGER PRIMARY KEY);" || executed[0].Direction != litemigrate.Up {
+		t.Errorf("expected only the successful statement to be passed to the sink, got %+v", executed)
+	}
+}
@@ -0,0 +1,65 @@
+package sqlfile
+
+// destructiveStatements returns a summary of every statement that destroys data: DROP TABLE,
+// ALTER TABLE ... DROP COLUMN and DELETE without a WHERE clause. Statements that can't be split
+// are left to fail when the migration runs.
+func destructiveStatements(chunks []string) []string {
+	var destructive []string
+	for _, chunk := range chunks {
+		statements, err := split(chunk)
+		if err != nil {
+			continue
+		}
+
+		for _, s := range statements {
+			if summary, ok := classify(s.tokens); ok {
+				destructive = append(destructive, summary)
+			}
+		}
+	}
+	return destructive
+}
+
+// classify returns a summary of the statement if it destroys data.
+func classify(tokens []token) (string, bool) {
+	at := func(i int, keyword string) bool {
+		return i < len(tokens) && tokens[i].is(keyword)
+	}
+
+	switch {
+	case at(0, "DROP") && at(1, "TABLE"):
+		i := 2
+		if at(i, "IF") && at(i+1, "EXISTS") {
+			i += 2
+		}
+		if name, _, ok := qualifiedName(tokens, i); ok {
+			return "DROP TABLE " + name, true
+		}
+	case at(0, "ALTER") && at(1, "TABLE"):
+		table, i, ok := qualifiedName(tokens, 2)
+		if !ok || !at(i, "DROP") {
+			return "", false
+		}
+		i++
+
+		if at(i, "COLUMN") {
+			i++
+		}
+		if i < len(tokens) {
+			return "ALTER TABLE " + table + " DROP COLUMN " + tokens[i].text, true
+		}
+	case at(0, "DELETE") && at(1, "FROM"):
+		table, _, ok := qualifiedName(tokens, 2)
+		if !ok {
+			return "", false
+		}
+
+		for _, t := range tokens {
+			if t.is("WHERE") {
+				return "", false
+			}
+		}
+		return "DELETE FROM " + table + " without WHERE", true
+	}
+	return "", false
+}
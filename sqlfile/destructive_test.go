@@ -0,0 +1,72 @@
+package sqlfile_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+func TestDestructiveStatements(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_users.up.sql": {Data: []byte(`
+			CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, legacy TEXT);
+			CREATE TABLE sessions (id INTEGER PRIMARY KEY);
+			DELETE FROM users WHERE legacy IS NOT NULL;
+		`)},
+		"0002_cleanup.up.sql": {Data: []byte(`
+			DROP TABLE IF EXISTS main.sessions;
+			ALTER TABLE users DROP COLUMN "legacy";
+			DELETE FROM users; -- WHERE in a comment doesn't count
+			INSERT INTO users (email) VALUES ('DELETE FROM users');
+		`)},
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(migrations[0].DestructiveStatements) != 0 {
+		t.Errorf("expected no destructive statements, got %v", migrations[0].DestructiveStatements)
+	}
+
+	expected := []string{
+		"DROP TABLE main.sessions",
+		`ALTER TABLE users DROP COLUMN "legacy"`,
+		"DELETE FROM users without WHERE",
+	}
+	if !reflect.DeepEqual(migrations[1].DestructiveStatements, expected) {
+		t.Errorf("expected %v, got %v", expected, migrations[1].DestructiveStatements)
+	}
+
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &migrations)
+
+	_, err = db.MigrateUp(context.Background())
+	if litemigrate.Code(err) != litemigrate.CodeDestructiveNotAllowed {
+		t.Fatalf("expected destructive statements to be refused, got %v", err)
+	}
+
+	plan, err := db.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(plan.Migrations[1].DestructiveStatements, expected) || !plan.Migrations[1].Destructive() {
+		t.Errorf("expected the plan to flag the destructive statements, got %+v", plan.Migrations[1])
+	}
+
+	if _, err := db.MigrateUp(context.Background(), litemigrate.WithAllowDestructive(true)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package sqlfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateDown returns statements undoing the up statements, in reverse order. Only statements
+// creating tables, indexes, views or triggers and statements adding columns can be undone.
+func generateDown(up []string) ([]string, error) {
+	down := make([]string, 0)
+	for _, chunk := range up {
+		statements, err := split(chunk)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range statements {
+			inverse, err := invert(s)
+			if err != nil {
+				return nil, err
+			}
+			down = append([]string{inverse}, down...)
+		}
+	}
+	return down, nil
+}
+
+// invert returns the statement undoing a CREATE or ALTER TABLE ... ADD COLUMN statement.
+func invert(s statement) (string, error) {
+	tokens := s.tokens
+	unsupported := fmt.Errorf("cannot generate down statement for: %s", summary(s.text))
+
+	at := func(i int, keywords ...string) bool {
+		if i >= len(tokens) {
+			return false
+		}
+		for _, keyword := range keywords {
+			if tokens[i].is(keyword) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case at(0, "CREATE"):
+		i := 1
+		if at(i, "TEMP", "TEMPORARY") {
+			i++
+		}
+		if at(i, "UNIQUE") && at(i+1, "INDEX") || at(i, "VIRTUAL") && at(i+1, "TABLE") {
+			i++
+		}
+		if !at(i, "TABLE", "INDEX", "VIEW", "TRIGGER") {
+			return "", unsupported
+		}
+		kind := strings.ToUpper(tokens[i].text)
+		i++
+
+		if at(i, "IF") && at(i+1, "NOT") && at(i+2, "EXISTS") {
+			i += 3
+		}
+
+		name, _, ok := qualifiedName(tokens, i)
+		if !ok {
+			return "", unsupported
+		}
+		return fmt.Sprintf("DROP %s IF EXISTS %s;", kind, name), nil
+	case at(0, "ALTER") && at(1, "TABLE"):
+		table, i, ok := qualifiedName(tokens, 2)
+		if !ok || !at(i, "ADD") {
+			return "", unsupported
+		}
+		i++
+
+		if at(i, "COLUMN") {
+			i++
+		}
+
+		if i >= len(tokens) || tokens[i].kind != tokenWord && tokens[i].kind != tokenIdent {
+			return "", unsupported
+		}
+		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, tokens[i].text), nil
+	default:
+		return "", unsupported
+	}
+}
+
+// qualifiedName returns the possibly schema qualified name starting at tokens[i] and the index of
+// the token following it.
+func qualifiedName(tokens []token, i int) (string, int, bool) {
+	isName := func(i int) bool {
+		return i < len(tokens) && (tokens[i].kind == tokenWord || tokens[i].kind == tokenIdent)
+	}
+
+	if !isName(i) {
+		return "", i, false
+	}
+
+	if i+2 < len(tokens) && tokens[i+1].text == "." && isName(i+2) {
+		return tokens[i].text + "." + tokens[i+2].text, i + 3, true
+	}
+	return tokens[i].text, i + 1, true
+}
+
+// summary returns the first line of a statement, for error messages.
+func summary(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return strings.TrimSpace(text[:i]) + " ..."
+	}
+	return text
+}
@@ -0,0 +1,66 @@
+package sqlfile_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+func TestLoadWithGeneratedDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_users.up.sql": {Data: []byte(`
+			CREATE TABLE IF NOT EXISTS users (id INTEGER PRIMARY KEY);
+			CREATE UNIQUE INDEX users_id ON users (id);
+		`)},
+		"0002_add_email.up.sql": {Data: []byte(`ALTER TABLE users ADD COLUMN "email" TEXT;`)},
+	}
+
+	migrations, err := sqlfile.Load(fsys, sqlfile.WithGeneratedDown())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &migrations)
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = db.MigrateDown(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var count int
+	err = conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name IN ('users', 'users_id');`).Scan(&count)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if count != 0 {
+		t.Errorf("expected generated down to drop everything, got %d objects", count)
+	}
+}
+
+func TestLoadWithGeneratedDownUnsupported(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_seed_users.up.sql": {Data: []byte(`INSERT INTO users (id) VALUES (1);`)},
+	}
+
+	_, err := sqlfile.Load(fsys, sqlfile.WithGeneratedDown())
+	if err == nil {
+		t.Error("expected error for statement that can't be undone, got nil")
+	}
+}
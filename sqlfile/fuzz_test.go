@@ -0,0 +1,78 @@
+package sqlfile
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var fuzzSeeds = []string{
+	"CREATE TABLE users (id INTEGER PRIMARY KEY);",
+	"CREATE TABLE \"odd;name\" (a TEXT DEFAULT 'x;y'); -- comment;\nDROP TABLE x",
+	"CREATE TEMP TRIGGER t AFTER INSERT ON users BEGIN SELECT CASE WHEN 1 THEN 2 END; END;",
+	"CREATE TRIGGER t BEGIN",
+	"ALTER TABLE main.users ADD COLUMN email TEXT;",
+	"CREATE UNIQUE INDEX IF NOT EXISTS",
+	"/* unterminated",
+	"'unterminated",
+	"[bracket",
+	"-- +goose Up\nCREATE TABLE a (id INT);\n-- +goose Down\nDROP TABLE a;\n",
+	"-- +goose Up\n-- +goose StatementBegin\nCREATE TRIGGER t BEGIN SELECT 1; END;\n-- +goose StatementEnd\n",
+	"-- +goose StatementEnd\n",
+	"\xff\xfe;\x00",
+}
+
+// The fuzz tests check that malformed input results in errors rather than panics. Load doesn't
+// recover from panics, so a bug in the parsers fails them.
+
+func FuzzSplit(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, src string) {
+		statements, err := split(src)
+		if err != nil {
+			return
+		}
+
+		for _, s := range statements {
+			if !strings.Contains(src, s.text) {
+				t.Fatalf("statement %q is not part of the source", s.text)
+			}
+		}
+	})
+}
+
+func FuzzParseGoose(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		_, _, _ = parseGoose("fuzz.sql", content)
+	})
+}
+
+func FuzzGenerateDown(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, up string) {
+		_, _ = generateDown([]string{up})
+	})
+}
+
+func FuzzLoad(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add("0001_fuzz.sql", seed)
+		f.Add("0001_fuzz.up.sql", seed)
+	}
+
+	f.Fuzz(func(t *testing.T, name, content string) {
+		fsys := fstest.MapFS{name: {Data: []byte(content)}}
+		_, _ = Load(fsys, WithGeneratedDown())
+		_, _ = LoadRepeatable(fsys)
+	})
+}
@@ -0,0 +1,99 @@
+package sqlfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+const gooseAnnotation = "-- +goose"
+
+// parseGoose splits a goose migration file into its up and down statements. Statements end at a
+// line ending with a semicolon, unless they are wrapped in StatementBegin and StatementEnd.
+func parseGoose(name, content string) (up, down []string, err error) {
+	var (
+		direction *[]string
+		inBlock   bool
+		sawUp     bool
+		buf       strings.Builder
+	)
+
+	flush := func() {
+		if statement := strings.TrimSpace(buf.String()); statement != "" {
+			*direction = append(*direction, statement)
+		}
+		buf.Reset()
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, gooseAnnotation) {
+			annotation := strings.TrimSpace(strings.TrimPrefix(trimmed, gooseAnnotation))
+
+			switch annotation {
+			case "Up", "Down":
+				if inBlock {
+					return nil, nil, fmt.Errorf("%s:%d: missing -- +goose StatementEnd", name, i+1)
+				}
+				if direction != nil {
+					flush()
+				}
+				if annotation == "Up" {
+					direction, sawUp = &up, true
+				} else {
+					direction = &down
+				}
+			case "StatementBegin":
+				if direction == nil {
+					return nil, nil, fmt.Errorf("%s:%d: statement before -- +goose Up", name, i+1)
+				}
+				if inBlock {
+					return nil, nil, fmt.Errorf("%s:%d: nested -- +goose StatementBegin", name, i+1)
+				}
+				flush()
+				inBlock = true
+			case "StatementEnd":
+				if !inBlock {
+					return nil, nil, fmt.Errorf("%s:%d: -- +goose StatementEnd without StatementBegin", name, i+1)
+				}
+				flush()
+				inBlock = false
+			default:
+				return nil, nil, fmt.Errorf("%s:%d: unsupported annotation: %s", name, i+1, trimmed)
+			}
+			continue
+		}
+
+		if direction == nil {
+			if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return nil, nil, fmt.Errorf("%s:%d: statement before -- +goose Up", name, i+1)
+			}
+			continue
+		}
+
+		buf.WriteString(line)
+		buf.WriteString("\n")
+
+		if !inBlock && endsStatement(trimmed) {
+			flush()
+		}
+	}
+
+	if !sawUp {
+		return nil, nil, fmt.Errorf("%s: missing -- +goose Up annotation", name)
+	}
+
+	if inBlock {
+		return nil, nil, fmt.Errorf("%s: missing -- +goose StatementEnd", name)
+	}
+	flush()
+	return up, down, nil
+}
+
+// endsStatement reports whether a line ends with a semicolon, ignoring a trailing comment.
+func endsStatement(line string) bool {
+	if i := strings.Index(line, "--"); i >= 0 {
+		line = strings.TrimSpace(line[:i])
+	}
+	return strings.HasSuffix(line, ";")
+}
@@ -0,0 +1,73 @@
+package sqlfile_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+const gooseMigration = `-- +goose Up
+CREATE TABLE users (id INTEGER PRIMARY KEY, updated_at TEXT);
+CREATE TABLE audit (user_id INTEGER); -- written by the trigger
+
+-- +goose StatementBegin
+CREATE TRIGGER users_updated AFTER UPDATE ON users
+BEGIN
+	INSERT INTO audit (user_id) VALUES (NEW.id);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER users_updated;
+DROP TABLE audit;
+DROP TABLE users;
+`
+
+func TestLoadGoose(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20240612153000_create_users.sql": {Data: []byte(gooseMigration)},
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(migrations) != 1 || migrations[0].Version != 20240612153000 {
+		t.Fatalf("expected version 20240612153000, got %v", migrations)
+	}
+
+	db, err := litemigrate.New(":memory:", &migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = db.MigrateDown(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestLoadGooseInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing up":             "CREATE TABLE users (id INTEGER PRIMARY KEY);\n",
+		"missing statement end":  "-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n",
+		"unsupported annotation": "-- +goose NO TRANSACTION\n-- +goose Up\nVACUUM;\n",
+	}
+
+	for name, content := range tests {
+		fsys := fstest.MapFS{"0001_test.sql": {Data: []byte(content)}}
+		if _, err := sqlfile.Load(fsys); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
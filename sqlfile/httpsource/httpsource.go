@@ -0,0 +1,75 @@
+// Package httpsource fetches SQL migration files published over HTTP(S), e.g. for edge devices
+// applying migrations that are published centrally. It is kept apart from sqlfile so that loading
+// migrations from disk doesn't pull in net/http.
+package httpsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+// Fetch downloads the manifest at manifestURL and the files it lists, which are resolved relative
+// to it, and returns them as a file system for sqlfile.Load and sqlfile.LoadRepeatable. The
+// manifest is built with sqlfile.BuildManifest. Every file is verified against its checksum, and
+// fetching fails on any mismatch, so a partial or tampered file is never applied. A nil client
+// uses http.DefaultClient.
+//
+//	fsys, err := httpsource.Fetch(ctx, nil, "https://example.com/migrations/manifest.json")
+//	if err != nil {
+//		return err
+//	}
+//	migrations, err := sqlfile.Load(fsys)
+func Fetch(ctx context.Context, client *http.Client, manifestURL string) (fs.FS, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	base, err := url.Parse(manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest URL: %w", err)
+	}
+
+	data, err := fetch(ctx, client, base)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &sqlfile.Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", manifestURL, err)
+	}
+
+	return manifest.Fetch(func(name string) ([]byte, error) {
+		return fetch(ctx, client, base.ResolveReference(&url.URL{Path: name}))
+	})
+}
+
+func fetch(ctx context.Context, client *http.Client, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", u.Redacted(), resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u.Redacted(), err)
+	}
+	return data, nil
+}
@@ -0,0 +1,73 @@
+package httpsource_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate/sqlfile"
+	"github.com/joeychilson/litemigrate/sqlfile/httpsource"
+)
+
+func TestFetch(t *testing.T) {
+	files := fstest.MapFS{
+		"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);")},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"R__views.sql":               {Data: []byte("DROP VIEW IF EXISTS v; CREATE VIEW v AS SELECT 1;")},
+	}
+
+	manifest, err := sqlfile.BuildManifest(files)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var tampered atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/migrations/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(manifest)
+	})
+	mux.HandleFunc("/migrations/", func(w http.ResponseWriter, r *http.Request) {
+		file, ok := files[strings.TrimPrefix(r.URL.Path, "/migrations/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(file.Data)
+		if tampered.Load() {
+			w.Write([]byte(" DROP TABLE users;"))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fsys, err := httpsource.Fetch(context.Background(), server.Client(), server.URL+"/migrations/manifest.json")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := fstest.TestFS(fsys, "0001_create_users.up.sql", "0001_create_users.down.sql", "R__views.sql"); err != nil {
+		t.Fatalf("expected a valid file system, got %v", err)
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(migrations) != 1 || migrations[0].Description != "create users" {
+		t.Errorf("expected the create users migration, got %+v", migrations)
+	}
+
+	tampered.Store(true)
+	if _, err := httpsource.Fetch(context.Background(), server.Client(), server.URL+"/migrations/manifest.json"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+
+	if _, err := httpsource.Fetch(context.Background(), server.Client(), server.URL+"/missing/manifest.json"); err == nil {
+		t.Error("expected an error for a missing manifest")
+	}
+}
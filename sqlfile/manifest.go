@@ -0,0 +1,75 @@
+package sqlfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ManifestFile is a file listed in a Manifest.
+type ManifestFile struct {
+	Name string `json:"name"`
+	// SHA256 is the hex-encoded SHA-256 hash of the file's content.
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest lists the migration files published at a remote location, with their checksums.
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// BuildManifest returns the manifest of the SQL files in the root of fsys, to publish alongside
+// them for FetchObjects or httpsource.Fetch.
+func BuildManifest(fsys fs.FS) (*Manifest, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{Files: make([]ManifestFile, 0, len(entries))}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, ManifestFile{Name: entry.Name(), SHA256: hex.EncodeToString(sum[:])})
+	}
+	return manifest, nil
+}
+
+// Fetch reads the files of the manifest with read, verifies them against their checksums and
+// returns them as a file system for Load and LoadRepeatable. Fetching fails on any mismatch, so a
+// partial or tampered file is never applied. It lets other sources than FetchObjects serve
+// published migrations, see the httpsource package.
+func (m *Manifest) Fetch(read func(name string) ([]byte, error)) (fs.FS, error) {
+	files := memFS{}
+	for _, file := range m.Files {
+		if file.Name == "" || strings.ContainsAny(file.Name, `/\`) || file.Name == "." || file.Name == ".." {
+			return nil, fmt.Errorf("invalid file name %q in manifest", file.Name)
+		}
+		if _, ok := files[file.Name]; ok {
+			return nil, fmt.Errorf("duplicate file %s in manifest", file.Name)
+		}
+
+		data, err := read(file.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), file.SHA256) {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", file.Name, file.SHA256, hex.EncodeToString(sum[:]))
+		}
+		files[file.Name] = data
+	}
+	return files, nil
+}
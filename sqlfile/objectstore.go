@@ -0,0 +1,50 @@
+package sqlfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// ObjectStore reads objects from a bucket, such as one in Amazon S3 or Google Cloud Storage. The
+// package doesn't depend on a cloud SDK; a client is adapted with a few lines, see
+// ObjectStoreFunc.
+type ObjectStore interface {
+	// ReadObject returns the content of the object with the key.
+	ReadObject(ctx context.Context, key string) ([]byte, error)
+}
+
+// ObjectStoreFunc adapts a function to an ObjectStore.
+type ObjectStoreFunc func(ctx context.Context, key string) ([]byte, error)
+
+// ReadObject calls f.
+func (f ObjectStoreFunc) ReadObject(ctx context.Context, key string) ([]byte, error) {
+	return f(ctx, key)
+}
+
+// FetchObjects reads the manifest.json object under the prefix and the files it lists, and returns
+// them as a file system for Load and LoadRepeatable, so fleets of devices or serverless functions
+// can pull the latest migrations from a bucket. Every file is verified against its checksum in the
+// manifest, which is built with BuildManifest.
+func FetchObjects(ctx context.Context, store ObjectStore, prefix string) (fs.FS, error) {
+	data, err := store.ReadObject(ctx, path.Join(prefix, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return manifest.Fetch(func(name string) ([]byte, error) {
+		key := path.Join(prefix, name)
+		data, err := store.ReadObject(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		return data, nil
+	})
+}
@@ -0,0 +1,64 @@
+package sqlfile_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+func TestFetchObjects(t *testing.T) {
+	files := fstest.MapFS{
+		"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);")},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	}
+
+	manifest, err := sqlfile.BuildManifest(files)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	bucket := map[string][]byte{"releases/42/manifest.json": data}
+	for name, file := range files {
+		bucket["releases/42/"+name] = file.Data
+	}
+
+	store := sqlfile.ObjectStoreFunc(func(ctx context.Context, key string) ([]byte, error) {
+		data, ok := bucket[key]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return data, nil
+	})
+
+	fsys, err := sqlfile.FetchObjects(context.Background(), store, "releases/42")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(migrations) != 1 {
+		t.Errorf("expected 1 migration, got %d", len(migrations))
+	}
+
+	bucket["releases/42/0001_create_users.up.sql"] = []byte("DROP TABLE users;")
+	if _, err := sqlfile.FetchObjects(context.Background(), store, "releases/42"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+
+	if _, err := sqlfile.FetchObjects(context.Background(), store, "releases/43"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing manifest, got %v", err)
+	}
+}
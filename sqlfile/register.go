@@ -0,0 +1,38 @@
+package sqlfile
+
+import (
+	"io/fs"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+// Register loads the SQL migrations in fsys and adds them to the registry of litemigrate.Register,
+// so SQL files, e.g. embedded with go:embed, and Go migrations registered in init functions form a
+// single set ordered by version, returned by litemigrate.Registered:
+//
+//	//go:embed *.sql
+//	var files embed.FS
+//
+//	func init() {
+//		if err := sqlfile.Register(files); err != nil {
+//			panic(err)
+//		}
+//	}
+//
+// Unlike litemigrate.Register, it returns an error instead of panicking when a version is already
+// registered, and registers nothing in that case.
+func Register(fsys fs.FS, opts ...Option) error {
+	migrations, err := Load(fsys, opts...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := litemigrate.Merge(*litemigrate.Registered(), migrations); err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		litemigrate.Register(migration)
+	}
+	return nil
+}
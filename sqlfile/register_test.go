@@ -0,0 +1,44 @@
+package sqlfile_test
+
+import (
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+func TestRegister(t *testing.T) {
+	litemigrate.Register(litemigrate.Migration{
+		Version:     20240102000000,
+		Description: "Backfill user names",
+		Up:          func(tx *sql.Tx) error { return nil },
+		Down:        func(tx *sql.Tx) error { return nil },
+	})
+
+	fsys := fstest.MapFS{
+		"20240101000000_create_users.up.sql":  {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);")},
+		"20240103000000_index_names.up.sql":   {Data: []byte("CREATE INDEX users_name ON users (name);")},
+		"20240103000000_index_names.down.sql": {Data: []byte("DROP INDEX users_name;")},
+	}
+	if err := sqlfile.Register(fsys); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	registered := *litemigrate.Registered()
+	versions := make([]int64, 0, len(registered))
+	for _, migration := range registered {
+		versions = append(versions, migration.Version)
+	}
+	if len(versions) != 3 || versions[0] != 20240101000000 || versions[1] != 20240102000000 || versions[2] != 20240103000000 {
+		t.Errorf("expected the Go migration between the SQL migrations, got %v", versions)
+	}
+
+	if err := sqlfile.Register(fsys); litemigrate.Code(err) != litemigrate.CodeDuplicateVersion {
+		t.Errorf("expected a duplicate version error, got %v", err)
+	}
+	if len(*litemigrate.Registered()) != 3 {
+		t.Error("expected nothing to be registered after a duplicate")
+	}
+}
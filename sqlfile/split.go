@@ -0,0 +1,183 @@
+package sqlfile
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenPunct
+	tokenSemicolon
+)
+
+// token is a lexical token of a SQL statement. Comments and whitespace are not tokens.
+type token struct {
+	kind       tokenKind
+	text       string
+	start, end int
+}
+
+// is reports whether the token is the given keyword.
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// statement is a single SQL statement, including its terminating semicolon if it has one, and its
+// tokens, excluding the semicolon.
+type statement struct {
+	text   string
+	tokens []token
+}
+
+// lex splits SQL source into tokens.
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(src[i:], "--"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			i += end + 4
+		case r == '\'' || r == '"' || r == '`' || r == '[':
+			end, err := quoted(src, i)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokenIdent
+			if r == '\'' {
+				kind = tokenString
+			}
+			tokens = append(tokens, token{kind: kind, text: src[i:end], start: i, end: end})
+			i = end
+		case r == ';':
+			tokens = append(tokens, token{kind: tokenSemicolon, text: ";", start: i, end: i + 1})
+			i++
+		case isWordRune(r):
+			end := i
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if !isWordRune(r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: src[i:end], start: i, end: end})
+			i = end
+		default:
+			tokens = append(tokens, token{kind: tokenPunct, text: src[i : i+size], start: i, end: i + size})
+			i += size
+		}
+	}
+	return tokens, nil
+}
+
+// quoted returns the end of the quoted string or identifier starting at i. Quotes are escaped by
+// doubling them, except for brackets which can't be escaped.
+func quoted(src string, i int) (int, error) {
+	open := src[i]
+	close := open
+	if open == '[' {
+		close = ']'
+	}
+
+	for j := i + 1; j < len(src); j++ {
+		if src[j] != close {
+			continue
+		}
+		if open != '[' && j+1 < len(src) && src[j+1] == close {
+			j++
+			continue
+		}
+		return j + 1, nil
+	}
+	return 0, fmt.Errorf("unterminated %c at offset %d", open, i)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// split splits SQL source into statements. Semicolons inside the body of a CREATE TRIGGER
+// statement don't end the statement.
+func split(src string) ([]statement, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]statement, 0)
+	current := make([]token, 0)
+	inBody, cases := false, 0
+
+	emit := func(end int) {
+		if len(current) > 0 {
+			statements = append(statements, statement{
+				text:   src[current[0].start:end],
+				tokens: current,
+			})
+		}
+		current, inBody, cases = make([]token, 0), false, 0
+	}
+
+	for _, t := range tokens {
+		if t.kind == tokenSemicolon && !inBody {
+			emit(t.end)
+			continue
+		}
+		current = append(current, t)
+
+		if !isTrigger(current) {
+			continue
+		}
+
+		switch {
+		case t.is("BEGIN") && !inBody:
+			inBody = true
+		case t.is("CASE") && inBody:
+			cases++
+		case t.is("END") && inBody && cases > 0:
+			cases--
+		case t.is("END") && inBody:
+			inBody = false
+		}
+	}
+
+	if inBody {
+		return nil, fmt.Errorf("unterminated trigger body: missing END")
+	}
+
+	if len(current) > 0 {
+		emit(current[len(current)-1].end)
+	}
+	return statements, nil
+}
+
+// isTrigger reports whether the tokens start a CREATE [TEMP|TEMPORARY] TRIGGER statement.
+func isTrigger(tokens []token) bool {
+	if len(tokens) < 2 || !tokens[0].is("CREATE") {
+		return false
+	}
+	if tokens[1].is("TEMP") || tokens[1].is("TEMPORARY") {
+		return len(tokens) > 2 && tokens[2].is("TRIGGER")
+	}
+	return tokens[1].is("TRIGGER")
+}
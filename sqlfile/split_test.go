@@ -0,0 +1,40 @@
+package sqlfile
+
+import "testing"
+
+func TestSplit(t *testing.T) {
+	src := `
+		-- create the users table; with a comment
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT DEFAULT 'a;b');
+		/* block; comment */
+		CREATE TRIGGER users_insert AFTER INSERT ON users
+		BEGIN
+			UPDATE users SET name = CASE WHEN NEW.name = '' THEN 'none' ELSE NEW.name END WHERE id = NEW.id;
+		END;
+		INSERT INTO "odd;name" VALUES (1)`
+
+	statements, err := split(src)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(statements) != 3 {
+		t.Fatalf("expected 3 statements, got %d: %q", len(statements), statements)
+	}
+
+	if statements[2].text != `INSERT INTO "odd;name" VALUES (1)` {
+		t.Errorf("unexpected last statement: %q", statements[2].text)
+	}
+}
+
+func TestSplitInvalid(t *testing.T) {
+	for _, src := range []string{
+		"SELECT 'unterminated;",
+		"SELECT 1; /* unterminated",
+		"CREATE TRIGGER t AFTER INSERT ON users BEGIN SELECT 1;",
+	} {
+		if _, err := split(src); err == nil {
+			t.Errorf("expected error for %q, got nil", src)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package sqlfile loads migrations from SQL files.
+//
+// Each migration is either a pair of files named after its version and description:
+//
+//	0001_create_users.up.sql
+//	0001_create_users.down.sql
+//
+// or a single file in the goose format, with the up and down statements separated by
+// annotations:
+//
+//	-- 0001_create_users.sql
+//	-- +goose Up
+//	CREATE TABLE users (id INTEGER PRIMARY KEY);
+//
+//	-- +goose Down
+//	DROP TABLE users;
+//
+// Statements spanning several lines that contain semicolons, such as triggers, are wrapped in
+// "-- +goose StatementBegin" and "-- +goose StatementEnd". The description is taken from the
+// file name with underscores replaced by spaces.
+//
+// File pairs follow the golang-migrate convention, so its migration directories can be loaded
+// unchanged: as in golang-migrate, the down file is optional and rolling back a migration without
+// one only removes it from the migration table.
+//
+// Up statements that destroy data, DROP TABLE, ALTER TABLE ... DROP COLUMN and DELETE without a
+// WHERE clause, are listed in the migration's DestructiveStatements, so they only run when
+// allowed with litemigrate.WithAllowDestructive.
+//
+// Statements that can't run inside the migration's transaction, such as VACUUM, ATTACH or setting
+// the journal_mode pragma, are listed in the migration's NonTransactionalStatements and make it
+// fail validation.
+//
+// Files named R__<name>.sql are repeatable migrations, loaded with LoadRepeatable and run again
+// whenever their content changes.
+//
+// With WithTemplate, the files are Go templates executed with values supplied at load time, and
+// WithEnvironment selects variables and statements for an environment such as dev or prod.
+//
+// FetchObjects fetches migration files published in a bucket along with a manifest of their
+// checksums, the httpsource package fetches them from a URL, and OpenArchive and ReadArchive read
+// them from a zip or tar.gz archive.
+//
+// Migration files may be generated or come from third parties, so malformed files result in errors
+// and never in panics. The parsers are fuzz tested to keep it that way.
+package sqlfile
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+const repeatablePrefix = "R__"
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+?)(\.up|\.down)?\.sql$`)
+
+// Option configures how migrations are loaded.
+type Option func(*options)
+
+type options struct {
+	generateDown bool
+	audit        AuditSink
+	template     bool
+	templateData any
+
+	environment     string
+	environmentVars map[string]map[string]any
+}
+
+// WithGeneratedDown generates the down statements of migrations that have none, when their up
+// statements only create tables, indexes, views or triggers, or add columns. Loading fails if a
+// migration without down statements contains any other statement.
+func WithGeneratedDown() Option {
+	return func(o *options) {
+		o.generateDown = true
+	}
+}
+
+// Load reads the SQL migration files in the root of fsys and returns them as migrations. Their
+// checksum is the SHA-256 hash of their up statements.
+func Load(fsys fs.FS, opts ...Option) (litemigrate.Migrations, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	type pair struct {
+		name     string
+		up, down []string
+		hasUp    bool
+		hasDown  bool
+	}
+	pairs := map[int64]*pair{}
+	versions := make([]int64, 0)
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" || strings.HasPrefix(entry.Name(), repeatablePrefix) {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s: expected <version>_<name>.sql, <version>_<name>.up.sql or <version>_<name>.down.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: %w", entry.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if data, err = o.render(entry.Name(), data); err != nil {
+			return nil, err
+		}
+
+		p, ok := pairs[version]
+		if !ok {
+			p = &pair{name: match[2]}
+			pairs[version] = p
+			versions = append(versions, version)
+		}
+
+		if p.name != match[2] || p.hasUp && match[3] != ".down" || p.hasDown && match[3] != ".up" {
+			return nil, fmt.Errorf("duplicate migration: (version=%v) is defined by more than one file", version)
+		}
+
+		switch match[3] {
+		case ".up":
+			p.up, p.hasUp = []string{string(data)}, true
+		case ".down":
+			p.down, p.hasDown = []string{string(data)}, true
+		default:
+			if p.up, p.down, err = parseGoose(entry.Name(), string(data)); err != nil {
+				return nil, err
+			}
+			p.hasUp, p.hasDown = true, true
+		}
+	}
+
+	migrations := make(litemigrate.Migrations, 0, len(versions))
+	for _, version := range versions {
+		p := pairs[version]
+		if !p.hasUp {
+			return nil, fmt.Errorf("invalid migration: (version=%v, name=%s) has no up file", version, p.name)
+		}
+
+		if o.generateDown && len(p.down) == 0 {
+			down, err := generateDown(p.up)
+			if err != nil {
+				return nil, fmt.Errorf("invalid migration: (version=%v, name=%s) %w", version, p.name, err)
+			}
+			p.down = down
+		}
+
+		sum := sha256.Sum256([]byte(strings.Join(p.up, "\n")))
+		up, down := exec(p.up), exec(p.down)
+		if o.audit != nil {
+			up = audited(version, litemigrate.Up, p.up, o.audit)
+			down = audited(version, litemigrate.Down, p.down, o.audit)
+		}
+
+		migrations = append(migrations, litemigrate.Migration{
+			Version:                    version,
+			Description:                strings.ReplaceAll(p.name, "_", " "),
+			Up:                         up,
+			Down:                       down,
+			DestructiveStatements:      destructiveStatements(p.up),
+			NonTransactionalStatements: nonTransactionalStatements(p.up, p.down),
+			Checksum:                   hex.EncodeToString(sum[:]),
+		})
+	}
+	return migrations, nil
+}
+
+// LoadRepeatable reads the repeatable migration files, named R__<name>.sql, in the root of fsys.
+// Their checksum is the SHA-256 hash of their content. Of the options, only WithTemplate and
+// WithEnvironment apply.
+func LoadRepeatable(fsys fs.FS, opts ...Option) ([]litemigrate.Repeatable, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	repeatables := make([]litemigrate.Repeatable, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".sql" || !strings.HasPrefix(name, repeatablePrefix) {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if data, err = o.render(name, data); err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		repeatables = append(repeatables, litemigrate.Repeatable{
+			Name:     strings.TrimSuffix(strings.TrimPrefix(name, repeatablePrefix), ".sql"),
+			Checksum: hex.EncodeToString(sum[:]),
+			Up:       exec([]string{string(data)}),
+		})
+	}
+	return repeatables, nil
+}
+
+func exec(statements []string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, statement := range statements {
+			if strings.TrimSpace(statement) == "" {
+				continue
+			}
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,147 @@
+package sqlfile_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);")},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"0002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT;")},
+		"0002_add_email.down.sql":    {Data: []byte("-- nothing to undo\n")},
+		"README.md":                  {Data: []byte("not a migration")},
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations, got %d", len(migrations))
+	}
+
+	if migrations[1].Version != 2 || migrations[1].Description != "add email" {
+		t.Errorf("expected version 2 (add email), got %d (%s)", migrations[1].Version, migrations[1].Description)
+	}
+
+	if migrations[0].Checksum == "" || migrations[0].Checksum == migrations[1].Checksum {
+		t.Errorf("expected a checksum per migration, got %q and %q", migrations[0].Checksum, migrations[1].Checksum)
+	}
+
+	db, err := litemigrate.New(":memory:", &migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = db.MigrateDown(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing up": {
+			"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		},
+		"bad name": {
+			"create_users.sql": {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);")},
+		},
+		"duplicate version": {
+			"0001_a.up.sql":   {},
+			"0001_a.down.sql": {},
+			"0001_b.up.sql":   {},
+			"0001_b.down.sql": {},
+		},
+	}
+
+	for name, fsys := range tests {
+		if _, err := sqlfile.Load(fsys); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestLoadGolangMigrate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"1_create_users.v2.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);")},
+		"1_create_users.v2.down.sql": {Data: []byte("DROP TABLE users;")},
+		"2_seed_users.up.sql":        {Data: []byte("INSERT INTO users (id) VALUES (1);")},
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(migrations) != 2 || migrations[0].Description != "create users.v2" {
+		t.Fatalf("expected 2 migrations, got %v", migrations)
+	}
+
+	db, err := litemigrate.New(":memory:", &migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = db.MigrateDown(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestLoadRepeatable(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_users.up.sql": {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);")},
+		"R__user_ids.sql":          {Data: []byte("DROP VIEW IF EXISTS user_ids;\nCREATE VIEW user_ids AS SELECT id FROM users;")},
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	repeatables, err := sqlfile.LoadRepeatable(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(migrations) != 1 || len(repeatables) != 1 {
+		t.Fatalf("expected 1 migration and 1 repeatable migration, got %d and %d", len(migrations), len(repeatables))
+	}
+
+	if repeatables[0].Name != "user_ids" || repeatables[0].Checksum == "" {
+		t.Errorf("expected repeatable migration user_ids with a checksum, got %+v", repeatables[0])
+	}
+
+	db, err := litemigrate.New(":memory:", &migrations)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.SetRepeatables(repeatables...).MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package sqlfile
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// WithTemplate executes every SQL file as a Go template with the data before it is parsed, so the
+// same migrations can target differently prefixed schemas:
+//
+//	CREATE TABLE {{ .TablePrefix }}users (id INTEGER PRIMARY KEY);
+//
+// Referencing a missing map key or field fails loading. The values are inserted as they are, so
+// they must come from trusted configuration, not from user input.
+func WithTemplate(data any) Option {
+	return func(o *options) {
+		o.template = true
+		o.templateData = data
+	}
+}
+
+// WithEnvironment executes every SQL file as a template for an environment, such as "dev",
+// "staging" or "prod". The variables of the environment in vars are merged over the data of
+// WithTemplate, which must then be a map, and the env and envIs functions select
+// environment-only statements, so test seed rows can live with the migration that needs them:
+//
+//	{{ if envIs "dev" "staging" }}
+//	INSERT INTO users (name) VALUES ('test');
+//	{{ end }}
+//
+// vars may be nil when the files only use the functions. Loading fails if vars doesn't define the
+// environment.
+func WithEnvironment(name string, vars map[string]map[string]any) Option {
+	return func(o *options) {
+		o.template = true
+		o.environment = name
+		o.environmentVars = vars
+	}
+}
+
+// render executes the file as a template if templates are enabled.
+func (o *options) render(name string, data []byte) ([]byte, error) {
+	if !o.template {
+		return data, nil
+	}
+
+	templateData, err := o.data()
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := template.FuncMap{
+		"env": func() string { return o.environment },
+		"envIs": func(names ...string) bool {
+			for _, name := range names {
+				if name == o.environment {
+					return true
+				}
+			}
+			return false
+		},
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// data returns the template data, with the variables of the environment merged in.
+func (o *options) data() (any, error) {
+	if o.environmentVars == nil {
+		return o.templateData, nil
+	}
+
+	vars, ok := o.environmentVars[o.environment]
+	if !ok {
+		return nil, fmt.Errorf("no template variables defined for environment %q", o.environment)
+	}
+
+	merged := map[string]any{}
+	switch data := o.templateData.(type) {
+	case nil:
+	case map[string]any:
+		for key, value := range data {
+			merged[key] = value
+		}
+	case map[string]string:
+		for key, value := range data {
+			merged[key] = value
+		}
+	default:
+		return nil, fmt.Errorf("template data must be a map to merge the variables of environment %q, got %T", o.environment, o.templateData)
+	}
+
+	for key, value := range vars {
+		merged[key] = value
+	}
+	return merged, nil
+}
@@ -0,0 +1,106 @@
+package sqlfile_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+func TestWithTemplate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE {{ .TablePrefix }}users (id INTEGER PRIMARY KEY);")},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE {{ .TablePrefix }}users;")},
+		"R__user_ids.sql":            {Data: []byte("DROP VIEW IF EXISTS {{ .TablePrefix }}user_ids; CREATE VIEW {{ .TablePrefix }}user_ids AS SELECT id FROM {{ .TablePrefix }}users;")},
+	}
+	data := map[string]string{"TablePrefix": "app_"}
+
+	migrations, err := sqlfile.Load(fsys, sqlfile.WithTemplate(data))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	repeatables, err := sqlfile.LoadRepeatable(fsys, sqlfile.WithTemplate(data))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &migrations).SetRepeatables(repeatables...)
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := conn.Exec("SELECT id FROM app_user_ids;"); err != nil {
+		t.Errorf("expected prefixed objects, got %v", err)
+	}
+
+	if _, err := sqlfile.Load(fsys, sqlfile.WithTemplate(map[string]string{})); err == nil {
+		t.Error("expected an error for a missing template variable")
+	}
+}
+
+func TestWithEnvironment(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_users.sql": {Data: []byte(`-- +goose Up
+CREATE TABLE {{ .TablePrefix }}users (id INTEGER PRIMARY KEY, name TEXT);
+{{ if envIs "dev" "staging" }}
+INSERT INTO {{ .TablePrefix }}users (name) VALUES ('{{ .SeedUser }}');
+{{ end }}
+-- +goose Down
+DROP TABLE {{ .TablePrefix }}users;
+`)},
+	}
+	vars := map[string]map[string]any{
+		"dev":  {"SeedUser": "alice"},
+		"prod": {"TablePrefix": "prod_"},
+	}
+
+	tests := []struct {
+		env   string
+		table string
+		rows  int
+	}{
+		{env: "dev", table: "app_users", rows: 1},
+		{env: "prod", table: "prod_users", rows: 0},
+	}
+
+	for _, test := range tests {
+		migrations, err := sqlfile.Load(fsys, sqlfile.WithTemplate(map[string]string{"TablePrefix": "app_"}), sqlfile.WithEnvironment(test.env, vars))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", test.env, err)
+		}
+
+		conn, err := sql.Open("sqlite3", ":memory:")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer conn.Close()
+		conn.SetMaxOpenConns(1)
+
+		if _, err := litemigrate.NewWithConn(conn, &migrations).MigrateUp(context.Background()); err != nil {
+			t.Fatalf("%s: expected no error, got %v", test.env, err)
+		}
+
+		rows := 0
+		if err := conn.QueryRow("SELECT COUNT(*) FROM " + test.table + ";").Scan(&rows); err != nil {
+			t.Fatalf("%s: expected no error, got %v", test.env, err)
+		}
+		if rows != test.rows {
+			t.Errorf("%s: expected %d seed rows, got %d", test.env, test.rows, rows)
+		}
+	}
+
+	if _, err := sqlfile.Load(fsys, sqlfile.WithEnvironment("staging", vars)); err == nil {
+		t.Error("expected an error for an environment without variables")
+	}
+}
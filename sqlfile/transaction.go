@@ -0,0 +1,58 @@
+package sqlfile
+
+import "strings"
+
+// nonTransactionalStatements returns a summary of every statement that can't run inside the
+// transaction of a migration: VACUUM, ATTACH and DETACH fail, transaction control statements
+// conflict with the migration's transaction, and setting the journal_mode or foreign_keys pragma
+// fails or is silently ignored.
+func nonTransactionalStatements(chunks ...[]string) []string {
+	var statements []string
+	for _, chunk := range chunks {
+		for _, src := range chunk {
+			split, err := split(src)
+			if err != nil {
+				continue
+			}
+
+			for _, s := range split {
+				if summary, ok := nonTransactional(s.tokens); ok {
+					statements = append(statements, summary)
+				}
+			}
+		}
+	}
+	return statements
+}
+
+// nonTransactional returns a summary of the statement if it can't run inside a transaction.
+func nonTransactional(tokens []token) (string, bool) {
+	if len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return "", false
+	}
+
+	keyword := strings.ToUpper(tokens[0].text)
+	switch keyword {
+	case "VACUUM", "ATTACH", "DETACH", "BEGIN", "COMMIT", "END":
+		return keyword, true
+	case "ROLLBACK":
+		// ROLLBACK TO rolls back to a savepoint, which is fine inside a transaction.
+		for _, t := range tokens[1:] {
+			if t.is("TO") {
+				return "", false
+			}
+		}
+		return keyword, true
+	case "PRAGMA":
+		name, i, ok := qualifiedName(tokens, 1)
+		if !ok || i >= len(tokens) || tokens[i].text != "=" && tokens[i].text != "(" {
+			return "", false
+		}
+
+		pragma := name[strings.LastIndex(name, ".")+1:]
+		if strings.EqualFold(pragma, "journal_mode") || strings.EqualFold(pragma, "foreign_keys") {
+			return "PRAGMA " + name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,53 @@
+package sqlfile_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joeychilson/litemigrate"
+	"github.com/joeychilson/litemigrate/sqlfile"
+)
+
+func TestNonTransactionalStatements(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_users.up.sql": {Data: []byte(`
+			CREATE TABLE users (id INTEGER PRIMARY KEY);
+			PRAGMA journal_mode;
+			SAVEPOINT seed;
+			ROLLBACK TO seed;
+		`)},
+		"0002_compact.up.sql": {Data: []byte(`
+			PRAGMA main.journal_mode = WAL;
+			VACUUM;
+		`)},
+		"0002_compact.down.sql": {Data: []byte("ATTACH DATABASE 'other.db' AS other;")},
+	}
+
+	migrations, err := sqlfile.Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(migrations[0].NonTransactionalStatements) != 0 {
+		t.Errorf("expected no non-transactional statements, got %v", migrations[0].NonTransactionalStatements)
+	}
+
+	expected := []string{"PRAGMA main.journal_mode", "VACUUM", "ATTACH"}
+	if !reflect.DeepEqual(migrations[1].NonTransactionalStatements, expected) {
+		t.Errorf("expected %v, got %v", expected, migrations[1].NonTransactionalStatements)
+	}
+
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	_, err = litemigrate.NewWithConn(conn, &migrations).Validate(context.Background())
+	if litemigrate.Code(err) != litemigrate.CodeInvalidMigration {
+		t.Fatalf("expected an invalid migration, got %v", err)
+	}
+}
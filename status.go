@@ -0,0 +1,75 @@
+package litemigrate
+
+import "context"
+
+// Status summarizes the migration state of a database.
+type Status struct {
+	// Version is the highest applied version.
+	Version int64 `json:"version"`
+	// Pending are the migrations defined in code that aren't applied, in version order. Migrations
+	// recorded as excluded aren't pending, see Database.ExcludedMigrations.
+	Pending []PendingMigration `json:"pending"`
+	// Dirty reports that the migration table is inconsistent with the migrations in code, and
+	// Problems describes how. See Validate.
+	Dirty    bool     `json:"dirty"`
+	Problems []string `json:"problems,omitempty"`
+	// Failed is the migration that failed in the last run committing every migration, which the
+	// next run resumes from. See Database.SetCommitEach.
+	Failed *FailedMigration `json:"failed,omitempty"`
+}
+
+// PendingMigration is a migration that isn't applied yet.
+type PendingMigration struct {
+	Version     int64  `json:"version"`
+	Description string `json:"description"`
+	// PostDeploy reports that the migration is applied by MigratePostDeploy.
+	PostDeploy bool `json:"post_deploy,omitempty"`
+}
+
+// Status returns the current version, the pending migrations and whether the migration table is
+// consistent with the migrations in code. It does not modify the database.
+func (db *Database) Status(ctx context.Context) (*Status, error) {
+	report, err := db.Validate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	records, err := db.getMigrationRecords(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := db.excludedVersionsRecorded(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Pending: make([]PendingMigration, 0), Dirty: !report.Valid()}
+	for version := range records {
+		if version > status.Version {
+			status.Version = version
+		}
+	}
+
+	for _, migration := range db.migrations.sorted() {
+		if _, applied := records[migration.Version]; !applied && !excluded[migration.Version] {
+			status.Pending = append(status.Pending, PendingMigration{Version: migration.Version, Description: migration.Description, PostDeploy: migration.PostDeploy})
+		}
+	}
+
+	if status.Dirty {
+		status.Problems = report.problems()
+	}
+
+	status.Failed, err = db.readFailed(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
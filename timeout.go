@@ -0,0 +1,56 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SetMigrationTimeout sets the time every migration may take before it is aborted, unless the
+// migration sets its own Timeout. A zero timeout, the default, lets migrations run indefinitely.
+//
+// A migration that times out fails with an error wrapping context.DeadlineExceeded and the run is
+// rolled back. The context passed to middleware carries the deadline. Up and Down functions don't
+// receive a context, so a statement that is already running finishes before the transaction is
+// rolled back, and the statements the function runs afterwards fail.
+func (db *Database) SetMigrationTimeout(timeout time.Duration) *Database {
+	db.migrationTimeout = timeout
+	return db
+}
+
+// runWithTimeout runs a migration step, aborting it once its timeout or the run's deadline
+// expires.
+func (db *Database) runWithTimeout(ctx context.Context, tx *sql.Tx, run Runner, step Step) error {
+	timeout := step.Migration.Timeout
+	if timeout <= 0 {
+		timeout = db.migrationTimeout
+	}
+	if _, ok := ctx.Deadline(); !ok && timeout <= 0 {
+		return recoverStep(ctx, tx, run, step)
+	}
+
+	parent := ctx
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- recoverStep(ctx, tx, run, step)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if parent.Err() != nil {
+			return parent.Err()
+		}
+		return fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+	}
+}
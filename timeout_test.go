@@ -0,0 +1,114 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestMigrationTimeout(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	finished := make(chan error, 1)
+	runaway := litemigrate.Migration{
+		Version:     2,
+		Description: "Runaway migration",
+		Up: func(tx *sql.Tx) error {
+			time.Sleep(100 * time.Millisecond)
+			_, err := tx.Exec("CREATE TABLE runaway (id INTEGER PRIMARY KEY);")
+			finished <- err
+			return err
+		},
+		Down:    func(tx *sql.Tx) error { return nil },
+		Timeout: 10 * time.Millisecond,
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users"), runaway}).
+		SetMigrationTimeout(time.Minute)
+
+	_, err = db.MigrateUp(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	if err := <-finished; !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("expected the aborted migration's statements to fail, got %v", err)
+	}
+
+	count := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name IN ('users', 'runaway');").Scan(&count); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected the run to be rolled back, got %d tables", count)
+	}
+}
+
+func TestMigrationTimeoutDefault(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users")}).
+		SetMigrationTimeout(time.Minute)
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestMaxDuration(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	slow := func(version int64) litemigrate.Migration {
+		return litemigrate.Migration{
+			Version:     version,
+			Description: fmt.Sprintf("Slow migration %d", version),
+			Up: func(tx *sql.Tx) error {
+				time.Sleep(30 * time.Millisecond)
+				return nil
+			},
+			Down: func(tx *sql.Tx) error { return nil },
+		}
+	}
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{tableMigration(1, "users"), slow(2), slow(3), slow(4)})
+
+	_, err = db.MigrateUp(context.Background(), litemigrate.WithMaxDuration(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if litemigrate.Code(err) != litemigrate.CodeMaxDuration {
+		t.Errorf("expected code %s, got %v", litemigrate.CodeMaxDuration, litemigrate.Code(err))
+	}
+
+	count := 0
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'users';").Scan(&count); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if count != 0 {
+		t.Error("expected the run to be rolled back")
+	}
+
+	if _, err := db.MigrateUp(context.Background(), litemigrate.WithMaxDuration(time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
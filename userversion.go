@@ -0,0 +1,130 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// SetUserVersion makes the database track the applied migrations in SQLite's PRAGMA user_version
+// instead of the migration table, for deployments that want no extra tables or share the database
+// with other tools that use user_version. The user_version holds the latest applied version, so
+// every defined migration up to it counts as applied: migrations can't be applied out of order,
+// and a run that skips a pending migration, e.g. because of its Condition, tags or MinAppVersion,
+// doesn't apply any later one. Versions must fit into 32 bits, and no metadata such as AppliedBy
+// or durations is recorded. The hash chain requires the migration table. Features that keep their
+// own tables, such as repeatable migrations, still create them.
+func (db *Database) SetUserVersion(enabled bool) *Database {
+	db.userVersion = enabled
+	return db
+}
+
+// checkUserVersion returns an error if the migrations can't be tracked in the user_version.
+func (db *Database) checkUserVersion() error {
+	if db.hashChain {
+		return errorf(CodeMigrationTable, "the hash chain can't be used when tracking the user_version")
+	}
+	for _, migration := range *db.migrations {
+		if migration.Version > math.MaxInt32 {
+			return errorf(CodeMigrationTable, "migration (version=%v, description=%s) exceeds the largest user_version", migration.Version, migration.Description)
+		}
+	}
+	return nil
+}
+
+// readUserVersion returns the user_version of the database.
+func readUserVersion(ctx context.Context, q queryer) (int64, error) {
+	rows, err := q.QueryContext(ctx, "PRAGMA user_version;")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	version := int64(0)
+	if rows.Next() {
+		if err := rows.Scan(&version); err != nil {
+			return 0, err
+		}
+	}
+	return version, rows.Err()
+}
+
+// writeUserVersion sets the user_version of the database. PRAGMA statements can't take
+// parameters, so the version is formatted into the statement.
+func writeUserVersion(ctx context.Context, tx *sql.Tx, version int64) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d;", version)); err != nil {
+		return errorf(CodeMigrationTable, "failed to set user_version to %v: %w", version, err)
+	}
+	return nil
+}
+
+// userVersionIndex returns the versions the user_version implies as applied: the defined versions
+// below it, and the user_version itself.
+func (db *Database) userVersionIndex(ctx context.Context, q queryer) ([]int64, error) {
+	current, err := readUserVersion(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make([]int64, 0)
+	for _, migration := range *db.migrations {
+		if migration.Version < current {
+			index = append(index, migration.Version)
+		}
+	}
+	if current > 0 {
+		index = append(index, current)
+	}
+	sort.Slice(index, func(i, j int) bool { return index[i] < index[j] })
+	return index, nil
+}
+
+// userVersionRecords returns the implied applied versions with their descriptions in code. Versions
+// that aren't defined have no description.
+func (db *Database) userVersionRecords(ctx context.Context, q queryer) (map[int64]string, error) {
+	index, err := db.userVersionIndex(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := db.migrations.byVersion()
+	records := make(map[int64]string, len(index))
+	for _, version := range index {
+		records[version] = defined[version].Description
+	}
+	return records, nil
+}
+
+// insertUserVersion records the version as applied by raising the user_version to it.
+func (db *Database) insertUserVersion(ctx context.Context, tx *sql.Tx, version int64) error {
+	current, err := readUserVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if version <= current {
+		return nil
+	}
+	return writeUserVersion(ctx, tx, version)
+}
+
+// deleteUserVersion records the version as rolled back by lowering the user_version to the
+// previous defined version. Only the latest version can be rolled back.
+func (db *Database) deleteUserVersion(ctx context.Context, tx *sql.Tx, version int64) error {
+	current, err := readUserVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if version != current {
+		return errorf(CodeMigrationTable, "can't remove migration (version=%v): the user_version only records the latest version %v", version, current)
+	}
+
+	previous := int64(0)
+	for _, migration := range *db.migrations {
+		if migration.Version < version && migration.Version > previous {
+			previous = migration.Version
+		}
+	}
+	return writeUserVersion(ctx, tx, previous)
+}
@@ -0,0 +1,132 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestUserVersion(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		tableMigration(3, "three"),
+	}).SetUserVersion(true)
+
+	result, err := db.MigrateUp(ctx, litemigrate.WithMaxVersion(2))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Applied) != 2 {
+		t.Errorf("expected 2 migrations to be applied, got %v", result.Applied)
+	}
+
+	userVersion := 0
+	if err := conn.QueryRow("PRAGMA user_version;").Scan(&userVersion); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if userVersion != 2 {
+		t.Errorf("expected user_version 2, got %d", userVersion)
+	}
+
+	var tables int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = ?;", litemigrate.DefaultMigrationTable).Scan(&tables); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("expected no migration table")
+	}
+
+	result, err = db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Applied) != 1 || result.Applied[0] != 3 {
+		t.Errorf("expected version 3 to be applied, got %v", result.Applied)
+	}
+
+	if _, err := db.MigrateDown(ctx, 2); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	version, err := db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+
+	history, err := db.History(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(history) != 1 || history[0].Version != 1 || history[0].Description != "Create one table" {
+		t.Errorf("expected version 1 in the history, got %+v", history)
+	}
+}
+
+func TestUserVersionTooLarge(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(20240612153000, "one"),
+	}).SetUserVersion(true)
+
+	_, err = db.MigrateUp(context.Background())
+	var lmErr *litemigrate.Error
+	if !errors.As(err, &lmErr) || lmErr.Code != litemigrate.CodeMigrationTable {
+		t.Fatalf("expected a migration table error, got %v", err)
+	}
+}
+
+func TestUserVersionSkipped(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	backfill := tableMigration(2, "two")
+	backfill.Tags = []string{"data"}
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		backfill,
+		tableMigration(3, "three"),
+	}).SetUserVersion(true)
+
+	result, err := db.MigrateUp(ctx, litemigrate.WithoutTags("data"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Applied) != 1 || result.Applied[0] != 1 {
+		t.Errorf("expected only version 1 to be applied before the skipped version, got %v", result.Applied)
+	}
+
+	result, err = db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Applied) != 2 || result.Applied[0] != 2 || result.Applied[1] != 3 {
+		t.Errorf("expected versions 2 and 3 to be applied, got %v", result.Applied)
+	}
+}
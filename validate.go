@@ -0,0 +1,226 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Mismatch describes a migration whose recorded description differs from the one in code.
+type Mismatch struct {
+	Version  int64
+	Code     string
+	Database string
+}
+
+// ValidationReport describes how the migrations in code compare to the migration table.
+type ValidationReport struct {
+	// Pending are versions in code that are newer than the current version and not yet applied.
+	Pending []int64
+	// Missing are versions in code that are older than the current version but were never applied.
+	Missing []int64
+	// Excluded are versions in code that a run skipped because they were excluded, see
+	// Database.SetExcludedVersions. They are neither pending nor missing.
+	Excluded []int64
+	// Extra are versions recorded in the migration table that no longer exist in code.
+	Extra []int64
+	// Mismatched are versions whose description in code differs from the recorded one.
+	Mismatched []Mismatch
+	// Modified are applied versions whose checksum in code differs from the one recorded when they
+	// were applied. See Migration.Checksum.
+	Modified []int64
+}
+
+// Valid reports whether the code and the database are consistent. Pending migrations are not
+// considered a problem.
+func (r *ValidationReport) Valid() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Mismatched) == 0 && len(r.Modified) == 0
+}
+
+// String returns a human readable summary of the report.
+func (r *ValidationReport) String() string {
+	if r.Valid() && len(r.Pending) == 0 {
+		return "database is consistent with migrations"
+	}
+
+	lines := r.problems()
+	for _, version := range r.Pending {
+		lines = append(lines, fmt.Sprintf("pending migration: (version=%v)", version))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// problems describes the inconsistencies of the report, one per line.
+func (r *ValidationReport) problems() []string {
+	lines := make([]string, 0)
+	for _, version := range r.Missing {
+		lines = append(lines, fmt.Sprintf("missing migration: (version=%v) was never applied", version))
+	}
+	for _, version := range r.Extra {
+		lines = append(lines, fmt.Sprintf("extra migration: (version=%v) is applied but not defined", version))
+	}
+	for _, m := range r.Mismatched {
+		lines = append(lines, fmt.Sprintf("mismatched migration: (version=%v, code=%s, database=%s)", m.Version, m.Code, m.Database))
+	}
+	for _, version := range r.Modified {
+		lines = append(lines, fmt.Sprintf("modified migration: (version=%v) changed since it was applied", version))
+	}
+	return lines
+}
+
+// Validate compares the migrations in code against the migration table and returns a report
+// of the differences. It does not modify the database.
+func (db *Database) Validate(ctx context.Context) (*ValidationReport, error) {
+	if err := db.migrations.validate(); err != nil {
+		return nil, err
+	}
+
+	q, release, err := db.stateConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := db.getMigrationRecords(ctx, q)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	excluded, err := db.excludedVersionsRecorded(ctx, q)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	checksums, err := db.getMigrationChecksums(ctx, q)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	current := int64(0)
+	for version := range records {
+		if version > current {
+			current = version
+		}
+	}
+
+	report := &ValidationReport{}
+	defined := map[int64]bool{}
+	for _, migration := range db.migrations.sorted() {
+		defined[migration.Version] = true
+
+		description, applied := records[migration.Version]
+		switch {
+		case !applied && excluded[migration.Version]:
+			report.Excluded = append(report.Excluded, migration.Version)
+		case !applied && migration.Version > current:
+			report.Pending = append(report.Pending, migration.Version)
+		case !applied:
+			report.Missing = append(report.Missing, migration.Version)
+		case description != migration.Description:
+			report.Mismatched = append(report.Mismatched, Mismatch{
+				Version:  migration.Version,
+				Code:     migration.Description,
+				Database: description,
+			})
+		case migration.modified(checksums[migration.Version]):
+			report.Modified = append(report.Modified, migration.Version)
+		}
+	}
+
+	for version := range records {
+		if !defined[version] {
+			report.Extra = append(report.Extra, version)
+		}
+	}
+	sort.Slice(report.Extra, func(i, j int) bool { return report.Extra[i] < report.Extra[j] })
+	return report, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (db *Database) migrationTableExists(ctx context.Context, q queryer) (bool, error) {
+	if err := db.checkMigrationTable(); err != nil {
+		return false, err
+	}
+
+	return db.stateTableExists(ctx, q, db.migrationTable)
+}
+
+// appliedCondition returns the WHERE clause selecting the records of applied migrations from the
+// migration table. Tables without ColumnStatus only hold applied migrations.
+func (db *Database) appliedCondition(ctx context.Context, q queryer) (string, error) {
+	columns, err := db.stateColumns(ctx, q, db.migrationTable)
+	if err != nil || !contains(columns, ColumnStatus) {
+		return "", err
+	}
+	return fmt.Sprintf(" WHERE COALESCE(%s, '%s') = '%s'", ColumnStatus, StatusApplied, StatusApplied), nil
+}
+
+func (db *Database) getMigrationRecords(ctx context.Context, q queryer) (map[int64]string, error) {
+	if db.userVersion {
+		return db.userVersionRecords(ctx, q)
+	}
+
+	exists, err := db.migrationTableExists(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	records := map[int64]string{}
+	if !exists {
+		return records, nil
+	}
+
+	applied, err := db.appliedCondition(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT version, description FROM %s%s;", db.qualify(db.migrationTable), applied)
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			version     int64
+			description string
+		)
+		if err := rows.Scan(&version, &description); err != nil {
+			return nil, err
+		}
+		records[version] = description
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	return records, nil
+}
+
+// getMigrationChecksums returns the checksums recorded with the applied migrations, by version.
+// Migrations applied without a checksum are left out.
+func (db *Database) getMigrationChecksums(ctx context.Context, q queryer) (map[int64]string, error) {
+	history, err := db.readHistory(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	checksums := map[int64]string{}
+	for _, record := range history {
+		if record.Status == StatusApplied && record.Checksum != "" {
+			checksums[record.Version] = record.Checksum
+		}
+	}
+	return checksums, nil
+}
+
+// modified reports whether the migration's checksum differs from the recorded one. Migrations
+// without a checksum, in code or recorded, are never modified.
+func (m Migration) modified(recorded string) bool {
+	return m.Checksum != "" && recorded != "" && m.Checksum != recorded
+}
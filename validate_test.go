@@ -0,0 +1,126 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestValidate(t *testing.T) {
+	applied := &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(3, "three"),
+		tableMigration(4, "four"),
+	}
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = litemigrate.NewWithConn(conn, applied).MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	renamed := tableMigration(3, "three")
+	renamed.Description = "Create renamed table"
+
+	code := &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		renamed,
+		tableMigration(5, "five"),
+	}
+
+	report, err := litemigrate.NewWithConn(conn, code).Validate(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if report.Valid() {
+		t.Error("expected invalid report, got valid")
+	}
+
+	if len(report.Missing) != 1 || report.Missing[0] != 2 {
+		t.Errorf("expected missing [2], got %v", report.Missing)
+	}
+
+	if len(report.Extra) != 1 || report.Extra[0] != 4 {
+		t.Errorf("expected extra [4], got %v", report.Extra)
+	}
+
+	if len(report.Mismatched) != 1 || report.Mismatched[0].Version != 3 {
+		t.Errorf("expected mismatched version 3, got %v", report.Mismatched)
+	}
+
+	if len(report.Pending) != 1 || report.Pending[0] != 5 {
+		t.Errorf("expected pending [5], got %v", report.Pending)
+	}
+}
+
+func TestValidateChecksum(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	applied := tableMigration(1, "one")
+	applied.Checksum = "a"
+	if _, err := litemigrate.NewWithConn(conn, &litemigrate.Migrations{applied}).MigrateUp(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	edited := applied
+	edited.Checksum = "b"
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{edited})
+
+	report, err := db.Validate(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Valid() || len(report.Modified) != 1 || report.Modified[0] != 1 {
+		t.Errorf("expected modified [1], got %+v", report)
+	}
+
+	if err := db.Force(ctx, 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	report, err = db.Validate(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !report.Valid() {
+		t.Errorf("expected force to record the new checksum, got %+v", report)
+	}
+}
+
+func TestValidateWithoutMigrationTable(t *testing.T) {
+	db, err := litemigrate.New(testDBPath, &litemigrate.Migrations{tableMigration(1, "one")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	report, err := db.Validate(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !report.Valid() {
+		t.Errorf("expected valid report, got %v", report)
+	}
+
+	if len(report.Pending) != 1 {
+		t.Errorf("expected 1 pending migration, got %v", report.Pending)
+	}
+}
@@ -0,0 +1,18 @@
+package litemigrate
+
+import (
+	"context"
+	"database/sql"
+)
+
+// verify returns an error if an applied migration's Verify hook fails.
+func verify(ctx context.Context, tx *sql.Tx, migration Migration) error {
+	if migration.Verify == nil {
+		return nil
+	}
+
+	if err := migration.Verify(ctx, tx); err != nil {
+		return errorf(CodeVerifyFailed, "verification of migration (version=%v, description=%s) failed: %w", migration.Version, migration.Description, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestVerify(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	verified := false
+	one := tableMigration(1, "one")
+	one.Verify = func(ctx context.Context, tx *sql.Tx) error {
+		verified = true
+		return litemigrate.TableExists("one")(ctx, tx)
+	}
+
+	two := tableMigration(2, "two")
+	two.Verify = func(ctx context.Context, tx *sql.Tx) error {
+		return errors.New("invariant violated")
+	}
+
+	ctx := context.Background()
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{one, two})
+	_, err = db.MigrateUp(ctx)
+	if code := litemigrate.Code(err); code != litemigrate.CodeVerifyFailed {
+		t.Fatalf("expected error code %s, got %v", litemigrate.CodeVerifyFailed, err)
+	}
+
+	if !verified {
+		t.Fatal("expected the first migration to be verified")
+	}
+
+	version, err := db.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if version != 0 {
+		t.Fatalf("expected the run to be rolled back, got version %d", version)
+	}
+}
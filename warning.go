@@ -0,0 +1,65 @@
+package litemigrate
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// WarningCode identifies the kind of a warning.
+type WarningCode string
+
+const (
+	// WarningOutOfOrder is reported when a migration is applied although a migration with a higher
+	// version is already applied.
+	WarningOutOfOrder WarningCode = "out_of_order"
+	// WarningSlowMigration is reported when a migration takes longer than the threshold set with
+	// SetSlowMigrationThreshold.
+	WarningSlowMigration WarningCode = "slow_migration"
+	// WarningUnknownMigration is reported when the migration table records a version that isn't
+	// defined in code. See Repair.
+	WarningUnknownMigration WarningCode = "unknown_migration"
+	// WarningMaintenanceFailed is reported when a maintenance step after a run fails. See
+	// SetMaintenance.
+	WarningMaintenanceFailed WarningCode = "maintenance_failed"
+)
+
+// Warning is a non-fatal finding of a migration run.
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Version int64       `json:"version"`
+	Message string      `json:"message"`
+}
+
+// String returns the warning as a human-readable line.
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s (version=%v)", w.Code, w.Message, w.Version)
+}
+
+// SetWarningHandler sets the function called with the warnings of migration runs. By default they
+// are logged. Warnings are also listed in Result.Warnings and reported as WarningRaised events.
+func (db *Database) SetWarningHandler(handler func(Warning)) *Database {
+	db.warningHandler = handler
+	return db
+}
+
+// SetSlowMigrationThreshold reports a WarningSlowMigration for every migration that takes longer
+// than the threshold. A zero threshold disables the warning.
+func (db *Database) SetSlowMigrationThreshold(threshold time.Duration) *Database {
+	db.slowThreshold = threshold
+	return db
+}
+
+// warn reports a warning of the run to the handler and the progress handler, and adds it to its
+// result.
+func (db *Database) warn(result *Result, code WarningCode, version int64, format string, args ...any) {
+	w := Warning{Code: code, Version: version, Message: fmt.Sprintf(format, args...)}
+	result.Warnings = append(result.Warnings, w)
+	db.progress(Event{Type: WarningRaised, Direction: result.Direction, Version: version, Warning: &w})
+
+	if db.warningHandler == nil {
+		log.Printf("warning: %s", w)
+		return
+	}
+	db.warningHandler(w)
+}
@@ -0,0 +1,76 @@
+package litemigrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/joeychilson/litemigrate"
+)
+
+func TestWarnings(t *testing.T) {
+	conn, err := sql.Open("sqlite3", testDBPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	_, err = litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(3, "three"),
+		tableMigration(4, "four"),
+	}).MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	warnings := make([]litemigrate.Warning, 0)
+	db := litemigrate.NewWithConn(conn, &litemigrate.Migrations{
+		tableMigration(1, "one"),
+		tableMigration(2, "two"),
+		tableMigration(3, "three"),
+	})
+	events := make([]litemigrate.Warning, 0)
+	db.SetSlowMigrationThreshold(time.Nanosecond).SetWarningHandler(func(w litemigrate.Warning) {
+		warnings = append(warnings, w)
+	}).SetProgressHandler(func(e litemigrate.Event) {
+		if e.Type == litemigrate.WarningRaised {
+			events = append(events, *e.Warning)
+		}
+	})
+
+	result, err := db.MigrateUp(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []struct {
+		code    litemigrate.WarningCode
+		version int64
+	}{
+		{litemigrate.WarningUnknownMigration, 4},
+		{litemigrate.WarningOutOfOrder, 2},
+		{litemigrate.WarningSlowMigration, 2},
+	}
+
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+
+	for i, w := range expected {
+		if warnings[i].Code != w.code || warnings[i].Version != w.version {
+			t.Errorf("expected warning %s for version %d, got %v", w.code, w.version, warnings[i])
+		}
+	}
+
+	if len(result.Warnings) != len(warnings) || len(events) != len(warnings) {
+		t.Fatalf("expected the warnings in the result and the events, got %v and %v", result.Warnings, events)
+	}
+	for i, w := range warnings {
+		if result.Warnings[i] != w || events[i] != w {
+			t.Errorf("expected warning %v, got %v and %v", w, result.Warnings[i], events[i])
+		}
+	}
+}